@@ -0,0 +1,204 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package binrpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/rlp"
+	"golang.org/x/net/http2"
+)
+
+const (
+	// maxResponseSize is the maximum size of the response of a call.
+	maxResponseSize = 128 * 1024 * 1024
+
+	// pingInterval is the idle time after which the connection is health checked,
+	// detecting dead connections of long-lived subscriptions.
+	pingInterval = 30 * time.Second
+)
+
+// Client is a connection to a binary RPC server. Concurrent calls and
+// subscriptions are multiplexed over a single HTTP/2 connection.
+type Client struct {
+	url    string
+	client *http.Client
+}
+
+// Dial connects a client to the given URL. Plain http URLs use cleartext HTTP/2,
+// https URLs use HTTP/2 over TLS.
+func Dial(rawurl string) (*Client, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	transport := &http2.Transport{
+		ReadIdleTimeout: pingInterval,
+		PingTimeout:     pingInterval / 2,
+	}
+	switch u.Scheme {
+	case "http":
+		transport.AllowHTTP = true
+		transport.DialTLSContext = func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, addr)
+		}
+	case "https":
+	default:
+		return nil, fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+	}
+	return NewClient(rawurl, &http.Client{Transport: transport}), nil
+}
+
+// NewClient creates a client using the given HTTP client, which must support
+// HTTP/2 for subscriptions to work.
+func NewClient(rawurl string, client *http.Client) *Client {
+	return &Client{url: strings.TrimSuffix(rawurl, "/"), client: client}
+}
+
+// Close releases the connections of the client.
+func (c *Client) Close() {
+	c.client.CloseIdleConnections()
+}
+
+// post sends a request to the given path of the server.
+func (c *Client) post(ctx context.Context, path string, method string, params interface{}) (*http.Response, error) {
+	req, err := encodeRequest(method, params)
+	if err != nil {
+		return nil, err
+	}
+	hreq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+path, bytes.NewReader(req))
+	if err != nil {
+		return nil, err
+	}
+	hreq.Header.Set("Content-Type", ContentType)
+	hreq.Header.Set("Accept", ContentType)
+
+	resp, err := c.client.Do(hreq)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return resp, nil
+}
+
+// Call invokes the given method and decodes its result into result. If params
+// is nil, the method is called without parameters. ethereum.NotFound is
+// returned if the requested object doesn't exist.
+func (c *Client) Call(ctx context.Context, result interface{}, method string, params interface{}) error {
+	resp, err := c.post(ctx, CallPath, method, params)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var res Response
+	if err := rlp.NewStream(resp.Body, maxResponseSize).Decode(&res); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	return decodeResult(res, result)
+}
+
+// Subscribe creates a subscription to the given event stream. The events are
+// retrieved with Subscription.Next.
+func (c *Client) Subscribe(ctx context.Context, name string, params interface{}) (*Subscription, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	resp, err := c.post(ctx, SubscribePath, name, params)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	sub := &Subscription{
+		body:   resp.Body,
+		stream: rlp.NewStream(resp.Body, 0),
+		cancel: cancel,
+	}
+	var ack Response
+	if err := sub.stream.Decode(&ack); err != nil {
+		sub.Close()
+		return nil, fmt.Errorf("invalid subscription response: %w", err)
+	}
+	if ack.Error != "" {
+		sub.Close()
+		return nil, errors.New(ack.Error)
+	}
+	return sub, nil
+}
+
+// Subscription is a stream of events sent by the server.
+type Subscription struct {
+	body   io.ReadCloser
+	stream *rlp.Stream
+	cancel context.CancelFunc
+	once   sync.Once
+}
+
+// Next blocks until the next event is received and decodes it into v. An error
+// is returned if the subscription is closed by either side.
+func (s *Subscription) Next(v interface{}) error {
+	return s.stream.Decode(v)
+}
+
+// Close terminates the subscription.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.cancel()
+		s.body.Close()
+	})
+}
+
+// encodeRequest encodes a call of the given method.
+func encodeRequest(method string, params interface{}) ([]byte, error) {
+	if params == nil {
+		params = []interface{}{}
+	}
+	enc, err := rlp.EncodeToBytes(params)
+	if err != nil {
+		return nil, err
+	}
+	return rlp.EncodeToBytes(&Request{Method: method, Params: enc})
+}
+
+// decodeResult decodes the result of a response into v.
+func decodeResult(res Response, v interface{}) error {
+	if res.Error != "" {
+		return errors.New(res.Error)
+	}
+	if len(res.Result) == 0 {
+		return ethereum.NotFound
+	}
+	if v == nil {
+		return nil
+	}
+	return rlp.DecodeBytes(res.Result, v)
+}
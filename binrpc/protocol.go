@@ -0,0 +1,255 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package binrpc implements a binary RPC transport for the core chain queries,
+// avoiding the cost of the JSON encoding of large results such as full blocks.
+//
+// Calls and their results are RLP encoded and exchanged over HTTP/2, which
+// allows for multiplexing concurrent calls over a single connection. A call is
+// a POST request to CallPath whose body is an RLP encoded Request, answered
+// with an RLP encoded Response. Subscriptions are POST requests to
+// SubscribePath, answered with a stream of RLP items: a Response acknowledging
+// the subscription, followed by the RLP encoded notifications. Since RLP items
+// are self-delimiting, no additional framing is needed.
+package binrpc
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	CallPath      = "/binrpc/call"      // Path of the unary calls
+	SubscribePath = "/binrpc/subscribe" // Path of the streaming subscriptions
+	ContentType   = "application/x-rlp" // Content type of the requests and responses
+
+	// MaxRequestSize is the maximum size of an encoded request.
+	MaxRequestSize = 1024 * 1024
+)
+
+// Methods of the binary RPC transport.
+const (
+	MethodChainID     = "chainId"     // () -> big.Int
+	MethodBlockNumber = "blockNumber" // () -> uint64
+	MethodHeader      = "header"      // (BlockID) -> types.Header
+	MethodBlock       = "block"       // (BlockID) -> types.Block
+	MethodReceipts    = "receipts"    // (BlockID) -> []Receipt
+	MethodReceipt     = "receipt"     // (common.Hash) -> Receipt
+	MethodTransaction = "transaction" // (common.Hash) -> Transaction
+	MethodLogs        = "logs"        // (FilterQuery) -> []Log
+	MethodBalance     = "balance"     // (AccountQuery) -> big.Int
+	MethodNonce       = "nonce"       // (AccountQuery) -> uint64
+	MethodCode        = "code"        // (AccountQuery) -> []byte
+	MethodStorage     = "storage"     // (StorageQuery) -> common.Hash
+)
+
+// Subscriptions of the binary RPC transport.
+const (
+	SubscriptionNewHeads = "newHeads" // () -> stream of types.Header
+	SubscriptionLogs     = "logs"     // (FilterQuery) -> stream of Log
+)
+
+// Request is a method call or subscription request. Params is the RLP encoding
+// of the parameters, as documented for every method.
+type Request struct {
+	Method string
+	Params rlp.RawValue
+}
+
+// Response is the answer to a request. Result is the RLP encoding of the return
+// value, it's omitted if the requested object doesn't exist.
+type Response struct {
+	Error  string
+	Result rlp.RawValue `rlp:"optional"`
+}
+
+// BlockID identifies a block by its hash, or by its number if the hash is
+// zero. Block tags (e.g. latest) are encoded as the two's complement of their
+// negative rpc.BlockNumber.
+type BlockID struct {
+	Hash   common.Hash
+	Number uint64
+}
+
+// BlockByNumber returns the identifier of a block by its number or tag.
+func BlockByNumber(number rpc.BlockNumber) BlockID {
+	return BlockID{Number: uint64(number)}
+}
+
+// BlockByHash returns the identifier of a block by its hash.
+func BlockByHash(hash common.Hash) BlockID {
+	return BlockID{Hash: hash}
+}
+
+// BlockNumberOrHash converts the identifier into its JSON-RPC equivalent.
+func (id BlockID) BlockNumberOrHash() rpc.BlockNumberOrHash {
+	if id.Hash != (common.Hash{}) {
+		return rpc.BlockNumberOrHashWithHash(id.Hash, false)
+	}
+	return rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(int64(id.Number)))
+}
+
+// AccountQuery are the parameters of the account state queries.
+type AccountQuery struct {
+	Address common.Address
+	Block   BlockID
+}
+
+// StorageQuery are the parameters of the storage slot queries.
+type StorageQuery struct {
+	Address common.Address
+	Key     common.Hash
+	Block   BlockID
+}
+
+// FilterQuery are the parameters of the log queries and subscriptions. If the
+// block hash is set, the logs of that block are returned, otherwise the logs
+// of the block range. Block numbers are encoded like in BlockID.
+type FilterQuery struct {
+	BlockHash common.Hash
+	FromBlock uint64
+	ToBlock   uint64
+	Addresses []common.Address
+	Topics    [][]common.Hash
+}
+
+// Log is a log event along with its position in the chain.
+type Log struct {
+	Address     common.Address
+	Topics      []common.Hash
+	Data        []byte
+	BlockNumber uint64
+	TxHash      common.Hash
+	TxIndex     uint64
+	BlockHash   common.Hash
+	Index       uint64
+	Removed     bool
+}
+
+// NewLog converts a log into its wire representation.
+func NewLog(log *types.Log) *Log {
+	return &Log{
+		Address:     log.Address,
+		Topics:      log.Topics,
+		Data:        log.Data,
+		BlockNumber: log.BlockNumber,
+		TxHash:      log.TxHash,
+		TxIndex:     uint64(log.TxIndex),
+		BlockHash:   log.BlockHash,
+		Index:       uint64(log.Index),
+		Removed:     log.Removed,
+	}
+}
+
+// Log converts the wire representation back into a log.
+func (l *Log) Log() *types.Log {
+	return &types.Log{
+		Address:     l.Address,
+		Topics:      l.Topics,
+		Data:        l.Data,
+		BlockNumber: l.BlockNumber,
+		TxHash:      l.TxHash,
+		TxIndex:     uint(l.TxIndex),
+		BlockHash:   l.BlockHash,
+		Index:       uint(l.Index),
+		Removed:     l.Removed,
+	}
+}
+
+// Receipt is a transaction receipt including all its derived fields.
+type Receipt struct {
+	Type              uint8
+	PostState         []byte
+	Status            uint64
+	CumulativeGasUsed uint64
+	Bloom             types.Bloom
+	Logs              []*Log
+	TxHash            common.Hash
+	ContractAddress   common.Address
+	GasUsed           uint64
+	EffectiveGasPrice *big.Int `rlp:"nil"`
+	BlobGasUsed       uint64
+	BlobGasPrice      *big.Int `rlp:"nil"`
+	BlockHash         common.Hash
+	BlockNumber       uint64
+	TransactionIndex  uint64
+}
+
+// NewReceipt converts a receipt into its wire representation.
+func NewReceipt(receipt *types.Receipt) *Receipt {
+	enc := &Receipt{
+		Type:              receipt.Type,
+		PostState:         receipt.PostState,
+		Status:            receipt.Status,
+		CumulativeGasUsed: receipt.CumulativeGasUsed,
+		Bloom:             receipt.Bloom,
+		Logs:              make([]*Log, len(receipt.Logs)),
+		TxHash:            receipt.TxHash,
+		ContractAddress:   receipt.ContractAddress,
+		GasUsed:           receipt.GasUsed,
+		EffectiveGasPrice: receipt.EffectiveGasPrice,
+		BlobGasUsed:       receipt.BlobGasUsed,
+		BlobGasPrice:      receipt.BlobGasPrice,
+		BlockHash:         receipt.BlockHash,
+		TransactionIndex:  uint64(receipt.TransactionIndex),
+	}
+	if receipt.BlockNumber != nil {
+		enc.BlockNumber = receipt.BlockNumber.Uint64()
+	}
+	for i, log := range receipt.Logs {
+		enc.Logs[i] = NewLog(log)
+	}
+	return enc
+}
+
+// Receipt converts the wire representation back into a receipt.
+func (r *Receipt) Receipt() *types.Receipt {
+	receipt := &types.Receipt{
+		Type:              r.Type,
+		PostState:         r.PostState,
+		Status:            r.Status,
+		CumulativeGasUsed: r.CumulativeGasUsed,
+		Bloom:             r.Bloom,
+		Logs:              make([]*types.Log, len(r.Logs)),
+		TxHash:            r.TxHash,
+		ContractAddress:   r.ContractAddress,
+		GasUsed:           r.GasUsed,
+		EffectiveGasPrice: r.EffectiveGasPrice,
+		BlobGasUsed:       r.BlobGasUsed,
+		BlobGasPrice:      r.BlobGasPrice,
+		BlockHash:         r.BlockHash,
+		BlockNumber:       new(big.Int).SetUint64(r.BlockNumber),
+		TransactionIndex:  uint(r.TransactionIndex),
+	}
+	for i, log := range r.Logs {
+		receipt.Logs[i] = log.Log()
+	}
+	return receipt
+}
+
+// Transaction is a transaction along with its position in the chain. Pending
+// transactions have no position.
+type Transaction struct {
+	Tx          *types.Transaction
+	Pending     bool
+	BlockHash   common.Hash
+	BlockNumber uint64
+	Index       uint64
+}
@@ -0,0 +1,426 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package service implements the server side of the binary RPC transport.
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/binrpc"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// method is the implementation of a binary RPC method. A nil result is sent to
+// the client as not found.
+type method func(ctx context.Context, params rlp.RawValue) (interface{}, error)
+
+// Service serves the binary RPC transport.
+type Service struct {
+	backend ethapi.Backend
+	filters *filters.FilterAPI
+	events  *filters.EventSystem
+	methods map[string]method
+}
+
+// New creates the binary RPC service and registers its handlers on the binary
+// RPC server of the node. Log queries are limited to maxBlockSpan blocks, like
+// on the JSON-RPC endpoints.
+func New(stack *node.Node, backend ethapi.Backend, filterSystem *filters.FilterSystem, maxBlockSpan uint64) *Service {
+	s := newService(backend, filterSystem, maxBlockSpan)
+	stack.RegisterBinaryHandler("Binary RPC", binrpc.CallPath, http.HandlerFunc(s.serveCall))
+	stack.RegisterBinaryHandler("Binary RPC", binrpc.SubscribePath, http.HandlerFunc(s.serveSubscribe))
+	return s
+}
+
+func newService(backend ethapi.Backend, filterSystem *filters.FilterSystem, maxBlockSpan uint64) *Service {
+	s := &Service{
+		backend: backend,
+		filters: filters.NewFilterAPI(filterSystem, false, maxBlockSpan),
+		events:  filters.NewEventSystem(filterSystem, false),
+	}
+	s.methods = map[string]method{
+		binrpc.MethodChainID:     s.chainID,
+		binrpc.MethodBlockNumber: s.blockNumber,
+		binrpc.MethodHeader:      s.header,
+		binrpc.MethodBlock:       s.block,
+		binrpc.MethodReceipts:    s.receipts,
+		binrpc.MethodReceipt:     s.receipt,
+		binrpc.MethodTransaction: s.transaction,
+		binrpc.MethodLogs:        s.logs,
+		binrpc.MethodBalance:     s.balance,
+		binrpc.MethodNonce:       s.nonce,
+		binrpc.MethodCode:        s.code,
+		binrpc.MethodStorage:     s.storage,
+	}
+	return s
+}
+
+// readRequest decodes the request sent by the client.
+func readRequest(w http.ResponseWriter, r *http.Request) (*binrpc.Request, bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil, false
+	}
+	if ct := r.Header.Get("Content-Type"); !strings.HasPrefix(ct, binrpc.ContentType) {
+		http.Error(w, "unsupported content type", http.StatusUnsupportedMediaType)
+		return nil, false
+	}
+	var req binrpc.Request
+	if err := rlp.NewStream(http.MaxBytesReader(w, r.Body, binrpc.MaxRequestSize), binrpc.MaxRequestSize).Decode(&req); err != nil {
+		http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	return &req, true
+}
+
+// writeResponse encodes a response into the stream.
+func writeResponse(w io.Writer, result interface{}, err error) error {
+	var res binrpc.Response
+	if err != nil {
+		res.Error = err.Error()
+	} else if result != nil {
+		enc, err := rlp.EncodeToBytes(result)
+		if err != nil {
+			res.Error = err.Error()
+		} else {
+			res.Result = enc
+		}
+	}
+	return rlp.Encode(w, &res)
+}
+
+// serveCall serves a single method call.
+func (s *Service) serveCall(w http.ResponseWriter, r *http.Request) {
+	req, ok := readRequest(w, r)
+	if !ok {
+		return
+	}
+	var (
+		result interface{}
+		err    error
+	)
+	if fn := s.methods[req.Method]; fn != nil {
+		result, err = fn(r.Context(), req.Params)
+	} else {
+		err = fmt.Errorf("the method %s does not exist/is not available", req.Method)
+	}
+	w.Header().Set("Content-Type", binrpc.ContentType)
+	if err := writeResponse(w, result, err); err != nil {
+		log.Debug("Failed to write binary RPC response", "method", req.Method, "err", err)
+	}
+}
+
+// serveSubscribe serves a subscription, streaming the events until either the
+// client or the server terminates the request.
+func (s *Service) serveSubscribe(w http.ResponseWriter, r *http.Request) {
+	req, ok := readRequest(w, r)
+	if !ok {
+		return
+	}
+	var (
+		ctrl = http.NewResponseController(w)
+		send = func(v interface{}) error {
+			if err := rlp.Encode(w, v); err != nil {
+				return err
+			}
+			return ctrl.Flush()
+		}
+	)
+	// Subscriptions outlive the write timeout of the server
+	ctrl.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", binrpc.ContentType)
+
+	var err error
+	switch req.Method {
+	case binrpc.SubscriptionNewHeads:
+		err = s.streamHeads(r.Context(), send)
+	case binrpc.SubscriptionLogs:
+		err = s.streamLogs(r.Context(), req.Params, send)
+	default:
+		err = fmt.Errorf("no %q subscription", req.Method)
+	}
+	if err != nil && !errors.Is(err, context.Canceled) {
+		// Subscription errors are reported in the acknowledgement, failures
+		// after it terminate the stream.
+		if !errSubscribed(err) {
+			writeResponse(w, nil, err)
+		}
+		log.Debug("Binary RPC subscription terminated", "subscription", req.Method, "err", err)
+	}
+}
+
+// subscribedError wraps the errors which occur after a subscription was
+// acknowledged.
+type subscribedError struct{ err error }
+
+func (e *subscribedError) Error() string { return e.err.Error() }
+func (e *subscribedError) Unwrap() error { return e.err }
+
+func errSubscribed(err error) bool {
+	var e *subscribedError
+	return errors.As(err, &e)
+}
+
+// streamHeads sends the new chain heads to the client.
+func (s *Service) streamHeads(ctx context.Context, send func(interface{}) error) error {
+	var (
+		headers = make(chan *types.Header, 16)
+		sub     = s.events.SubscribeNewHeads(headers)
+	)
+	defer sub.Unsubscribe()
+
+	if err := send(&binrpc.Response{}); err != nil {
+		return &subscribedError{err}
+	}
+	for {
+		select {
+		case header := <-headers:
+			if err := send(header); err != nil {
+				return &subscribedError{err}
+			}
+		case <-sub.Err():
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// streamLogs sends the logs matching the filter to the client.
+func (s *Service) streamLogs(ctx context.Context, params rlp.RawValue, send func(interface{}) error) error {
+	var query binrpc.FilterQuery
+	if err := rlp.DecodeBytes(params, &query); err != nil {
+		return fmt.Errorf("invalid filter: %w", err)
+	}
+	var (
+		logs     = make(chan []*types.Log, 16)
+		sub, err = s.events.SubscribeLogs(filterQuery(query), logs)
+	)
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+
+	if err := send(&binrpc.Response{}); err != nil {
+		return &subscribedError{err}
+	}
+	for {
+		select {
+		case batch := <-logs:
+			for _, log := range batch {
+				if err := send(binrpc.NewLog(log)); err != nil {
+					return &subscribedError{err}
+				}
+			}
+		case <-sub.Err():
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// filterQuery converts a binary RPC filter into a log filter.
+func filterQuery(query binrpc.FilterQuery) ethereum.FilterQuery {
+	crit := ethereum.FilterQuery{
+		Addresses: query.Addresses,
+		Topics:    query.Topics,
+	}
+	if query.BlockHash != (common.Hash{}) {
+		crit.BlockHash = &query.BlockHash
+	} else {
+		crit.FromBlock = big.NewInt(int64(query.FromBlock))
+		crit.ToBlock = big.NewInt(int64(query.ToBlock))
+	}
+	return crit
+}
+
+// decodeParams decodes the parameters of a call.
+func decodeParams(params rlp.RawValue, v interface{}) error {
+	if err := rlp.DecodeBytes(params, v); err != nil {
+		return fmt.Errorf("invalid params: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) chainID(ctx context.Context, params rlp.RawValue) (interface{}, error) {
+	return s.backend.ChainConfig().ChainID, nil
+}
+
+func (s *Service) blockNumber(ctx context.Context, params rlp.RawValue) (interface{}, error) {
+	return s.backend.CurrentHeader().Number.Uint64(), nil
+}
+
+func (s *Service) header(ctx context.Context, params rlp.RawValue) (interface{}, error) {
+	var id binrpc.BlockID
+	if err := decodeParams(params, &id); err != nil {
+		return nil, err
+	}
+	header, err := s.backend.HeaderByNumberOrHash(ctx, id.BlockNumberOrHash())
+	if header == nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+func (s *Service) block(ctx context.Context, params rlp.RawValue) (interface{}, error) {
+	var id binrpc.BlockID
+	if err := decodeParams(params, &id); err != nil {
+		return nil, err
+	}
+	block, err := s.backend.BlockByNumberOrHash(ctx, id.BlockNumberOrHash())
+	if block == nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+func (s *Service) receipts(ctx context.Context, params rlp.RawValue) (interface{}, error) {
+	var id binrpc.BlockID
+	if err := decodeParams(params, &id); err != nil {
+		return nil, err
+	}
+	header, err := s.backend.HeaderByNumberOrHash(ctx, id.BlockNumberOrHash())
+	if header == nil {
+		return nil, err
+	}
+	receipts, err := s.backend.GetReceipts(ctx, header.Hash())
+	if err != nil {
+		return nil, err
+	}
+	enc := make([]*binrpc.Receipt, len(receipts))
+	for i, receipt := range receipts {
+		enc[i] = binrpc.NewReceipt(receipt)
+	}
+	return enc, nil
+}
+
+func (s *Service) receipt(ctx context.Context, params rlp.RawValue) (interface{}, error) {
+	var hash common.Hash
+	if err := decodeParams(params, &hash); err != nil {
+		return nil, err
+	}
+	found, _, blockHash, _, index, err := s.backend.GetTransaction(ctx, hash)
+	if err != nil {
+		return nil, ethapi.NewTxIndexingError()
+	}
+	if !found {
+		return nil, nil
+	}
+	receipts, err := s.backend.GetReceipts(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(receipts)) <= index {
+		return nil, nil
+	}
+	return binrpc.NewReceipt(receipts[index]), nil
+}
+
+func (s *Service) transaction(ctx context.Context, params rlp.RawValue) (interface{}, error) {
+	var hash common.Hash
+	if err := decodeParams(params, &hash); err != nil {
+		return nil, err
+	}
+	found, tx, blockHash, blockNumber, index, err := s.backend.GetTransaction(ctx, hash)
+	if err != nil {
+		return nil, ethapi.NewTxIndexingError()
+	}
+	if found {
+		return &binrpc.Transaction{Tx: tx, BlockHash: blockHash, BlockNumber: blockNumber, Index: index}, nil
+	}
+	if tx := s.backend.GetPoolTransaction(hash); tx != nil {
+		return &binrpc.Transaction{Tx: tx, Pending: true}, nil
+	}
+	return nil, nil
+}
+
+func (s *Service) logs(ctx context.Context, params rlp.RawValue) (interface{}, error) {
+	var query binrpc.FilterQuery
+	if err := decodeParams(params, &query); err != nil {
+		return nil, err
+	}
+	logs, err := s.filters.GetLogs(ctx, filters.FilterCriteria(filterQuery(query)))
+	if err != nil {
+		return nil, err
+	}
+	enc := make([]*binrpc.Log, len(logs))
+	for i, log := range logs {
+		enc[i] = binrpc.NewLog(log)
+	}
+	return enc, nil
+}
+
+// account runs a query against the state of an account.
+func (s *Service) account(ctx context.Context, params rlp.RawValue, fn func(state *state.StateDB, addr common.Address) interface{}) (interface{}, error) {
+	var query binrpc.AccountQuery
+	if err := decodeParams(params, &query); err != nil {
+		return nil, err
+	}
+	state, _, err := s.backend.StateAndHeaderByNumberOrHash(ctx, query.Block.BlockNumberOrHash())
+	if state == nil || err != nil {
+		return nil, err
+	}
+	result := fn(state, query.Address)
+	return result, state.Error()
+}
+
+func (s *Service) balance(ctx context.Context, params rlp.RawValue) (interface{}, error) {
+	return s.account(ctx, params, func(state *state.StateDB, addr common.Address) interface{} {
+		return state.GetBalance(addr)
+	})
+}
+
+func (s *Service) nonce(ctx context.Context, params rlp.RawValue) (interface{}, error) {
+	return s.account(ctx, params, func(state *state.StateDB, addr common.Address) interface{} {
+		return state.GetNonce(addr)
+	})
+}
+
+func (s *Service) code(ctx context.Context, params rlp.RawValue) (interface{}, error) {
+	return s.account(ctx, params, func(state *state.StateDB, addr common.Address) interface{} {
+		return state.GetCode(addr)
+	})
+}
+
+func (s *Service) storage(ctx context.Context, params rlp.RawValue) (interface{}, error) {
+	var query binrpc.StorageQuery
+	if err := decodeParams(params, &query); err != nil {
+		return nil, err
+	}
+	state, _, err := s.backend.StateAndHeaderByNumberOrHash(ctx, query.Block.BlockNumberOrHash())
+	if state == nil || err != nil {
+		return nil, err
+	}
+	value := state.GetState(query.Address, query.Key)
+	return value, state.Error()
+}
@@ -149,13 +149,25 @@ func info(ctx *cli.Context) error {
 	if err != nil {
 		return fmt.Errorf("error reading total difficulty: %w", err)
 	}
+	index, err := e.CheckpointIndex()
+	if err != nil {
+		return fmt.Errorf("error reading checkpoint index: %w", err)
+	}
 	info := struct {
 		Accumulator     common.Hash `json:"accumulator"`
 		TotalDifficulty *big.Int    `json:"totalDifficulty"`
 		StartBlock      uint64      `json:"startBlock"`
 		Count           uint64      `json:"count"`
+		Epoch           uint64      `json:"epoch,omitempty"`
+		Checkpoints     int         `json:"checkpoints,omitempty"`
 	}{
-		acc, td, e.Start(), e.Count(),
+		Accumulator:     acc,
+		TotalDifficulty: td,
+		StartBlock:      e.Start(),
+		Count:           e.Count(),
+	}
+	if index != nil {
+		info.Epoch, info.Checkpoints = index.Epoch, len(index.Checkpoints)
 	}
 	b, _ := json.MarshalIndent(info, "", "  ")
 	fmt.Println(string(b))
@@ -195,6 +207,8 @@ func verify(ctx *cli.Context) error {
 		network  = ctx.String(networkFlag.Name)
 		start    = time.Now()
 		reported = time.Now()
+
+		validators []common.Address // Congress validator set carried across files
 	)
 
 	entries, err := era.ReadDir(dir, network)
@@ -222,8 +236,15 @@ func verify(ctx *cli.Context) error {
 			} else if got != want {
 				return fmt.Errorf("invalid root %s: got %s, want %s", name, got, want)
 			}
-			// Recompute accumulator.
-			if err := checkAccumulator(e); err != nil {
+			// Recompute accumulator. Congress archives are verified against
+			// their validator checkpoints, chained across consecutive files.
+			if index, err := e.CheckpointIndex(); err != nil {
+				return fmt.Errorf("error retrieving checkpoint index for %s: %w", name, err)
+			} else if index != nil {
+				if validators, err = e.VerifyCongress(validators); err != nil {
+					return fmt.Errorf("error verify era1 file %s: %w", name, err)
+				}
+			} else if err := checkAccumulator(e); err != nil {
 				return fmt.Errorf("error verify era1 file %s: %w", name, err)
 			}
 			// Give the user some feedback that something is happening.
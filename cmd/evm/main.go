@@ -101,6 +101,11 @@ var (
 		Usage:    "JSON file with prestate (genesis) config",
 		Category: flags.VMCategory,
 	}
+	GasScheduleFlag = &cli.StringFlag{
+		Name:     "gasschedule",
+		Usage:    "JSON file with a gas schedule override to apply to the chain config",
+		Category: flags.VMCategory,
+	}
 	MachineFlag = &cli.BoolFlag{
 		Name:     "json",
 		Usage:    "output trace logs in machine readable format (json)",
@@ -204,6 +209,7 @@ var vmFlags = []cli.Flag{
 	InputFlag,
 	InputFileFlag,
 	GenesisFlag,
+	GasScheduleFlag,
 	SenderFlag,
 	ReceiverFlag,
 }
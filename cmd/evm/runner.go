@@ -73,6 +73,21 @@ func readGenesis(genesisPath string) *core.Genesis {
 	return genesis
 }
 
+// readGasSchedule reads the given JSON format gas schedule file.
+func readGasSchedule(path string) *params.GasSchedule {
+	file, err := os.Open(path)
+	if err != nil {
+		utils.Fatalf("Failed to read gas schedule file: %v", err)
+	}
+	defer file.Close()
+
+	schedule := new(params.GasSchedule)
+	if err := json.NewDecoder(file).Decode(schedule); err != nil {
+		utils.Fatalf("invalid gas schedule file: %v", err)
+	}
+	return schedule
+}
+
 type execStats struct {
 	time           time.Duration // The execution time.
 	allocs         int64         // The number of heap allocations during execution.
@@ -146,6 +161,16 @@ func runCmd(ctx *cli.Context) error {
 	} else {
 		genesisConfig.Config = params.AllDevChainProtocolChanges
 	}
+	if ctx.String(GasScheduleFlag.Name) != "" {
+		schedule := readGasSchedule(ctx.String(GasScheduleFlag.Name))
+		if err := vm.ValidateGasSchedule(schedule); err != nil {
+			utils.Fatalf("Invalid gas schedule: %v", err)
+		}
+		// Copy the config, it might be one of the shared presets
+		config := *genesisConfig.Config
+		config.GasSchedules = []*params.GasSchedule{schedule}
+		genesisConfig.Config = &config
+	}
 
 	db := rawdb.NewMemoryDatabase()
 	triedb := triedb.NewDatabase(db, &triedb.Config{
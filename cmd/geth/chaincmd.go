@@ -102,6 +102,10 @@ if one is set.  Otherwise it prints the genesis from the datadir.`,
 			utils.TxLookupLimitFlag,
 			utils.TransactionHistoryFlag,
 			utils.StateHistoryFlag,
+			utils.HistoryPruneFlag,
+			utils.AddressIndexFlag,
+			utils.LogIndexFlag,
+			utils.VMParallelWorkersFlag,
 		}, utils.DatabaseFlags),
 		Description: `
 The import command imports blocks from an RLP-encoded form. The form can be one file
@@ -151,6 +155,23 @@ from Era archives.
 		Description: `
 The export-history command will export blocks and their corresponding receipts
 into Era archives. Eras are typically packaged in steps of 8192 blocks.
+`,
+	}
+	pruneHistoryCommand = &cli.Command{
+		Action: pruneHistory,
+		Name:   "prune-history",
+		Usage:  "Prune ancient block bodies and receipts",
+		Flags: flags.Merge([]cli.Flag{
+			utils.HistoryPruneFlag,
+		},
+			utils.DatabaseFlags,
+			utils.NetworkFlags,
+		),
+		Description: `
+The prune-history command drops the bodies and receipts of all blocks except for
+the most recent --history.prune ones from the ancient store. Headers and the
+transaction indexes are retained. Only blocks already moved into the ancient
+store are pruned.
 `,
 	}
 	importPreimagesCommand = &cli.Command{
@@ -496,6 +517,35 @@ func exportHistory(ctx *cli.Context) error {
 	return nil
 }
 
+// pruneHistory drops the ancient block bodies and receipts which fell out of
+// the configured retention window.
+func pruneHistory(ctx *cli.Context) error {
+	retain := ctx.Uint64(utils.HistoryPruneFlag.Name)
+	if retain == 0 {
+		utils.Fatalf("The number of blocks to retain must be set with --%s", utils.HistoryPruneFlag.Name)
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, false)
+	defer db.Close()
+
+	head := rawdb.ReadHeadBlock(db)
+	if head == nil {
+		utils.Fatalf("Failed to load head block")
+	}
+	var (
+		start = time.Now()
+		old   = core.HistoryTail(db)
+	)
+	tail, err := core.PruneHistory(db, head.NumberU64(), retain)
+	if err != nil {
+		utils.Fatalf("History pruning failed: %v", err)
+	}
+	log.Info("Pruned chain history", "head", head.NumberU64(), "from", old, "tail", tail, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
 // importPreimages imports preimage data from the specified file.
 // it is deprecated, and the export function has been removed, but
 // the import function is kept around for the time being so that
@@ -36,6 +36,7 @@ import (
 	"github.com/ethereum/go-ethereum/internal/debug"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/internal/tracing"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/node"
@@ -91,6 +92,9 @@ var (
 		utils.TxLookupLimitFlag, // deprecated
 		utils.TransactionHistoryFlag,
 		utils.StateHistoryFlag,
+		utils.HistoryPruneFlag,
+		utils.AddressIndexFlag,
+		utils.LogIndexFlag,
 		utils.LightServeFlag,    // deprecated
 		utils.LightIngressFlag,  // deprecated
 		utils.LightEgressFlag,   // deprecated
@@ -125,6 +129,7 @@ var (
 		utils.MinerExtraDataFlag,
 		utils.MinerRecommitIntervalFlag,
 		utils.MinerNewPayloadTimeout,
+		utils.MinerSpeculativeFlag,
 		utils.NATFlag,
 		utils.NoDiscoverFlag,
 		utils.DiscoveryV4Flag,
@@ -138,6 +143,7 @@ var (
 		utils.DeveloperGasLimitFlag,
 		utils.DeveloperPeriodFlag,
 		utils.VMEnableDebugFlag,
+		utils.VMParallelWorkersFlag,
 		utils.NetworkIdFlag,
 		utils.EthStatsURLFlag,
 		utils.NoCompactionFlag,
@@ -163,12 +169,23 @@ var (
 		utils.GraphQLEnabledFlag,
 		utils.GraphQLCORSDomainFlag,
 		utils.GraphQLVirtualHostsFlag,
+		utils.BinaryRPCEnabledFlag,
+		utils.BinaryRPCListenAddrFlag,
+		utils.BinaryRPCPortFlag,
 		utils.HTTPApiFlag,
+		utils.HTTPMethodsAllowFlag,
+		utils.HTTPMethodsDenyFlag,
 		utils.HTTPPathPrefixFlag,
+		utils.HealthMaxHeadAgeFlag,
+		utils.HealthMinPeersFlag,
+		utils.HealthMaxBlocksBehindFlag,
+		utils.HealthSealingFlag,
 		utils.WSEnabledFlag,
 		utils.WSListenAddrFlag,
 		utils.WSPortFlag,
 		utils.WSApiFlag,
+		utils.WSMethodsAllowFlag,
+		utils.WSMethodsDenyFlag,
 		utils.WSAllowedOriginsFlag,
 		utils.WSPathPrefixFlag,
 		utils.IPCDisabledFlag,
@@ -178,9 +195,17 @@ var (
 		utils.RPCGlobalEVMTimeoutFlag,
 		utils.RPCGlobalTxFeeCapFlag,
 		utils.RPCGlobalMaxBlockSpanFlag,
+		utils.RPCStoreTracesFlag,
 		utils.AllowUnprotectedTxs,
 		utils.BatchRequestLimit,
 		utils.BatchResponseMaxSize,
+		utils.RPCRateLimitFlag,
+		utils.RPCRateLimitBurstFlag,
+		utils.RPCRateLimitMethodsFlag,
+		utils.RPCRateLimitWeightsFlag,
+		utils.RPCRateLimitKeyHeaderFlag,
+		utils.RPCJWTKeysFlag,
+		utils.RPCCacheFlag,
 	}
 
 	metricsFlags = []cli.Flag{
@@ -213,6 +238,7 @@ func init() {
 		exportCommand,
 		importHistoryCommand,
 		exportHistoryCommand,
+		pruneHistoryCommand,
 		importPreimagesCommand,
 		removedbCommand,
 		dumpCommand,
@@ -238,6 +264,8 @@ func init() {
 		snapshotCommand,
 		// See verkle.go
 		verkleCommand,
+		// See tracecmd.go
+		traceCommand,
 	}
 	if logTestCommand != nil {
 		app.Commands = append(app.Commands, logTestCommand)
@@ -249,6 +277,7 @@ func init() {
 		rpcFlags,
 		consoleFlags,
 		debug.Flags,
+		tracing.Flags,
 		metricsFlags,
 	)
 	flags.AutoEnvVars(app.Flags, "GETH")
@@ -259,10 +288,14 @@ func init() {
 		if err := debug.Setup(ctx); err != nil {
 			return err
 		}
+		if err := tracing.Setup(ctx); err != nil {
+			return err
+		}
 		flags.CheckEnvVars(ctx, app.Flags, "GETH")
 		return nil
 	}
 	app.After = func(ctx *cli.Context) error {
+		tracing.Exit()
 		debug.Exit()
 		prompt.Stdin.Close() // Resets terminal mode.
 		return nil
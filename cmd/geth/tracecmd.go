@@ -0,0 +1,154 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/log"
+	cli "github.com/urfave/cli/v2"
+)
+
+var (
+	traceTracerFlag = &cli.StringFlag{
+		Name:  "tracer",
+		Usage: "Name of the tracer whose results to store (callTracer or flatCallTracer)",
+		Value: "callTracer",
+	}
+	traceTracerConfigFlag = &cli.StringFlag{
+		Name:  "tracerconfig",
+		Usage: "JSON encoded configuration of the tracer",
+	}
+	traceReexecFlag = &cli.Uint64Flag{
+		Name:  "reexec",
+		Usage: "Number of blocks to re-execute at most to regenerate missing historical state",
+		Value: 128,
+	}
+
+	traceCommand = &cli.Command{
+		Name:  "traces",
+		Usage: "A set of commands managing the persistent block trace store",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "backfill",
+				Usage:     "Trace a range of canonical blocks and persist the results",
+				ArgsUsage: "<start> <end>",
+				Action:    backfillTraces,
+				Flags: flags.Merge([]cli.Flag{
+					traceTracerFlag,
+					traceTracerConfigFlag,
+					traceReexecFlag,
+				}, nodeFlags),
+				Description: `
+geth traces backfill <start> <end>
+traces all canonical blocks in the inclusive range with the configured tracer
+and stores the results in the database, so that a node running with
+--trace.store serves them without re-execution. Only callTracer and
+flatCallTracer results can be stored.`,
+			},
+			{
+				Name:      "prune",
+				Usage:     "Delete the persisted traces of a range of blocks",
+				ArgsUsage: "<start> <end>",
+				Action:    pruneTraces,
+				Flags:     flags.Merge(utils.NetworkFlags, utils.DatabaseFlags),
+				Description: `
+geth traces prune <start> <end>
+deletes the persisted traces of all blocks in the inclusive range, regardless
+of the tracer configuration they were produced with.`,
+			},
+		},
+	}
+)
+
+// parseTraceRange parses the inclusive block range of the trace commands.
+func parseTraceRange(ctx *cli.Context) (uint64, uint64, error) {
+	if ctx.Args().Len() != 2 {
+		return 0, 0, errors.New("expected <start> and <end> block numbers")
+	}
+	start, err := strconv.ParseUint(ctx.Args().Get(0), 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid start block: %v", err)
+	}
+	end, err := strconv.ParseUint(ctx.Args().Get(1), 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid end block: %v", err)
+	}
+	if start > end {
+		return 0, 0, fmt.Errorf("end block (#%d) needs to come after start block (#%d)", end, start)
+	}
+	return start, end, nil
+}
+
+func backfillTraces(ctx *cli.Context) error {
+	start, end, err := parseTraceRange(ctx)
+	if err != nil {
+		return err
+	}
+	var (
+		tracer = ctx.String(traceTracerFlag.Name)
+		reexec = ctx.Uint64(traceReexecFlag.Name)
+		config = &tracers.TraceConfig{Tracer: &tracer, Reexec: &reexec}
+	)
+	if ctx.IsSet(traceTracerConfigFlag.Name) {
+		config.TracerConfig = json.RawMessage(ctx.String(traceTracerConfigFlag.Name))
+	}
+	stack, backend := makeFullNode(ctx)
+	defer stack.Close()
+
+	traceBackend, ok := backend.(tracers.Backend)
+	if !ok {
+		return errors.New("tracing is not supported by the node backend")
+	}
+	begin := time.Now()
+	stored, err := tracers.BackfillTraces(context.Background(), traceBackend, start, end, config)
+	if err != nil {
+		return err
+	}
+	log.Info("Backfilled block traces", "start", start, "end", end, "tracer", tracer, "blocks", stored, "elapsed", common.PrettyDuration(time.Since(begin)))
+	return nil
+}
+
+func pruneTraces(ctx *cli.Context) error {
+	start, end, err := parseTraceRange(ctx)
+	if err != nil {
+		return err
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, false)
+	defer db.Close()
+
+	begin := time.Now()
+	deleted, err := rawdb.DeleteBlockTracesRange(db, start, end+1)
+	if err != nil {
+		return err
+	}
+	log.Info("Pruned block traces", "start", start, "end", end, "deleted", deleted, "elapsed", common.PrettyDuration(time.Since(begin)))
+	return nil
+}
@@ -34,6 +34,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/congress"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state/snapshot"
@@ -265,6 +266,8 @@ func ImportHistory(chain *core.BlockChain, db ethdb.Database, dir string, networ
 		forker   = core.NewForkChoice(chain, nil)
 		h        = sha256.New()
 		buf      = bytes.NewBuffer(nil)
+
+		validators []common.Address // Congress validator set carried across archives
 	)
 	for i, filename := range entries {
 		err := func() error {
@@ -289,6 +292,13 @@ func ImportHistory(chain *core.BlockChain, db ethdb.Database, dir string, networ
 			if err != nil {
 				return fmt.Errorf("error opening era: %w", err)
 			}
+			// Congress archives are self-verifying: check every seal against
+			// the archived validator checkpoints before importing anything.
+			if chain.Config().Congress != nil {
+				if validators, err = e.VerifyCongress(validators); err != nil {
+					return fmt.Errorf("error verifying era: %w", err)
+				}
+			}
 			it, err := era.NewIterator(e)
 			if err != nil {
 				return fmt.Errorf("error making era reader: %w", err)
@@ -299,6 +309,9 @@ func ImportHistory(chain *core.BlockChain, db ethdb.Database, dir string, networ
 					return fmt.Errorf("error reading block %d: %w", it.Number(), err)
 				}
 				if block.Number().BitLen() == 0 {
+					if block.Hash() != chain.Genesis().Hash() {
+						return fmt.Errorf("genesis mismatch: have %x, want %x", block.Hash(), chain.Genesis().Hash())
+					}
 					continue // skip genesis
 				}
 				receipts, err := it.Receipts()
@@ -401,6 +414,30 @@ func ExportAppendChain(blockchain *core.BlockChain, fn string, first uint64, las
 	return nil
 }
 
+// newHistoryBuilder creates an Era1 builder for the archive starting at the
+// given block. Congress chains get archives carrying the validator checkpoints
+// needed to verify the block seals.
+func newHistoryBuilder(bc *core.BlockChain, w io.Writer, first uint64) (*era.Builder, error) {
+	config := bc.Config().Congress
+	if config == nil {
+		return era.NewBuilder(w), nil
+	}
+	epoch := congress.EpochLength(config)
+	if first == 0 {
+		return era.NewCongressBuilder(w, epoch, nil), nil
+	}
+	number := (first - 1) - (first-1)%epoch
+	header := bc.GetHeaderByNumber(number)
+	if header == nil {
+		return nil, fmt.Errorf("export failed on #%d: checkpoint not found", number)
+	}
+	validators, err := congress.CheckpointValidators(header)
+	if err != nil {
+		return nil, fmt.Errorf("export failed on #%d: %w", number, err)
+	}
+	return era.NewCongressBuilder(w, epoch, validators), nil
+}
+
 // ExportHistory exports blockchain history into the specified directory,
 // following the Era format.
 func ExportHistory(bc *core.BlockChain, dir string, first, last, step uint64) error {
@@ -432,7 +469,10 @@ func ExportHistory(bc *core.BlockChain, dir string, first, last, step uint64) er
 			}
 			defer f.Close()
 
-			w := era.NewBuilder(f)
+			w, err := newHistoryBuilder(bc, f, i)
+			if err != nil {
+				return err
+			}
 			for j := uint64(0); j < step && j <= last-i; j++ {
 				var (
 					n     = i + j
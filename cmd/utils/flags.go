@@ -37,6 +37,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
+	binservice "github.com/ethereum/go-ethereum/binrpc/service"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/fdlimit"
 	"github.com/ethereum/go-ethereum/core"
@@ -56,6 +57,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb/remotedb"
 	"github.com/ethereum/go-ethereum/ethstats"
 	"github.com/ethereum/go-ethereum/graphql"
+	"github.com/ethereum/go-ethereum/health"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/internal/flags"
 	"github.com/ethereum/go-ethereum/log"
@@ -287,6 +289,22 @@ var (
 		Value:    ethconfig.Defaults.TransactionHistory,
 		Category: flags.StateCategory,
 	}
+	HistoryPruneFlag = &cli.Uint64Flag{
+		Name:     "history.prune",
+		Usage:    "Number of recent blocks to retain bodies and receipts for, older ones are pruned from the ancient store (0 = entire chain)",
+		Value:    ethconfig.Defaults.HistoryPrune,
+		Category: flags.StateCategory,
+	}
+	AddressIndexFlag = &cli.BoolFlag{
+		Name:     "history.addresses",
+		Usage:    "Index transactions by sender and recipient address for the blocks covered by the transaction history",
+		Category: flags.StateCategory,
+	}
+	LogIndexFlag = &cli.BoolFlag{
+		Name:     "history.logs",
+		Usage:    "Index the logs of the entire chain by address and topics, replacing the bloombits once built",
+		Category: flags.StateCategory,
+	}
 	// Transaction pool settings
 	TxPoolLocalsFlag = &cli.StringFlag{
 		Name:     "txpool.locals",
@@ -470,6 +488,11 @@ var (
 		Value:    ethconfig.Defaults.Miner.NewPayloadTimeout,
 		Category: flags.MinerCategory,
 	}
+	MinerSpeculativeFlag = &cli.BoolFlag{
+		Name:     "miner.speculative",
+		Usage:    "Keep updating the block being sealed with new transactions until the in-turn slot (Congress)",
+		Category: flags.MinerCategory,
+	}
 
 	// Account settings
 	UnlockedAccountFlag = &cli.StringFlag{
@@ -502,6 +525,11 @@ var (
 		Usage:    "Record information useful for VM and contract debugging",
 		Category: flags.VMCategory,
 	}
+	VMParallelWorkersFlag = &cli.IntFlag{
+		Name:     "vm.parallel",
+		Usage:    "Number of workers executing block transactions speculatively in parallel (0 = sequential, experimental)",
+		Category: flags.VMCategory,
+	}
 
 	// API options.
 	RPCGlobalGasCapFlag = &cli.Uint64Flag{
@@ -528,6 +556,11 @@ var (
 		Value:    ethconfig.Defaults.RPCMaxBlockSpan,
 		Category: flags.APICategory,
 	}
+	RPCStoreTracesFlag = &cli.BoolFlag{
+		Name:     "trace.store",
+		Usage:    "Persist callTracer/flatCallTracer results of canonical blocks and serve them from the database",
+		Category: flags.APICategory,
+	}
 	// Authenticated RPC HTTP settings
 	AuthListenFlag = &cli.StringFlag{
 		Name:     "authrpc.addr",
@@ -642,6 +675,45 @@ var (
 		Value:    strings.Join(node.DefaultConfig.GraphQLVirtualHosts, ","),
 		Category: flags.APICategory,
 	}
+	BinaryRPCEnabledFlag = &cli.BoolFlag{
+		Name:     "binrpc",
+		Usage:    "Enable the binary RPC server (RLP over HTTP/2), not supported with RPC rate limits, method policies or JWT client keys",
+		Category: flags.APICategory,
+	}
+	BinaryRPCListenAddrFlag = &cli.StringFlag{
+		Name:     "binrpc.addr",
+		Usage:    "Binary RPC server listening interface",
+		Value:    node.DefaultBinaryHost,
+		Category: flags.APICategory,
+	}
+	BinaryRPCPortFlag = &cli.IntFlag{
+		Name:     "binrpc.port",
+		Usage:    "Binary RPC server listening port",
+		Value:    node.DefaultBinaryPort,
+		Category: flags.APICategory,
+	}
+	HealthMaxHeadAgeFlag = &cli.DurationFlag{
+		Name:     "health.maxheadage",
+		Usage:    "Maximum age of the chain head reported healthy on /health and /ready (0 = 10 block periods)",
+		Category: flags.APICategory,
+	}
+	HealthMinPeersFlag = &cli.IntFlag{
+		Name:     "health.minpeers",
+		Usage:    "Minimum number of peers reported healthy on /health and /ready",
+		Value:    health.DefaultConfig.MinPeers,
+		Category: flags.APICategory,
+	}
+	HealthMaxBlocksBehindFlag = &cli.Uint64Flag{
+		Name:     "health.maxblocksbehind",
+		Usage:    "Maximum number of blocks behind the best known chain reported ready on /ready",
+		Value:    health.DefaultConfig.MaxBlocksBehind,
+		Category: flags.APICategory,
+	}
+	HealthSealingFlag = &cli.BoolFlag{
+		Name:     "health.sealing",
+		Usage:    "Report the node unhealthy on /health and /ready if it isn't sealing blocks",
+		Category: flags.APICategory,
+	}
 	WSEnabledFlag = &cli.BoolFlag{
 		Name:     "ws",
 		Usage:    "Enable the WS-RPC server",
@@ -659,6 +731,16 @@ var (
 		Value:    node.DefaultWSPort,
 		Category: flags.APICategory,
 	}
+	HTTPMethodsAllowFlag = &cli.StringFlag{
+		Name:     "http.methods.allow",
+		Usage:    "Comma separated methods, method prefixes (e.g. debug_trace*) or namespaces allowed over the HTTP-RPC interface",
+		Category: flags.APICategory,
+	}
+	HTTPMethodsDenyFlag = &cli.StringFlag{
+		Name:     "http.methods.deny",
+		Usage:    "Comma separated methods, method prefixes (e.g. debug_set*) or namespaces denied over the HTTP-RPC interface",
+		Category: flags.APICategory,
+	}
 	WSApiFlag = &cli.StringFlag{
 		Name:     "ws.api",
 		Usage:    "API's offered over the WS-RPC interface",
@@ -671,6 +753,16 @@ var (
 		Value:    "",
 		Category: flags.APICategory,
 	}
+	WSMethodsAllowFlag = &cli.StringFlag{
+		Name:     "ws.methods.allow",
+		Usage:    "Comma separated methods, method prefixes (e.g. debug_trace*) or namespaces allowed over the WS-RPC interface",
+		Category: flags.APICategory,
+	}
+	WSMethodsDenyFlag = &cli.StringFlag{
+		Name:     "ws.methods.deny",
+		Usage:    "Comma separated methods, method prefixes (e.g. debug_set*) or namespaces denied over the WS-RPC interface",
+		Category: flags.APICategory,
+	}
 	WSPathPrefixFlag = &cli.StringFlag{
 		Name:     "ws.rpcprefix",
 		Usage:    "HTTP path prefix on which JSON-RPC is served. Use '/' to serve on all paths.",
@@ -704,6 +796,42 @@ var (
 		Value:    node.DefaultConfig.BatchResponseMaxSize,
 		Category: flags.APICategory,
 	}
+	RPCRateLimitFlag = &cli.Float64Flag{
+		Name:     "rpc.ratelimit",
+		Usage:    "Maximum number of HTTP and WebSocket RPC calls per second of a client (0 = unlimited)",
+		Category: flags.APICategory,
+	}
+	RPCRateLimitBurstFlag = &cli.Float64Flag{
+		Name:     "rpc.ratelimit.burst",
+		Usage:    "Maximum number of RPC calls a client can make in a burst (defaults to the rate)",
+		Category: flags.APICategory,
+	}
+	RPCRateLimitMethodsFlag = &cli.StringFlag{
+		Name:     "rpc.ratelimit.methods",
+		Usage:    "Comma separated per-client limits of methods or namespaces as name=rate[:burst] (e.g. debug_trace*=1:5,eth_getLogs=10)",
+		Category: flags.APICategory,
+	}
+	RPCRateLimitWeightsFlag = &cli.StringFlag{
+		Name:     "rpc.ratelimit.weights",
+		Usage:    "Comma separated number of tokens consumed by methods or namespaces as name=weight (e.g. eth_getLogs=10,debug=5)",
+		Category: flags.APICategory,
+	}
+	RPCRateLimitKeyHeaderFlag = &cli.StringFlag{
+		Name:     "rpc.ratelimit.keyheader",
+		Usage:    "HTTP header carrying the API keys configured in the config file",
+		Value:    "X-API-Key",
+		Category: flags.APICategory,
+	}
+	RPCJWTKeysFlag = &flags.DirectoryFlag{
+		Name:     "rpc.jwtkeys",
+		Usage:    "Directory of the keys verifying the JWT tokens required by the HTTP and WebSocket RPC endpoints, and by GraphQL",
+		Category: flags.APICategory,
+	}
+	RPCCacheFlag = &cli.IntFlag{
+		Name:     "rpc.cache",
+		Usage:    "Megabytes of memory allocated to caching immutable HTTP and WebSocket RPC results (0 = disabled)",
+		Category: flags.APICategory,
+	}
 	EnablePersonal = &cli.BoolFlag{
 		Name:     "rpc.enabledeprecatedpersonal",
 		Usage:    "Enables the (deprecated) personal namespace",
@@ -1138,6 +1266,7 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(HTTPApiFlag.Name) {
 		cfg.HTTPModules = SplitAndTrim(ctx.String(HTTPApiFlag.Name))
 	}
+	setMethodPolicy(ctx, &cfg.HTTPMethods, HTTPMethodsAllowFlag, HTTPMethodsDenyFlag)
 
 	if ctx.IsSet(HTTPVirtualHostsFlag.Name) {
 		cfg.HTTPVirtualHosts = SplitAndTrim(ctx.String(HTTPVirtualHostsFlag.Name))
@@ -1157,6 +1286,93 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(BatchResponseMaxSize.Name) {
 		cfg.BatchResponseMaxSize = ctx.Int(BatchResponseMaxSize.Name)
 	}
+	setRPCRateLimit(ctx, cfg)
+
+	if ctx.IsSet(RPCCacheFlag.Name) {
+		cfg.RPCCacheSize = ctx.Int(RPCCacheFlag.Name)
+	}
+}
+
+// setMethodPolicy applies the method allow and deny flags of an RPC endpoint on
+// top of the method policy configured in the config file.
+func setMethodPolicy(ctx *cli.Context, policy **rpc.MethodPolicy, allow, deny *cli.StringFlag) {
+	if !ctx.IsSet(allow.Name) && !ctx.IsSet(deny.Name) {
+		return
+	}
+	if *policy == nil {
+		*policy = new(rpc.MethodPolicy)
+	}
+	if ctx.IsSet(allow.Name) {
+		(*policy).Allow = SplitAndTrim(ctx.String(allow.Name))
+	}
+	if ctx.IsSet(deny.Name) {
+		(*policy).Deny = SplitAndTrim(ctx.String(deny.Name))
+	}
+}
+
+// setRPCRateLimit applies the RPC rate limiting flags on top of the rate limits
+// configured in the config file.
+func setRPCRateLimit(ctx *cli.Context, cfg *node.Config) {
+	if !ctx.IsSet(RPCRateLimitFlag.Name) && !ctx.IsSet(RPCRateLimitBurstFlag.Name) &&
+		!ctx.IsSet(RPCRateLimitMethodsFlag.Name) && !ctx.IsSet(RPCRateLimitWeightsFlag.Name) &&
+		!ctx.IsSet(RPCRateLimitKeyHeaderFlag.Name) {
+		return
+	}
+	if cfg.RPCRateLimit == nil {
+		cfg.RPCRateLimit = &rpc.RateLimitConfig{KeyHeader: RPCRateLimitKeyHeaderFlag.Value}
+	}
+	limits := cfg.RPCRateLimit
+	if ctx.IsSet(RPCRateLimitFlag.Name) {
+		limits.Limit.Rate = ctx.Float64(RPCRateLimitFlag.Name)
+	}
+	if ctx.IsSet(RPCRateLimitBurstFlag.Name) {
+		limits.Limit.Burst = ctx.Float64(RPCRateLimitBurstFlag.Name)
+	}
+	if ctx.IsSet(RPCRateLimitKeyHeaderFlag.Name) {
+		limits.KeyHeader = ctx.String(RPCRateLimitKeyHeaderFlag.Name)
+	}
+	if ctx.IsSet(RPCRateLimitMethodsFlag.Name) {
+		limits.Methods = make(map[string]rpc.RateLimit)
+		for name, value := range parseRateLimitPairs(RPCRateLimitMethodsFlag.Name, ctx.String(RPCRateLimitMethodsFlag.Name)) {
+			rate, burst, _ := strings.Cut(value, ":")
+			var (
+				limit rpc.RateLimit
+				err   error
+			)
+			if limit.Rate, err = strconv.ParseFloat(rate, 64); err != nil {
+				Fatalf("Invalid rate limit of %s in --%s: %v", name, RPCRateLimitMethodsFlag.Name, err)
+			}
+			if burst != "" {
+				if limit.Burst, err = strconv.ParseFloat(burst, 64); err != nil {
+					Fatalf("Invalid burst limit of %s in --%s: %v", name, RPCRateLimitMethodsFlag.Name, err)
+				}
+			}
+			limits.Methods[name] = limit
+		}
+	}
+	if ctx.IsSet(RPCRateLimitWeightsFlag.Name) {
+		limits.Weights = make(map[string]float64)
+		for name, value := range parseRateLimitPairs(RPCRateLimitWeightsFlag.Name, ctx.String(RPCRateLimitWeightsFlag.Name)) {
+			weight, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				Fatalf("Invalid weight of %s in --%s: %v", name, RPCRateLimitWeightsFlag.Name, err)
+			}
+			limits.Weights[name] = weight
+		}
+	}
+}
+
+// parseRateLimitPairs splits a comma separated list of name=value pairs.
+func parseRateLimitPairs(flag string, list string) map[string]string {
+	pairs := make(map[string]string)
+	for _, item := range SplitAndTrim(list) {
+		name, value, ok := strings.Cut(item, "=")
+		if !ok || name == "" {
+			Fatalf("Invalid entry %q in --%s, expected name=value", item, flag)
+		}
+		pairs[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return pairs
 }
 
 // setGraphQL creates the GraphQL listener interface string from the set
@@ -1192,12 +1408,29 @@ func setWS(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(WSApiFlag.Name) {
 		cfg.WSModules = SplitAndTrim(ctx.String(WSApiFlag.Name))
 	}
+	setMethodPolicy(ctx, &cfg.WSMethods, WSMethodsAllowFlag, WSMethodsDenyFlag)
 
 	if ctx.IsSet(WSPathPrefixFlag.Name) {
 		cfg.WSPathPrefix = ctx.String(WSPathPrefixFlag.Name)
 	}
 }
 
+// setBinaryRPC creates the binary RPC listener interface string from the set
+// command line flags, returning empty if the binary RPC endpoint is disabled.
+func setBinaryRPC(ctx *cli.Context, cfg *node.Config) {
+	if ctx.Bool(BinaryRPCEnabledFlag.Name) {
+		if cfg.BinaryHost == "" {
+			cfg.BinaryHost = "127.0.0.1"
+		}
+		if ctx.IsSet(BinaryRPCListenAddrFlag.Name) {
+			cfg.BinaryHost = ctx.String(BinaryRPCListenAddrFlag.Name)
+		}
+	}
+	if ctx.IsSet(BinaryRPCPortFlag.Name) {
+		cfg.BinaryPort = ctx.Int(BinaryRPCPortFlag.Name)
+	}
+}
+
 // setIPC creates an IPC path configuration from the set command line flags,
 // returning an empty string if IPC was explicitly disabled, or the set path.
 func setIPC(ctx *cli.Context, cfg *node.Config) {
@@ -1372,6 +1605,7 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	setHTTP(ctx, cfg)
 	setGraphQL(ctx, cfg)
 	setWS(ctx, cfg)
+	setBinaryRPC(ctx, cfg)
 	setNodeUserIdent(ctx, cfg)
 	SetDataDir(ctx, cfg)
 	setSmartCard(ctx, cfg)
@@ -1379,6 +1613,9 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	if ctx.IsSet(JWTSecretFlag.Name) {
 		cfg.JWTSecret = ctx.String(JWTSecretFlag.Name)
 	}
+	if ctx.IsSet(RPCJWTKeysFlag.Name) {
+		cfg.JWTKeysDir = ctx.String(RPCJWTKeysFlag.Name)
+	}
 
 	if ctx.IsSet(EnablePersonal.Name) {
 		cfg.EnablePersonal = true
@@ -1532,6 +1769,9 @@ func setMiner(ctx *cli.Context, cfg *miner.Config) {
 	if ctx.IsSet(MinerNewPayloadTimeout.Name) {
 		cfg.NewPayloadTimeout = ctx.Duration(MinerNewPayloadTimeout.Name)
 	}
+	if ctx.IsSet(MinerSpeculativeFlag.Name) {
+		cfg.Speculative = ctx.Bool(MinerSpeculativeFlag.Name)
+	}
 }
 
 func setRequiredBlocks(ctx *cli.Context, cfg *ethconfig.Config) {
@@ -1671,6 +1911,15 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(StateHistoryFlag.Name) {
 		cfg.StateHistory = ctx.Uint64(StateHistoryFlag.Name)
 	}
+	if ctx.IsSet(HistoryPruneFlag.Name) {
+		cfg.HistoryPrune = ctx.Uint64(HistoryPruneFlag.Name)
+	}
+	if ctx.IsSet(AddressIndexFlag.Name) {
+		cfg.AddressIndex = ctx.Bool(AddressIndexFlag.Name)
+	}
+	if ctx.IsSet(LogIndexFlag.Name) {
+		cfg.LogIndex = ctx.Bool(LogIndexFlag.Name)
+	}
 	if ctx.IsSet(StateSchemeFlag.Name) {
 		cfg.StateScheme = ctx.String(StateSchemeFlag.Name)
 	}
@@ -1724,6 +1973,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 		// TODO(fjl): force-enable this in --dev mode
 		cfg.EnablePreimageRecording = ctx.Bool(VMEnableDebugFlag.Name)
 	}
+	if ctx.IsSet(VMParallelWorkersFlag.Name) {
+		cfg.ParallelWorkers = ctx.Int(VMParallelWorkersFlag.Name)
+	}
 
 	if ctx.IsSet(RPCGlobalGasCapFlag.Name) {
 		cfg.RPCGasCap = ctx.Uint64(RPCGlobalGasCapFlag.Name)
@@ -1742,6 +1994,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(RPCGlobalMaxBlockSpanFlag.Name) {
 		cfg.RPCMaxBlockSpan = ctx.Uint64(RPCGlobalMaxBlockSpanFlag.Name)
 	}
+	if ctx.IsSet(RPCStoreTracesFlag.Name) {
+		cfg.StoreTraces = ctx.Bool(RPCStoreTracesFlag.Name)
+	}
 	if ctx.IsSet(NoDiscoverFlag.Name) {
 		cfg.EthDiscoveryURLs, cfg.SnapDiscoveryURLs = []string{}, []string{}
 	} else if ctx.IsSet(DNSDiscoveryFlag.Name) {
@@ -1888,7 +2143,7 @@ func RegisterEthService(stack *node.Node, cfg *ethconfig.Config) (ethapi.Backend
 	if err != nil {
 		Fatalf("Failed to register the Ethereum service: %v", err)
 	}
-	stack.RegisterAPIs(tracers.APIs(backend.APIBackend))
+	stack.RegisterAPIs(tracers.APIs(backend.APIBackend, tracers.Config{StoreTraces: cfg.StoreTraces}))
 	return backend.APIBackend, backend
 }
 
@@ -1899,6 +2154,28 @@ func RegisterEthStatsService(stack *node.Node, backend ethapi.Backend, url strin
 	}
 }
 
+// SetHealthConfig applies health-check-related command line flags to the config.
+func SetHealthConfig(ctx *cli.Context, cfg *health.Config) {
+	if ctx.IsSet(HealthMaxHeadAgeFlag.Name) {
+		cfg.MaxHeadAge = ctx.Duration(HealthMaxHeadAgeFlag.Name)
+	}
+	if ctx.IsSet(HealthMinPeersFlag.Name) {
+		cfg.MinPeers = ctx.Int(HealthMinPeersFlag.Name)
+	}
+	if ctx.IsSet(HealthMaxBlocksBehindFlag.Name) {
+		cfg.MaxBlocksBehind = ctx.Uint64(HealthMaxBlocksBehindFlag.Name)
+	}
+	if ctx.IsSet(HealthSealingFlag.Name) {
+		cfg.RequireSealing = ctx.Bool(HealthSealingFlag.Name)
+	}
+}
+
+// RegisterHealthService adds the health and readiness endpoints to the HTTP
+// server of the node.
+func RegisterHealthService(stack *node.Node, backend *eth.Ethereum, cfg health.Config) {
+	health.New(stack, backend, cfg)
+}
+
 // RegisterGraphQLService adds the GraphQL API to the node.
 func RegisterGraphQLService(stack *node.Node, backend ethapi.Backend, filterSystem *filters.FilterSystem, cfg *node.Config) {
 	err := graphql.New(stack, backend, filterSystem, cfg.GraphQLCors, cfg.GraphQLVirtualHosts)
@@ -1907,6 +2184,11 @@ func RegisterGraphQLService(stack *node.Node, backend ethapi.Backend, filterSyst
 	}
 }
 
+// RegisterBinaryRPCService adds the binary RPC handlers to the node.
+func RegisterBinaryRPCService(stack *node.Node, backend ethapi.Backend, filterSystem *filters.FilterSystem, ethcfg *ethconfig.Config) {
+	binservice.New(stack, backend, filterSystem, ethcfg.RPCMaxBlockSpan)
+}
+
 // RegisterFilterAPI adds the eth log filtering RPC API to the node.
 func RegisterFilterAPI(stack *node.Node, backend ethapi.Backend, ethcfg *ethconfig.Config) *filters.FilterSystem {
 	filterSystem := filters.NewFilterSystem(backend, filters.Config{
@@ -2124,6 +2406,10 @@ func MakeChain(ctx *cli.Context, stack *node.Node, readonly bool) (*core.BlockCh
 		Preimages:           ctx.Bool(CachePreimagesFlag.Name),
 		StateScheme:         scheme,
 		StateHistory:        ctx.Uint64(StateHistoryFlag.Name),
+		HistoryPrune:        ctx.Uint64(HistoryPruneFlag.Name),
+		AddressIndex:        ctx.Bool(AddressIndexFlag.Name),
+		LogIndex:            ctx.Bool(LogIndexFlag.Name),
+		ParallelWorkers:     ctx.Int(VMParallelWorkersFlag.Name),
 	}
 	if cache.TrieDirtyDisabled && !cache.Preimages {
 		cache.Preimages = true
@@ -38,12 +38,14 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/internal/tracing"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/trie"
 	lru "github.com/hashicorp/golang-lru"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/crypto/sha3"
 )
 
@@ -82,6 +84,9 @@ var (
 	proposalAddr           = common.HexToAddress("0x000000000000000000000000000000000000f002")
 )
 
+// spanTracer creates the spans of the system calls made during finalization.
+var spanTracer = tracing.Tracer("github.com/ethereum/go-ethereum/consensus/congress")
+
 // Various error messages to mark blocks invalid. These should be private to
 // prevent engine specific errors from being referenced in the remainder of the
 // codebase, inherently breaking if the engine is swapped out. Please put common
@@ -193,6 +198,7 @@ type Congress struct {
 
 	recents    *lru.ARCCache // Snapshots for recent block to speed up reorgs
 	signatures *lru.ARCCache // Signatures of recent blocks to speed up mining
+	counted    *lru.Cache    // Hashes of the recent blocks counted in the sealing metrics
 
 	proposals map[common.Address]bool // Current list of proposals we are pushing
 
@@ -219,6 +225,7 @@ func New(chainConfig *params.ChainConfig, db ethdb.Database) *Congress {
 	// Allocate the snapshot caches and create the engine
 	recents, _ := lru.NewARC(inmemorySnapshots)
 	signatures, _ := lru.NewARC(inmemorySignatures)
+	counted, _ := lru.New(inmemorySignatures)
 
 	abi := getInteractiveABI()
 
@@ -228,6 +235,7 @@ func New(chainConfig *params.ChainConfig, db ethdb.Database) *Congress {
 		db:          db,
 		recents:     recents,
 		signatures:  signatures,
+		counted:     counted,
 		proposals:   make(map[common.Address]bool),
 		abi:         abi,
 	}
@@ -506,6 +514,7 @@ func (c *Congress) verifySeal(chain consensus.ChainHeaderReader, header *types.H
 			return errWrongDifficulty
 		}
 	}
+	c.countSeal(snap, header, signer)
 
 	return nil
 }
@@ -581,29 +590,38 @@ func (c *Congress) Prepare(chain consensus.ChainHeaderReader, header *types.Head
 func (c *Congress) Finalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, withdrawals []*types.Withdrawal) error {
 	// Initialize all system contracts at block 1.
 	if header.Number.Cmp(common.Big1) == 0 {
-		if err := c.initializeSystemContracts(chain, header, state); err != nil {
+		if err := c.systemCall(chain, header, "initializeSystemContracts", func() error {
+			return c.initializeSystemContracts(chain, header, state)
+		}); err != nil {
 			log.Error("Initialize system contracts failed", "err", err)
 			return err
 		}
 	}
 
 	if header.Difficulty.Cmp(diffInTurn) != 0 {
-		if err := c.tryPunishValidator(chain, header, state); err != nil {
+		if err := c.systemCall(chain, header, "punishValidator", func() error {
+			return c.tryPunishValidator(chain, header, state)
+		}); err != nil {
 			return err
 		}
 	}
 
 	// execute block reward tx.
 	if len(txs) > 0 {
-		if err := c.trySendBlockReward(chain, header, state); err != nil {
+		if err := c.systemCall(chain, header, "sendBlockReward", func() error {
+			return c.trySendBlockReward(chain, header, state)
+		}); err != nil {
 			return err
 		}
 	}
 
 	// do epoch thing at the end, because it will update active validators
 	if header.Number.Uint64()%c.config.Epoch == 0 {
-		newValidators, err := c.doSomethingAtEpoch(chain, header, state)
-		if err != nil {
+		var newValidators []common.Address
+		if err := c.systemCall(chain, header, "updateValidators", func() (err error) {
+			newValidators, err = c.doSomethingAtEpoch(chain, header, state)
+			return err
+		}); err != nil {
 			return err
 		}
 
@@ -656,28 +674,37 @@ func (c *Congress) Finalize(chain consensus.ChainHeaderReader, header *types.Hea
 func (c *Congress) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt, withdrawals []*types.Withdrawal) (*types.Block, error) {
 	// Initialize all system contracts at block 1.
 	if header.Number.Cmp(common.Big1) == 0 {
-		if err := c.initializeSystemContracts(chain, header, state); err != nil {
+		if err := c.systemCall(chain, header, "initializeSystemContracts", func() error {
+			return c.initializeSystemContracts(chain, header, state)
+		}); err != nil {
 			panic(err)
 		}
 	}
 
 	// punish validator if necessary
 	if header.Difficulty.Cmp(diffInTurn) != 0 {
-		if err := c.tryPunishValidator(chain, header, state); err != nil {
+		if err := c.systemCall(chain, header, "punishValidator", func() error {
+			return c.tryPunishValidator(chain, header, state)
+		}); err != nil {
 			panic(err)
 		}
 	}
 
 	// deposit block reward if any tx exists.
 	if len(txs) > 0 {
-		if err := c.trySendBlockReward(chain, header, state); err != nil {
+		if err := c.systemCall(chain, header, "sendBlockReward", func() error {
+			return c.trySendBlockReward(chain, header, state)
+		}); err != nil {
 			panic(err)
 		}
 	}
 
 	// do epoch thing at the end, because it will update active validators
 	if header.Number.Uint64()%c.config.Epoch == 0 {
-		if _, err := c.doSomethingAtEpoch(chain, header, state); err != nil {
+		if err := c.systemCall(chain, header, "updateValidators", func() error {
+			_, err := c.doSomethingAtEpoch(chain, header, state)
+			return err
+		}); err != nil {
 			panic(err)
 		}
 	}
@@ -718,6 +745,17 @@ func (c *Congress) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header
 	return types.NewBlock(header, txs, nil, receipts, trie.NewStackTrie(nil)), nil
 }
 
+// systemCall runs one of the system calls made during the finalization of a
+// block, tracing it in a span nested under the trace carried by the chain.
+func (c *Congress) systemCall(chain consensus.ChainHeaderReader, header *types.Header, name string, call func() error) error {
+	_, span := tracing.Start(consensus.TraceContext(chain), spanTracer, "congress."+name,
+		attribute.Int64("block.number", header.Number.Int64()),
+	)
+	err := call()
+	tracing.End(span, err)
+	return err
+}
+
 func (c *Congress) trySendBlockReward(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB) error {
 	fee := state.GetBalance(consensus.FeeRecorder)
 	if fee.Cmp(common.U2560) <= 0 {
@@ -751,17 +789,7 @@ func (c *Congress) tryPunishValidator(chain consensus.ChainHeaderReader, header
 	if err != nil {
 		return err
 	}
-	validators := snap.validators()
-	outTurnValidator := validators[number%uint64(len(validators))]
-	// check sigend recently or not
-	signedRecently := false
-	for _, recent := range snap.Recents {
-		if recent == outTurnValidator {
-			signedRecently = true
-			break
-		}
-	}
-	if !signedRecently {
+	if outTurnValidator, ok := snap.punished(number); ok {
 		if err := c.punishValidator(outTurnValidator, chain, header, state); err != nil {
 			return err
 		}
@@ -1086,6 +1114,7 @@ func (c *Congress) Seal(chain consensus.ChainHeaderReader, block *types.Block, r
 
 		select {
 		case results <- block.WithSeal(header):
+			c.countSeal(snap, header, val)
 		default:
 			log.Warn("Sealing result is not read by miner", "sealhash", SealHash(header))
 		}
@@ -1105,6 +1134,25 @@ func (c *Congress) CalcDifficulty(chain consensus.ChainHeaderReader, time uint64
 	return calcDifficulty(snap, c.validator)
 }
 
+// InTurn reports whether the local validator is in-turn to sign the block with
+// the given number, as predicted by the validator set on top of parent. For
+// blocks further ahead than the child of parent the prediction does not take
+// validator set updates into account.
+func (c *Congress) InTurn(chain consensus.ChainHeaderReader, parent *types.Header, number uint64) (bool, error) {
+	snap, err := c.snapshot(chain, parent.Number.Uint64(), parent.Hash(), nil)
+	if err != nil {
+		return false, err
+	}
+	c.lock.RLock()
+	val := c.validator
+	c.lock.RUnlock()
+
+	if _, authorized := snap.Validators[val]; !authorized {
+		return false, nil
+	}
+	return snap.inturn(number, val), nil
+}
+
 func calcDifficulty(snap *Snapshot, validator common.Address) *big.Int {
 	if snap.inturn(snap.Number+1, validator) {
 		return new(big.Int).Set(diffInTurn)
@@ -1156,6 +1204,46 @@ func SealHash(header *types.Header) (hash common.Hash) {
 	return hash
 }
 
+// EpochLength returns the number of blocks between validator checkpoints of
+// the given Congress configuration, falling back to the default if unset.
+func EpochLength(config *params.CongressConfig) uint64 {
+	if config == nil || config.Epoch == 0 {
+		return epochLength
+	}
+	return config.Epoch
+}
+
+// Ecrecover extracts the address of the validator which sealed the header.
+func Ecrecover(header *types.Header) (common.Address, error) {
+	if len(header.Extra) < extraSeal {
+		return common.Address{}, errMissingSignature
+	}
+	pubkey, err := crypto.Ecrecover(SealHash(header).Bytes(), header.Extra[len(header.Extra)-extraSeal:])
+	if err != nil {
+		return common.Address{}, err
+	}
+	var validator common.Address
+	copy(validator[:], crypto.Keccak256(pubkey[1:])[12:])
+	return validator, nil
+}
+
+// CheckpointValidators retrieves the validator set embedded in the extra-data
+// of a checkpoint header.
+func CheckpointValidators(header *types.Header) ([]common.Address, error) {
+	if len(header.Extra) < extraVanity+extraSeal {
+		return nil, errMissingSignature
+	}
+	validatorsBytes := len(header.Extra) - extraVanity - extraSeal
+	if validatorsBytes%common.AddressLength != 0 {
+		return nil, errInvalidCheckpointValidators
+	}
+	validators := make([]common.Address, validatorsBytes/common.AddressLength)
+	for i := 0; i < len(validators); i++ {
+		copy(validators[i][:], header.Extra[extraVanity+i*common.AddressLength:])
+	}
+	return validators, nil
+}
+
 // CongressRLP returns the rlp bytes which needs to be signed for the proof-of-stake-authority
 // sealing. The RLP to sign consists of the entire header apart from the 65 byte signature
 // contained at the end of the extra data.
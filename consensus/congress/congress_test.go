@@ -0,0 +1,225 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package congress
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// testerChainReader implements consensus.ChainHeaderReader over a list of
+// headers, indexed by number.
+type testerChainReader struct {
+	config  *params.ChainConfig
+	headers []*types.Header
+}
+
+func (r *testerChainReader) Config() *params.ChainConfig  { return r.config }
+func (r *testerChainReader) CurrentHeader() *types.Header { return r.headers[len(r.headers)-1] }
+func (r *testerChainReader) GetTd(common.Hash, uint64) *big.Int {
+	return nil
+}
+
+func (r *testerChainReader) GetHeaderByNumber(number uint64) *types.Header {
+	if number >= uint64(len(r.headers)) {
+		return nil
+	}
+	return r.headers[number]
+}
+
+func (r *testerChainReader) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header := r.GetHeaderByNumber(number); header != nil && header.Hash() == hash {
+		return header
+	}
+	return nil
+}
+
+func (r *testerChainReader) GetHeaderByHash(hash common.Hash) *types.Header {
+	for _, header := range r.headers {
+		if header.Hash() == hash {
+			return header
+		}
+	}
+	return nil
+}
+
+// newTesterValidators creates the keys of n validators, sorted by address as in
+// the validator set.
+func newTesterValidators(n int) ([]*ecdsa.PrivateKey, []common.Address) {
+	keys := make([]*ecdsa.PrivateKey, n)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return bytes.Compare(crypto.PubkeyToAddress(keys[i].PublicKey).Bytes(), crypto.PubkeyToAddress(keys[j].PublicKey).Bytes()) < 0
+	})
+	addrs := make([]common.Address, n)
+	for i, key := range keys {
+		addrs[i] = crypto.PubkeyToAddress(key.PublicKey)
+	}
+	return keys, addrs
+}
+
+// testerExtra assembles the extra-data of a header, listing the validators on
+// checkpoints.
+func testerExtra(validators []common.Address) []byte {
+	extra := make([]byte, extraVanity, extraVanity+len(validators)*common.AddressLength+extraSeal)
+	for _, validator := range validators {
+		extra = append(extra, validator.Bytes()...)
+	}
+	return append(extra, make([]byte, extraSeal)...)
+}
+
+// Tests that the turn of the local validator is predicted from the snapshot of
+// the parent, including the validator set updated on the checkpoints.
+func TestInTurn(t *testing.T) {
+	var (
+		keys, validators = newTesterValidators(3)
+		config           = &params.ChainConfig{ChainID: big.NewInt(1), Congress: &params.CongressConfig{Period: 1, Epoch: 4}}
+		engine           = New(config, rawdb.NewMemoryDatabase())
+		chain            = &testerChainReader{config: config}
+	)
+	chain.headers = append(chain.headers, &types.Header{
+		Number:     new(big.Int),
+		Difficulty: big.NewInt(1),
+		Extra:      testerExtra(validators),
+	})
+	// Seal blocks in-turn, dropping the last validator on the checkpoint
+	for number := uint64(1); number <= 4; number++ {
+		var (
+			parent = chain.headers[number-1]
+			key    = keys[number%uint64(len(keys))]
+			extra  = testerExtra(nil)
+		)
+		if number == 4 {
+			extra = testerExtra(validators[:2])
+		}
+		header := &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     new(big.Int).SetUint64(number),
+			Difficulty: new(big.Int).Set(diffInTurn),
+			Coinbase:   crypto.PubkeyToAddress(key.PublicKey),
+			Time:       parent.Time + 1,
+			Extra:      extra,
+		}
+		sig, err := crypto.Sign(SealHash(header).Bytes(), key)
+		if err != nil {
+			t.Fatalf("block %d: failed to sign: %v", number, err)
+		}
+		copy(header.Extra[len(header.Extra)-extraSeal:], sig)
+		chain.headers = append(chain.headers, header)
+	}
+	tests := []struct {
+		parent uint64
+		number uint64
+		local  common.Address
+		inturn bool
+	}{
+		// Child of the parent, three validators
+		{0, 1, validators[1], true},
+		{0, 1, validators[0], false},
+		{2, 3, validators[0], true},
+		{2, 3, validators[2], false},
+
+		// Further ahead than the child of the parent
+		{1, 3, validators[0], true},
+		{1, 5, validators[2], true},
+
+		// Past the checkpoint, only the first two validators are left
+		{4, 5, validators[1], true},
+		{4, 5, validators[0], false},
+		{4, 6, validators[0], true},
+		{4, 5, validators[2], false},
+		{4, 6, validators[2], false},
+
+		// The prediction from before the checkpoint doesn't know about the update
+		{3, 5, validators[2], true},
+
+		// A validator which is not part of the set is never in-turn
+		{2, 3, common.Address{0x1}, false},
+	}
+	for i, tt := range tests {
+		engine.Authorize(tt.local, func(accounts.Account, string, []byte) ([]byte, error) {
+			return nil, errors.New("not signing")
+		})
+		inturn, err := engine.InTurn(chain, chain.headers[tt.parent], tt.number)
+		if err != nil {
+			t.Fatalf("test %d: failed to predict turn: %v", i, err)
+		}
+		if inturn != tt.inturn {
+			t.Errorf("test %d: turn mismatch for block %d on %d: have %v, want %v", i, tt.number, tt.parent, inturn, tt.inturn)
+		}
+	}
+	// The turn can't be predicted on an unknown parent
+	unknown := &types.Header{ParentHash: common.Hash{0x1}, Number: big.NewInt(5)}
+	if _, err := engine.InTurn(chain, unknown, 6); !errors.Is(err, consensus.ErrUnknownAncestor) {
+		t.Fatalf("unknown parent error mismatch: have %v, want %v", err, consensus.ErrUnknownAncestor)
+	}
+}
+
+// Tests that the spans of the system calls are nested under the trace carried
+// by the chain the block is finalized with.
+func TestSystemCallTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	var (
+		_, validators = newTesterValidators(1)
+		config        = &params.ChainConfig{ChainID: big.NewInt(1), Congress: &params.CongressConfig{Period: 1, Epoch: 4}}
+		engine        = New(config, rawdb.NewMemoryDatabase())
+		chain         = &testerChainReader{config: config}
+	)
+	chain.headers = append(chain.headers, &types.Header{
+		Number:     new(big.Int),
+		Difficulty: big.NewInt(1),
+		Extra:      testerExtra(validators),
+	})
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	defer parent.End()
+
+	// Punishing the out-of-turn validator fails on the unknown parent, the call
+	// is traced all the same
+	header := &types.Header{ParentHash: common.Hash{0x1}, Number: big.NewInt(2), Difficulty: new(big.Int).Set(diffNoTurn)}
+	if err := engine.Finalize(consensus.WithTrace(chain, ctx), header, nil, nil, nil, nil); !errors.Is(err, consensus.ErrUnknownAncestor) {
+		t.Fatalf("finalization error mismatch: have %v, want %v", err, consensus.ErrUnknownAncestor)
+	}
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("wrong number of spans: have %d, want 1", len(spans))
+	}
+	if span := spans[0]; span.Name() != "congress.punishValidator" || span.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Fatalf("system call span %q not a child of the caller's span: parent %v", span.Name(), span.Parent().SpanID())
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package congress
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	// sealedBlocksCounter counts the blocks sealed by each validator, in-turn
+	// or out-of-turn.
+	sealedBlocksCounter = metrics.NewRegisteredCounterVec("congress/sealed", nil, "validator", "turn")
+
+	// punishedCounter counts the punishments of each validator for missing its
+	// turn.
+	punishedCounter = metrics.NewRegisteredCounterVec("congress/punished", nil, "validator")
+)
+
+// countSeal updates the sealing metrics with a block sealed by the given
+// validator on top of the given snapshot. Blocks are counted once, whether they
+// are sealed locally or imported.
+func (c *Congress) countSeal(snap *Snapshot, header *types.Header, validator common.Address) {
+	if !metrics.Enabled {
+		return
+	}
+	if seen, _ := c.counted.ContainsOrAdd(header.Hash(), struct{}{}); seen {
+		return
+	}
+	turn := "in"
+	if header.Difficulty.Cmp(diffInTurn) != 0 {
+		turn = "out"
+		if missed, ok := snap.punished(header.Number.Uint64()); ok {
+			punishedCounter.With(missed.Hex()).Inc(1)
+		}
+	}
+	sealedBlocksCounter.With(validator.Hex(), turn).Inc(1)
+}
@@ -194,6 +194,19 @@ func (s *Snapshot) validators() []common.Address {
 	return sigs
 }
 
+// punished returns the in-turn validator of the given block, which is punished
+// if the block is signed out-of-turn, unless it signed another block recently.
+func (s *Snapshot) punished(number uint64) (common.Address, bool) {
+	validators := s.validators()
+	outTurnValidator := validators[number%uint64(len(validators))]
+	for _, recent := range s.Recents {
+		if recent == outTurnValidator {
+			return common.Address{}, false
+		}
+	}
+	return outTurnValidator, true
+}
+
 // inturn returns if a validator at a given block height is in-turn or not.
 func (s *Snapshot) inturn(number uint64, validator common.Address) bool {
 	validators, offset := s.validators(), 0
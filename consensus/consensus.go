@@ -18,6 +18,7 @@
 package consensus
 
 import (
+	"context"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -53,6 +54,30 @@ type ChainHeaderReader interface {
 	GetTd(hash common.Hash, number uint64) *big.Int
 }
 
+// TracedChain is a ChainHeaderReader carrying the trace of its user, the spans
+// of the consensus engine are nested under it.
+type TracedChain struct {
+	ChainHeaderReader
+	Context context.Context
+}
+
+// WithTrace returns the chain carrying the given trace, if any.
+func WithTrace(chain ChainHeaderReader, ctx context.Context) ChainHeaderReader {
+	if ctx == nil {
+		return chain
+	}
+	return &TracedChain{ChainHeaderReader: chain, Context: ctx}
+}
+
+// TraceContext returns the trace carried by the chain, or an empty context if
+// there's none.
+func TraceContext(chain ChainHeaderReader) context.Context {
+	if chain, ok := chain.(*TracedChain); ok {
+		return chain.Context
+	}
+	return context.Background()
+}
+
 // ChainReader defines a small collection of methods needed to access the local
 // blockchain during header and/or uncle verification.
 type ChainReader interface {
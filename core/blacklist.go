@@ -16,6 +16,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/params"
 )
 
@@ -134,6 +135,10 @@ var (
 	cacheTimeout = 3 * time.Minute
 
 	parsedABI abi.ABI
+
+	// BlacklistRejectedCounter counts the transactions rejected for involving a
+	// blacklisted address, labelled by the stage rejecting them.
+	BlacklistRejectedCounter = metrics.NewRegisteredCounterVec("blacklist/rejected", nil, "stage")
 )
 
 func initABI() {
@@ -18,6 +18,7 @@
 package core
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -42,6 +43,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/internal/syncx"
+	"github.com/ethereum/go-ethereum/internal/tracing"
 	"github.com/ethereum/go-ethereum/internal/version"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
@@ -50,6 +52,7 @@ import (
 	"github.com/ethereum/go-ethereum/triedb"
 	"github.com/ethereum/go-ethereum/triedb/hashdb"
 	"github.com/ethereum/go-ethereum/triedb/pathdb"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/exp/slices"
 )
 
@@ -90,6 +93,8 @@ var (
 	blockPrefetchExecuteTimer   = metrics.NewRegisteredTimer("chain/prefetch/executes", nil)
 	blockPrefetchInterruptMeter = metrics.NewRegisteredMeter("chain/prefetch/interrupts", nil)
 
+	spanTracer = tracing.Tracer("github.com/ethereum/go-ethereum/core")
+
 	errInsertionInterrupted = errors.New("insertion is interrupted")
 	errChainStopped         = errors.New("blockchain is stopped")
 	errInvalidOldChain      = errors.New("invalid old chain")
@@ -142,6 +147,10 @@ type CacheConfig struct {
 	SnapshotLimit       int           // Memory allowance (MB) to use for caching snapshot entries in memory
 	Preimages           bool          // Whether to store preimage of trie key to the disk
 	StateHistory        uint64        // Number of blocks from head whose state histories are reserved.
+	HistoryPrune        uint64        // Number of blocks from head whose bodies and receipts are reserved (0 = all)
+	AddressIndex        bool          // Whether to index transactions by their sender and recipient addresses
+	LogIndex            bool          // Whether to index logs by their emitting address and topics
+	ParallelWorkers     int           // Number of workers executing block transactions in parallel (0 = sequential)
 	StateScheme         string        // Scheme used to store ethereum states and merkle tree nodes on top
 
 	SnapshotNoBuild bool // Whether the background generation is allowed
@@ -219,6 +228,8 @@ type BlockChain struct {
 	triedb        *triedb.Database                 // The database handler for maintaining trie nodes.
 	stateCache    state.Database                   // State database to reuse between imports (contains state cache)
 	txIndexer     *txIndexer                       // Transaction indexer, might be nil if not enabled
+	historyPruner *historyPruner                   // Chain history pruner, might be nil if not enabled
+	addrSigner    types.Signer                     // Sender deriver of the address index, nil if not enabled
 
 	hc            *HeaderChain
 	rmLogsFeed    event.Feed
@@ -303,12 +314,19 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, genesis *Genesis
 		engine:        engine,
 		vmConfig:      vmConfig,
 	}
+	if cacheConfig.AddressIndex {
+		bc.addrSigner = types.LatestSigner(chainConfig)
+	}
 	bc.flushInterval.Store(int64(cacheConfig.TrieTimeLimit))
 	bc.forker = NewForkChoice(bc, shouldPreserve)
 	bc.stateCache = state.NewDatabaseWithNodeDB(bc.db, bc.triedb)
 	bc.validator = NewBlockValidator(chainConfig, bc, engine)
 	bc.prefetcher = newStatePrefetcher(chainConfig, bc, engine)
-	bc.processor = NewStateProcessor(chainConfig, bc, engine)
+	if cacheConfig.ParallelWorkers > 1 {
+		bc.processor = NewParallelStateProcessor(chainConfig, bc, engine, cacheConfig.ParallelWorkers)
+	} else {
+		bc.processor = NewStateProcessor(chainConfig, bc, engine)
+	}
 
 	var err error
 	bc.hc, err = NewHeaderChain(db, chainConfig, engine, bc.insertStopped)
@@ -468,6 +486,10 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, genesis *Genesis
 	if txLookupLimit != nil {
 		bc.txIndexer = newTxIndexer(*txLookupLimit, bc)
 	}
+	// Start chain history pruner if it's enabled.
+	if cacheConfig.HistoryPrune != 0 {
+		bc.historyPruner = newHistoryPruner(cacheConfig.HistoryPrune, bc)
+	}
 	return bc, nil
 }
 
@@ -930,6 +952,18 @@ func (bc *BlockChain) writeHeadBlock(block *types.Block) {
 	rawdb.WriteHeadFastBlockHash(batch, block.Hash())
 	rawdb.WriteCanonicalHash(batch, block.Hash(), block.NumberU64())
 	rawdb.WriteTxLookupEntriesByBlock(batch, block)
+	if bc.addrSigner != nil {
+		rawdb.WriteAddressTxEntriesByBlock(batch, block, bc.addrSigner)
+
+		// Without an indexer only the blocks written here are indexed, so the
+		// address index starts at the first of them.
+		if bc.txIndexer == nil && rawdb.ReadAddrIndexTail(bc.db) == nil {
+			rawdb.WriteAddrIndexTail(batch, block.NumberU64())
+		}
+	}
+	if bc.cacheConfig.LogIndex {
+		rawdb.WriteLogIndexEntries(batch, block.NumberU64(), rawdb.ReadRawReceipts(bc.db, block.Hash(), block.NumberU64()))
+	}
 	rawdb.WriteHeadBlockHash(batch, block.Hash())
 
 	// Flush the whole batch into the disk, exit the node if failed
@@ -960,6 +994,10 @@ func (bc *BlockChain) stopWithoutSaving() {
 	if bc.txIndexer != nil {
 		bc.txIndexer.close()
 	}
+	// Signal shutdown chain history pruner.
+	if bc.historyPruner != nil {
+		bc.historyPruner.close()
+	}
 	// Unsubscribe all subscriptions registered from blockchain.
 	bc.scope.Close()
 
@@ -1588,6 +1626,12 @@ func (bc *BlockChain) insertChain(chain types.Blocks, setHead bool) (int, error)
 		return 0, nil
 	}
 
+	ctx, span := tracing.Start(context.Background(), spanTracer, "core.insertChain",
+		attribute.Int64("block.first", chain[0].Number().Int64()),
+		attribute.Int("blocks", len(chain)),
+	)
+	defer span.End()
+
 	// Start a parallel signature recovery (signer will fluke on fork transition, minimal perf loss)
 	SenderCacher.RecoverFromBlocks(types.MakeSigner(bc.chainConfig, chain[0].Number(), chain[0].Time()), chain)
 
@@ -1799,7 +1843,11 @@ func (bc *BlockChain) insertChain(chain types.Blocks, setHead bool) (int, error)
 
 		// Process block using the parent state as reference point
 		pstart := time.Now()
-		receipts, logs, usedGas, err := bc.processor.Process(block, statedb, bc.vmConfig)
+		ectx, espan := tracing.Start(ctx, spanTracer, "core.executeBlock", blockAttributes(block)...)
+		vmConfig := bc.vmConfig
+		vmConfig.TraceContext = ectx
+		receipts, logs, usedGas, err := bc.processor.Process(block, statedb, vmConfig)
+		tracing.End(espan, err)
 		if err != nil {
 			bc.reportBlock(block, receipts, err)
 			followupInterrupt.Store(true)
@@ -1808,7 +1856,10 @@ func (bc *BlockChain) insertChain(chain types.Blocks, setHead bool) (int, error)
 		ptime := time.Since(pstart)
 
 		vstart := time.Now()
-		if err := bc.validator.ValidateState(block, statedb, receipts, usedGas); err != nil {
+		_, vspan := tracing.Start(ctx, spanTracer, "core.validateBlock", blockAttributes(block)...)
+		err = bc.validator.ValidateState(block, statedb, receipts, usedGas)
+		tracing.End(vspan, err)
+		if err != nil {
 			bc.reportBlock(block, receipts, err)
 			followupInterrupt.Store(true)
 			return it.index, err
@@ -1837,12 +1888,14 @@ func (bc *BlockChain) insertChain(chain types.Blocks, setHead bool) (int, error)
 			wstart = time.Now()
 			status WriteStatus
 		)
+		_, wspan := tracing.Start(ctx, spanTracer, "core.commitBlock", blockAttributes(block)...)
 		if !setHead {
 			// Don't set the head, only insert the block
 			err = bc.writeBlockWithState(block, receipts, statedb)
 		} else {
 			status, err = bc.writeBlockAndSetHead(block, receipts, logs, statedb, false)
 		}
+		tracing.End(wspan, err)
 		followupInterrupt.Store(true)
 		if err != nil {
 			return it.index, err
@@ -1921,6 +1974,15 @@ func (bc *BlockChain) insertChain(chain types.Blocks, setHead bool) (int, error)
 	return it.index, err
 }
 
+// blockAttributes returns the span attributes identifying a block.
+func blockAttributes(block *types.Block) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.Int64("block.number", block.Number().Int64()),
+		attribute.String("block.hash", block.Hash().Hex()),
+		attribute.Int("block.txs", len(block.Transactions())),
+	}
+}
+
 // insertSideChain is called when an import batch hits upon a pruned ancestor
 // error, which happens when a sidechain with a sufficiently old fork-block is
 // found.
@@ -2228,6 +2290,23 @@ func (bc *BlockChain) reorg(oldHead *types.Header, newHead *types.Block) error {
 	// stale lookups are still cached.
 	bc.txLookupCache.Purge()
 
+	// Drop the address and log index entries of the old chain before the new
+	// chain is written, entries of the same block number but different
+	// transactions would otherwise be mixed up.
+	if bc.addrSigner != nil || bc.cacheConfig.LogIndex {
+		batch := bc.db.NewBatch()
+		for _, block := range oldChain {
+			if bc.addrSigner != nil {
+				rawdb.DeleteAddressTxEntriesByBlock(batch, block, bc.addrSigner)
+			}
+			if bc.cacheConfig.LogIndex {
+				rawdb.DeleteLogIndexEntries(batch, block.NumberU64(), rawdb.ReadRawReceipts(bc.db, block.Hash(), block.NumberU64()))
+			}
+		}
+		if err := batch.Write(); err != nil {
+			log.Crit("Failed to delete stale address and log indexes", "err", err)
+		}
+	}
 	// Insert the new chain(except the head block(reverse order)),
 	// taking care of the proper incremental order.
 	for i := len(newChain) - 1; i >= 1; i-- {
@@ -377,6 +377,11 @@ func (bc *BlockChain) Processor() Processor {
 	return bc.processor
 }
 
+// Prefetcher returns the current prefetcher.
+func (bc *BlockChain) Prefetcher() Prefetcher {
+	return bc.prefetcher
+}
+
 // StateCache returns the caching database underpinning the blockchain instance.
 func (bc *BlockChain) StateCache() state.Database {
 	return bc.stateCache
@@ -405,6 +410,30 @@ func (bc *BlockChain) TxIndexProgress() (TxIndexProgress, error) {
 	return bc.txIndexer.txIndexProgress()
 }
 
+// GetAddressTransactions retrieves the positions of the canonical transactions
+// sent by or to the given address, starting at the given block number and
+// transaction index up to and including block to. At most limit entries are
+// returned. Blocks below the address index tail are skipped.
+func (bc *BlockChain) GetAddressTransactions(address common.Address, from uint64, index uint32, to uint64, limit int) ([]rawdb.AddressTxEntry, error) {
+	if bc.addrSigner == nil {
+		return nil, errors.New("address index is not enabled")
+	}
+	tail := rawdb.ReadAddrIndexTail(bc.db)
+	if tail == nil {
+		return nil, errors.New("address index is being initialized")
+	}
+	if from < *tail {
+		from, index = *tail, 0
+	}
+	return rawdb.ReadAddressTxEntries(bc.db, address, from, index, to, limit), nil
+}
+
+// HistoryTail returns the number of the first block whose body and receipts are
+// available, all older ones were pruned from the database.
+func (bc *BlockChain) HistoryTail() uint64 {
+	return HistoryTail(bc.db)
+}
+
 // TrieDB retrieves the low level trie database used for data storage.
 func (bc *BlockChain) TrieDB() *triedb.Database {
 	return bc.triedb
@@ -17,6 +17,7 @@
 package core
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"math/big"
@@ -4326,3 +4327,254 @@ func TestEIP3651(t *testing.T) {
 		t.Fatalf("sender balance incorrect: expected %d, got %d", expected, actual)
 	}
 }
+
+// TestEIP7702 deploys two delegation designations and calls into the delegated
+// code of one account, which in turn calls into the delegated code of the other.
+func TestEIP7702(t *testing.T) {
+	var (
+		aa     = common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+		bb     = common.HexToAddress("0x000000000000000000000000000000000000bbbb")
+		engine = beacon.NewFaker()
+
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		key2, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		addr2   = crypto.PubkeyToAddress(key2.PublicKey)
+		funds   = new(big.Int).Mul(common.Big1, big.NewInt(params.Ether))
+		config  = *params.AllEthashProtocolChanges
+		gspec   = &Genesis{
+			Config: &config,
+			Alloc: types.GenesisAlloc{
+				addr1: {Balance: funds},
+				addr2: {Balance: funds},
+				// The address 0xAAAA calls into addr2
+				aa: {
+					Code: append(append([]byte{
+						byte(vm.PUSH1), 0, // out size
+						byte(vm.DUP1),   // out offset
+						byte(vm.DUP1),   // in size
+						byte(vm.DUP1),   // in offset
+						byte(vm.DUP1),   // value
+						byte(vm.PUSH20), // address
+					}, addr2.Bytes()...),
+						byte(vm.GAS), // gas
+						byte(vm.CALL),
+					),
+					Nonce:   0,
+					Balance: big.NewInt(0),
+				},
+				// The address 0xBBBB stores 0x42 into slot 0x42
+				bb: {
+					Code: []byte{
+						byte(vm.PUSH1), 0x42,
+						byte(vm.DUP1),
+						byte(vm.SSTORE),
+					},
+					Nonce:   0,
+					Balance: big.NewInt(0),
+				},
+			},
+		}
+	)
+	gspec.Config.BerlinBlock = common.Big0
+	gspec.Config.LondonBlock = common.Big0
+	gspec.Config.TerminalTotalDifficulty = common.Big0
+	gspec.Config.TerminalTotalDifficultyPassed = true
+	gspec.Config.ShanghaiTime = u64(0)
+	gspec.Config.CancunTime = u64(0)
+	gspec.Config.PragueTime = u64(0)
+	signer := types.LatestSigner(gspec.Config)
+
+	// Sign the authorizations, such that
+	//  1. the tx calls addr1, which is delegated to 0xAAAA
+	//  2. addr1:0xAAAA calls into addr2, which is delegated to 0xBBBB
+	//  3. addr2:0xBBBB writes to its storage
+	// The first authorization is signed by the sender of the tx, whose nonce
+	// is incremented before the authorizations are applied.
+	auth1, _ := types.SignSetCode(key1, types.SetCodeAuthorization{
+		ChainID: *uint256.MustFromBig(gspec.Config.ChainID),
+		Address: aa,
+		Nonce:   1,
+	})
+	auth2, _ := types.SignSetCode(key2, types.SetCodeAuthorization{
+		Address: bb,
+		Nonce:   0,
+	})
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 1, func(i int, b *BlockGen) {
+		b.SetCoinbase(aa)
+		txdata := &types.SetCodeTx{
+			ChainID:   uint256.MustFromBig(gspec.Config.ChainID),
+			Nonce:     0,
+			To:        addr1,
+			Gas:       500000,
+			GasFeeCap: uint256.MustFromBig(newGwei(5)),
+			GasTipCap: uint256.NewInt(2),
+			AuthList:  []types.SetCodeAuthorization{auth1, auth2},
+		}
+		b.AddTxWithChain(chain, types.MustSignNewTx(key1, signer, txdata))
+	})
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	// Verify the delegation designations were deployed
+	state, _ := chain.State()
+	if code, want := state.GetCode(addr1), types.AddressToDelegation(aa); !bytes.Equal(code, want) {
+		t.Fatalf("addr1 code incorrect: got %x, want %x", code, want)
+	}
+	if code, want := state.GetCode(addr2), types.AddressToDelegation(bb); !bytes.Equal(code, want) {
+		t.Fatalf("addr2 code incorrect: got %x, want %x", code, want)
+	}
+	if nonce := state.GetNonce(addr1); nonce != 2 {
+		t.Fatalf("addr1 nonce incorrect: got %d, want 2", nonce)
+	}
+	// Verify the delegated code was executed in the context of addr2
+	slot := common.BytesToHash([]byte{0x42})
+	if have := state.GetState(addr2, slot); have != slot {
+		t.Fatalf("addr2 storage incorrect: got %x, want %x", have, slot)
+	}
+}
+
+// TestSponsoredTransaction tests that the gas of a sponsored transaction is paid
+// by its paymaster if the paymaster approves it, and that the transaction is
+// rejected otherwise.
+func TestSponsoredTransaction(t *testing.T) {
+	var (
+		approve = common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+		reject  = common.HexToAddress("0x000000000000000000000000000000000000bbbb")
+		dest    = common.HexToAddress("0x000000000000000000000000000000000000cccc")
+		engine  = beacon.NewFaker()
+
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		funds  = new(big.Int).Mul(common.Big1, big.NewInt(params.Ether))
+		config = *params.AllEthashProtocolChanges
+		gspec  = &Genesis{
+			Config: &config,
+			Alloc: types.GenesisAlloc{
+				// The sender holds no funds at all
+				addr: {Balance: big.NewInt(0)},
+				// The paymaster 0xAAAA returns true
+				approve: {
+					Code: []byte{
+						byte(vm.PUSH1), 1,
+						byte(vm.PUSH1), 0,
+						byte(vm.MSTORE),
+						byte(vm.PUSH1), 32,
+						byte(vm.PUSH1), 0,
+						byte(vm.RETURN),
+					},
+					Balance: funds,
+				},
+				// The paymaster 0xBBBB returns false
+				reject: {
+					Code: []byte{
+						byte(vm.PUSH1), 32,
+						byte(vm.PUSH1), 0,
+						byte(vm.RETURN),
+					},
+					Balance: funds,
+				},
+			},
+		}
+	)
+	gspec.Config.BerlinBlock = common.Big0
+	gspec.Config.LondonBlock = common.Big0
+	gspec.Config.TerminalTotalDifficulty = common.Big0
+	gspec.Config.TerminalTotalDifficultyPassed = true
+	gspec.Config.ShanghaiTime = u64(0)
+	gspec.Config.CancunTime = u64(0)
+	gspec.Config.PragueTime = u64(0)
+	gspec.Config.PaymasterTime = u64(0)
+	signer := types.LatestSigner(gspec.Config)
+
+	newTx := func(nonce uint64, paymaster common.Address, value *big.Int) *types.Transaction {
+		return types.MustSignNewTx(key, signer, &types.SponsoredTx{
+			ChainID:   gspec.Config.ChainID,
+			Nonce:     nonce,
+			To:        &dest,
+			Gas:       200000,
+			GasFeeCap: newGwei(5),
+			GasTipCap: big.NewInt(2),
+			Value:     value,
+			Paymaster: paymaster,
+		})
+	}
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	_, blocks, receipts := GenerateChainWithGenesis(gspec, engine, 1, func(i int, b *BlockGen) {
+		b.AddTxWithChain(chain, newTx(0, approve, big.NewInt(0)))
+	})
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	receipt := receipts[0][0]
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("sponsored transaction failed")
+	}
+	// The paymaster paid for the gas, the sender paid nothing
+	state, _ := chain.State()
+	if balance := state.GetBalance(addr); !balance.IsZero() {
+		t.Fatalf("sender balance incorrect: have %v, want 0", balance)
+	}
+	cost := new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice)
+	if have, want := state.GetBalance(approve).ToBig(), new(big.Int).Sub(funds, cost); have.Cmp(want) != 0 {
+		t.Fatalf("paymaster balance incorrect: have %v, want %v", have, want)
+	}
+	if nonce := state.GetNonce(addr); nonce != 1 {
+		t.Fatalf("sender nonce incorrect: have %d, want 1", nonce)
+	}
+	// The rejecting paymaster makes the transaction invalid
+	header := chain.CurrentBlock()
+	msg, err := TransactionToMessage(newTx(1, reject, big.NewInt(0)), signer, header.BaseFee)
+	if err != nil {
+		t.Fatalf("failed to create message: %v", err)
+	}
+	blockContext := NewEVMBlockContext(header, chain, nil)
+	evm := vm.NewEVM(blockContext, NewEVMTxContext(msg), state, gspec.Config, vm.Config{})
+	if _, err := ApplyMessage(evm, msg, new(GasPool).AddGas(header.GasLimit)); !errors.Is(err, ErrPaymasterRejected) {
+		t.Fatalf("rejected sponsorship error mismatch: have %v, want %v", err, ErrPaymasterRejected)
+	}
+	// A value the sender can't pay makes the transaction invalid, without the
+	// paymaster being charged
+	state, _ = chain.State()
+	msg, err = TransactionToMessage(newTx(1, approve, big.NewInt(1)), signer, header.BaseFee)
+	if err != nil {
+		t.Fatalf("failed to create message: %v", err)
+	}
+	paymasterBalance := state.GetBalance(approve)
+	evm = vm.NewEVM(blockContext, NewEVMTxContext(msg), state, gspec.Config, vm.Config{})
+	if _, err := ApplyMessage(evm, msg, new(GasPool).AddGas(header.GasLimit)); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("unfunded transfer error mismatch: have %v, want %v", err, ErrInsufficientFunds)
+	}
+	if have := state.GetBalance(approve); have.Cmp(paymasterBalance) != 0 {
+		t.Fatalf("paymaster charged for an invalid transfer: have %v, want %v", have, paymasterBalance)
+	}
+	// The paymaster validation is traced as a system call, ahead of the
+	// transaction which runs no code of its own
+	state, _ = chain.State()
+	msg, err = TransactionToMessage(newTx(1, approve, big.NewInt(0)), signer, header.BaseFee)
+	if err != nil {
+		t.Fatalf("failed to create message: %v", err)
+	}
+	tracer := logger.NewStructLogger(nil)
+	evm = vm.NewEVM(blockContext, NewEVMTxContext(msg), state, gspec.Config, vm.Config{Tracer: tracer})
+	if _, err := ApplyMessage(evm, msg, new(GasPool).AddGas(header.GasLimit)); err != nil {
+		t.Fatalf("failed to apply sponsored transaction: %v", err)
+	}
+	if logs := tracer.StructLogs(); len(logs) != 6 || logs[5].Op != vm.RETURN {
+		t.Fatalf("paymaster validation not traced: have %d steps, want %d", len(logs), 6)
+	}
+	if output := tracer.Output(); len(output) != 0 {
+		t.Fatalf("transaction output mismatch: have %x, want none", output)
+	}
+}
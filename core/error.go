@@ -113,4 +113,29 @@ var (
 
 	// ErrBlobTxCreate is returned if a blob transaction has no explicit to field.
 	ErrBlobTxCreate = errors.New("blob transaction of type create")
+
+	// ErrBlobTxsDisabled is returned if a blob transaction is sent to a chain
+	// past the blob transactions removal fork.
+	ErrBlobTxsDisabled = errors.New("blob transactions are disabled on this chain")
+
+	// ErrPaymasterRejected is returned if the paymaster of a sponsored transaction
+	// doesn't agree to pay for its gas.
+	ErrPaymasterRejected = errors.New("paymaster rejected transaction")
+
+	// ErrEmptyAuthList is returned if a set code transaction has no authorizations.
+	ErrEmptyAuthList = errors.New("EIP-7702 transaction with empty auth list")
+
+	// ErrSetCodeTxCreate is returned if a set code transaction has no explicit to field.
+	ErrSetCodeTxCreate = errors.New("EIP-7702 transaction cannot be used to create contract")
+)
+
+// EIP-7702 state transition errors.
+// Note these are just informational, and do not cause tx execution abort.
+var (
+	ErrAuthorizationWrongChainID       = errors.New("EIP-7702 authorization chain ID mismatch")
+	ErrAuthorizationNonceOverflow      = errors.New("EIP-7702 authorization nonce > 64 bit")
+	ErrAuthorizationInvalidSignature   = errors.New("EIP-7702 authorization has invalid signature")
+	ErrAuthorizationDestinationHasCode = errors.New("EIP-7702 authorization destination is a contract")
+	ErrAuthorizationNonceMismatch      = errors.New("EIP-7702 authorization nonce does not match current account nonce")
+	ErrAuthorizationBlacklisted        = errors.New("EIP-7702 authorization authority is in blacklist")
 )
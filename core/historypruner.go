@@ -0,0 +1,133 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// HistoryTail returns the number of the first block whose body and receipts are
+// retained in the database. Blocks below it were pruned, only their headers and
+// transaction lookup entries are still available.
+func HistoryTail(db ethdb.AncientReader) uint64 {
+	tail, err := db.Tail()
+	if err != nil {
+		return 0 // ancient store not supported, nothing pruned
+	}
+	return tail
+}
+
+// PruneHistory drops the bodies and receipts of all blocks except for the most
+// recent retain ones from the ancient store. Headers, canonical hashes and the
+// transaction indexes are kept intact. Only frozen data is pruned, blocks still
+// residing in the key-value store are unaffected. The new history tail, i.e.
+// the first block whose history is retained, is returned.
+func PruneHistory(db ethdb.Database, head uint64, retain uint64) (uint64, error) {
+	tail := HistoryTail(db)
+	if retain == 0 || head < retain {
+		return tail, nil
+	}
+	target := head - retain + 1
+	frozen, err := db.Ancients()
+	if err != nil {
+		return tail, err
+	}
+	if target > frozen {
+		target = frozen
+	}
+	if target <= tail {
+		return tail, nil
+	}
+	if _, err := db.TruncateTail(target); err != nil {
+		return tail, err
+	}
+	return target, nil
+}
+
+// historyPruner is the module responsible for dropping the block bodies and
+// receipts which fell out of the configured retention window.
+type historyPruner struct {
+	// retain is the number of blocks from head whose bodies and receipts are
+	// reserved, all older ones are pruned once they are moved into the
+	// ancient store.
+	retain uint64
+	db     ethdb.Database
+	term   chan chan struct{}
+	closed chan struct{}
+}
+
+// newHistoryPruner initializes the chain history pruner.
+func newHistoryPruner(retain uint64, chain *BlockChain) *historyPruner {
+	pruner := &historyPruner{
+		retain: retain,
+		db:     chain.db,
+		term:   make(chan chan struct{}),
+		closed: make(chan struct{}),
+	}
+	go pruner.loop(chain)
+
+	log.Info("Initialized chain history pruner", "retain", retain, "tail", HistoryTail(chain.db))
+	return pruner
+}
+
+// prune advances the history tail according to the given chain head.
+func (pruner *historyPruner) prune(head uint64) {
+	old := HistoryTail(pruner.db)
+	tail, err := PruneHistory(pruner.db, head, pruner.retain)
+	if err != nil {
+		log.Error("Failed to prune chain history", "head", head, "err", err)
+		return
+	}
+	if tail != old {
+		log.Debug("Pruned chain history", "head", head, "from", old, "tail", tail)
+	}
+}
+
+// loop prunes the chain history whenever a new chain head is announced.
+func (pruner *historyPruner) loop(chain *BlockChain) {
+	defer close(pruner.closed)
+
+	var (
+		headCh = make(chan ChainHeadEvent, 1)
+		sub    = chain.SubscribeChainHeadEvent(headCh)
+	)
+	defer sub.Unsubscribe()
+
+	if head := chain.CurrentBlock(); head != nil {
+		pruner.prune(head.Number.Uint64())
+	}
+	for {
+		select {
+		case head := <-headCh:
+			pruner.prune(head.Block.NumberU64())
+		case ch := <-pruner.term:
+			close(ch)
+			return
+		}
+	}
+}
+
+// close shuts down the pruner. Safe to be called for multiple times.
+func (pruner *historyPruner) close() {
+	ch := make(chan struct{})
+	select {
+	case pruner.term <- ch:
+		<-ch
+	case <-pruner.closed:
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestPruneHistory(t *testing.T) {
+	var (
+		gspec = &Genesis{
+			Config:  params.TestChainConfig,
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		height = uint64(128)
+		frozen = uint64(100)
+	)
+	_, blocks, receipts := GenerateChainWithGenesis(gspec, ethash.NewFaker(), int(height), nil)
+
+	db, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), t.TempDir(), "", false)
+	if err != nil {
+		t.Fatalf("failed to create temp freezer db: %v", err)
+	}
+	defer db.Close()
+
+	chain, err := NewBlockChain(db, DefaultCacheConfigWithScheme(rawdb.HashScheme), gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	headers := make([]*types.Header, len(blocks))
+	for i, block := range blocks {
+		headers[i] = block.Header()
+	}
+	if n, err := chain.InsertHeaderChain(headers); err != nil {
+		t.Fatalf("failed to insert header %d: %v", n, err)
+	}
+	if n, err := chain.InsertReceiptChain(blocks, receipts, frozen); err != nil {
+		t.Fatalf("failed to insert receipt %d: %v", n, err)
+	}
+	// Nothing is pruned if the retention window covers the entire chain
+	if tail, err := PruneHistory(db, height, height+1); err != nil || tail != 0 {
+		t.Fatalf("unexpected pruning: tail %d, err %v", tail, err)
+	}
+	// Only frozen blocks are pruned, even if the window is smaller
+	tail, err := PruneHistory(db, height, 10)
+	if err != nil {
+		t.Fatalf("failed to prune history: %v", err)
+	}
+	if want := frozen + 1; tail != want {
+		t.Fatalf("history tail mismatch: have %d, want %d", tail, want)
+	}
+	if have := chain.HistoryTail(); have != tail {
+		t.Fatalf("chain history tail mismatch: have %d, want %d", have, tail)
+	}
+	for _, block := range blocks {
+		var (
+			number = block.NumberU64()
+			hash   = block.Hash()
+		)
+		if rawdb.ReadHeader(db, hash, number) == nil {
+			t.Fatalf("header #%d pruned", number)
+		}
+		body, rcpts := rawdb.ReadBody(db, hash, number), rawdb.ReadReceipts(db, hash, number, block.Time(), params.TestChainConfig)
+		if number < tail && (body != nil || rcpts != nil) {
+			t.Fatalf("block #%d not pruned", number)
+		}
+		if number >= tail && (body == nil || rcpts == nil) {
+			t.Fatalf("block #%d history missing", number)
+		}
+	}
+	// The genesis block lives in the key-value store and is never pruned
+	if chain.GetBlockByNumber(0) == nil {
+		t.Fatal("genesis block pruned")
+	}
+	// Pruning is idempotent
+	if again, err := PruneHistory(db, height, 10); err != nil || again != tail {
+		t.Fatalf("repeated pruning mismatch: tail %d, err %v", again, err)
+	}
+}
@@ -0,0 +1,494 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+var (
+	parallelSpeculatedMeter = metrics.NewRegisteredMeter("chain/parallel/speculated", nil)
+	parallelReexecutedMeter = metrics.NewRegisteredMeter("chain/parallel/reexecuted", nil)
+)
+
+// ParallelStateProcessor is a Processor which executes the transactions of a
+// block speculatively in parallel, each on its own copy of the state before the
+// transactions, and records the state read and written by every transaction.
+// The results are then committed in block order. A transaction which read state
+// written by an earlier transaction of the block is executed again on the
+// actual state, so the outcome is identical to sequential execution.
+//
+// ParallelStateProcessor implements Processor.
+type ParallelStateProcessor struct {
+	*StateProcessor
+	workers int // Number of goroutines executing transactions speculatively
+}
+
+// NewParallelStateProcessor initialises a new ParallelStateProcessor.
+func NewParallelStateProcessor(config *params.ChainConfig, bc *BlockChain, engine consensus.Engine, workers int) *ParallelStateProcessor {
+	return &ParallelStateProcessor{
+		StateProcessor: NewStateProcessor(config, bc, engine),
+		workers:        workers,
+	}
+}
+
+// Process processes the state changes according to the Ethereum rules, the same
+// way as StateProcessor.Process does, but executes the transactions in parallel.
+func (p *ParallelStateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg vm.Config) (types.Receipts, []*types.Log, uint64, error) {
+	// Speculation relies on per-transaction finalisation of the state and can't
+	// feed a tracer in order, fall back to sequential execution otherwise.
+	if p.workers < 2 || len(block.Transactions()) < 2 || cfg.Tracer != nil || !p.config.IsByzantium(block.Number()) {
+		return p.StateProcessor.Process(block, statedb, cfg)
+	}
+	var (
+		receipts    types.Receipts
+		usedGas     = new(uint64)
+		header      = block.Header()
+		blockHash   = block.Hash()
+		blockNumber = block.Number()
+		allLogs     []*types.Log
+		gp          = new(GasPool).AddGas(block.GasLimit())
+		context     = NewEVMBlockContext(header, p.bc, nil)
+		vmenv       = vm.NewEVM(context, vm.TxContext{}, statedb, p.config, cfg)
+		signer      = types.MakeSigner(p.config, header.Number, header.Time)
+	)
+	if beaconRoot := block.BeaconRoot(); beaconRoot != nil {
+		ProcessBeaconBlockRoot(*beaconRoot, vmenv, statedb)
+	}
+	if p.config.IsHistoryStorage(blockNumber, block.Time()) {
+		ProcessParentBlockHash(block.ParentHash(), vmenv, statedb)
+	}
+	msgs := make([]*Message, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		msg, err := TransactionToMessage(tx, signer, header.BaseFee)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+		}
+		msgs[i] = msg
+	}
+	specs := p.speculate(block, statedb, msgs, cfg)
+
+	// Commit the transactions in block order, executing again the ones which
+	// conflict with the transactions committed before them
+	written := newStateAccess()
+	for i, tx := range block.Transactions() {
+		statedb.SetTxContext(tx.Hash(), i)
+
+		spec := specs[i]
+		if spec.valid() && gp.Gas() >= msgs[i].GasLimit && !written.conflicts(spec.access) {
+			spec.apply(statedb)
+			if err := gp.SubGas(spec.result.UsedGas); err != nil {
+				return nil, nil, 0, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+			}
+			written.merge(spec.access)
+		} else {
+			tracker := newStateTracker(statedb)
+			vmenv.Reset(NewEVMTxContext(msgs[i]), tracker)
+
+			result, err := ApplyMessage(vmenv, msgs[i], gp)
+			if err != nil {
+				return nil, nil, 0, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+			}
+			spec = &speculation{result: result}
+			written.merge(tracker.access)
+			parallelReexecutedMeter.Mark(1)
+		}
+		statedb.Finalise(true)
+		*usedGas += spec.result.UsedGas
+
+		vmenv.Reset(NewEVMTxContext(msgs[i]), statedb)
+		receipt := makeReceipt(vmenv, spec.result, statedb, blockNumber, blockHash, tx, *usedGas, nil)
+		receipts = append(receipts, receipt)
+		allLogs = append(allLogs, receipt.Logs...)
+	}
+	parallelSpeculatedMeter.Mark(int64(len(specs)))
+
+	// Fail if Shanghai not enabled and len(withdrawals) is non-zero.
+	withdrawals := block.Withdrawals()
+	if len(withdrawals) > 0 && !p.config.IsShanghai(block.Number(), block.Time()) {
+		return nil, nil, 0, errors.New("withdrawals before shanghai")
+	}
+	// Finalize the block, applying any consensus engine specific extras (e.g. block rewards)
+	if err := p.engine.Finalize(consensus.WithTrace(p.bc, cfg.TraceContext), header, statedb, block.Transactions(), block.Uncles(), withdrawals); err != nil {
+		return nil, nil, 0, err
+	}
+	return receipts, allLogs, *usedGas, nil
+}
+
+// speculate executes the messages of a block in parallel, each on the given
+// state, which is left unmodified.
+func (p *ParallelStateProcessor) speculate(block *types.Block, statedb *state.StateDB, msgs []*Message, cfg vm.Config) []*speculation {
+	var (
+		specs   = make([]*speculation, len(msgs))
+		next    atomic.Int64
+		pend    sync.WaitGroup
+		workers = min(p.workers, len(msgs))
+	)
+	for w := 0; w < workers; w++ {
+		// Copy the state before any worker starts, the copies are then owned by
+		// the workers
+		statedb := statedb.Copy()
+
+		pend.Add(1)
+		go func() {
+			defer pend.Done()
+
+			var (
+				header  = block.Header()
+				context = NewEVMBlockContext(header, p.bc, nil)
+				vmenv   = vm.NewEVM(context, vm.TxContext{}, statedb, p.config, cfg)
+			)
+			for i := int(next.Add(1) - 1); i < len(msgs); i = int(next.Add(1) - 1) {
+				specs[i] = speculateMessage(vmenv, statedb, block.Transactions()[i], i, msgs[i], header.GasLimit)
+			}
+		}()
+	}
+	pend.Wait()
+	return specs
+}
+
+// speculation is the outcome of executing a transaction on the state before the
+// transactions of the block.
+type speculation struct {
+	result *ExecutionResult
+	err    error
+	access *stateAccess // State read and written by the transaction
+
+	balances  map[common.Address]*uint256.Int // Balance change of the accounts credited or debited
+	negative  map[common.Address]bool         // Whether the balance change is a debit
+	nonces    map[common.Address]uint64
+	codes     map[common.Address][]byte
+	storage   map[common.Address]map[common.Hash]common.Hash
+	logs      []*types.Log
+	preimages map[common.Hash][]byte
+}
+
+// speculateMessage executes a message on the state and collects its effects,
+// then reverts the state.
+func speculateMessage(evm *vm.EVM, statedb *state.StateDB, tx *types.Transaction, index int, msg *Message, gasLimit uint64) *speculation {
+	snapshot := statedb.Snapshot()
+	defer statedb.RevertToSnapshot(snapshot)
+
+	statedb.SetTxContext(tx.Hash(), index)
+	tracker := newStateTracker(statedb)
+	evm.Reset(NewEVMTxContext(msg), tracker)
+
+	spec := &speculation{access: tracker.access}
+	spec.result, spec.err = ApplyMessage(evm, msg, new(GasPool).AddGas(gasLimit))
+	if !spec.valid() {
+		return spec
+	}
+	// Collect the final values of the written state before reverting it
+	spec.balances = make(map[common.Address]*uint256.Int)
+	spec.negative = make(map[common.Address]bool)
+	for addr, prev := range tracker.balances {
+		balance := statedb.GetBalance(addr)
+		if balance.Cmp(prev) < 0 {
+			spec.balances[addr] = new(uint256.Int).Sub(prev, balance)
+			spec.negative[addr] = true
+		} else {
+			spec.balances[addr] = new(uint256.Int).Sub(balance, prev)
+		}
+	}
+	spec.nonces = make(map[common.Address]uint64)
+	spec.codes = make(map[common.Address][]byte)
+	spec.storage = make(map[common.Address]map[common.Hash]common.Hash)
+	for key := range tracker.access.writes {
+		switch key.kind {
+		case nonceKey:
+			spec.nonces[key.addr] = statedb.GetNonce(key.addr)
+		case codeKey:
+			spec.codes[key.addr] = statedb.GetCode(key.addr)
+		case storageKey:
+			if spec.storage[key.addr] == nil {
+				spec.storage[key.addr] = make(map[common.Hash]common.Hash)
+			}
+			spec.storage[key.addr][key.slot] = statedb.GetState(key.addr, key.slot)
+		}
+	}
+	spec.logs = statedb.GetLogs(tx.Hash(), 0, common.Hash{})
+	if preimages := statedb.Preimages(); len(preimages) > 0 {
+		spec.preimages = make(map[common.Hash][]byte, len(preimages))
+		for hash, preimage := range preimages {
+			spec.preimages[hash] = preimage
+		}
+	}
+	return spec
+}
+
+// valid reports whether the speculative execution succeeded and its effects can
+// be applied to another state.
+func (spec *speculation) valid() bool {
+	return spec.err == nil && !spec.access.unsupported
+}
+
+// apply applies the effects of the speculative execution to the state.
+func (spec *speculation) apply(statedb *state.StateDB) {
+	for addr, change := range spec.balances {
+		// Apply the change even if zero, to touch the account
+		if spec.negative[addr] {
+			statedb.SubBalance(addr, change)
+		} else {
+			statedb.AddBalance(addr, change)
+		}
+	}
+	for addr, nonce := range spec.nonces {
+		statedb.SetNonce(addr, nonce)
+	}
+	for addr, code := range spec.codes {
+		statedb.SetCode(addr, code)
+	}
+	for addr, slots := range spec.storage {
+		for slot, value := range slots {
+			statedb.SetState(addr, slot, value)
+		}
+	}
+	for _, log := range spec.logs {
+		statedb.AddLog(&types.Log{
+			Address: log.Address,
+			Topics:  log.Topics,
+			Data:    log.Data,
+		})
+	}
+	for hash, preimage := range spec.preimages {
+		statedb.AddPreimage(hash, preimage)
+	}
+}
+
+// stateKeyKind is the kind of state item a stateKey refers to.
+type stateKeyKind uint8
+
+const (
+	balanceKey stateKeyKind = iota
+	nonceKey
+	codeKey
+	storageKey
+)
+
+// stateKey identifies an item of the state: a field of an account or a storage
+// slot.
+type stateKey struct {
+	kind stateKeyKind
+	addr common.Address
+	slot common.Hash
+}
+
+// stateAccess is the set of state items read and written by a transaction.
+type stateAccess struct {
+	reads  map[stateKey]struct{}
+	writes map[stateKey]struct{}
+	wiped  map[common.Address]struct{} // Accounts whose whole storage was reset
+
+	// unsupported is set if the transaction created or destroyed accounts,
+	// whose effects are not collected by speculative execution.
+	unsupported bool
+}
+
+func newStateAccess() *stateAccess {
+	return &stateAccess{
+		reads:  make(map[stateKey]struct{}),
+		writes: make(map[stateKey]struct{}),
+		wiped:  make(map[common.Address]struct{}),
+	}
+}
+
+// conflicts reports whether any item read by the other access was written by
+// this one.
+func (a *stateAccess) conflicts(other *stateAccess) bool {
+	for key := range other.reads {
+		if _, ok := a.writes[key]; ok {
+			return true
+		}
+		if _, ok := a.wiped[key.addr]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// merge adds the items written by the other access to this one.
+func (a *stateAccess) merge(other *stateAccess) {
+	for key := range other.writes {
+		a.writes[key] = struct{}{}
+	}
+	for addr := range other.wiped {
+		a.wiped[addr] = struct{}{}
+	}
+}
+
+// stateTracker wraps a StateDB and records the state accessed through it.
+type stateTracker struct {
+	*state.StateDB
+	access *stateAccess
+
+	balances map[common.Address]*uint256.Int // Balances before the first change
+	written  map[common.Address]struct{}     // Accounts changed by the transaction
+}
+
+func newStateTracker(statedb *state.StateDB) *stateTracker {
+	return &stateTracker{
+		StateDB:  statedb,
+		access:   newStateAccess(),
+		balances: make(map[common.Address]*uint256.Int),
+		written:  make(map[common.Address]struct{}),
+	}
+}
+
+func (t *stateTracker) read(kind stateKeyKind, addr common.Address, slot common.Hash) {
+	t.access.reads[stateKey{kind: kind, addr: addr, slot: slot}] = struct{}{}
+}
+
+func (t *stateTracker) readAccount(addr common.Address) {
+	t.read(balanceKey, addr, common.Hash{})
+	t.read(nonceKey, addr, common.Hash{})
+	t.read(codeKey, addr, common.Hash{})
+}
+
+func (t *stateTracker) write(kind stateKeyKind, addr common.Address, slot common.Hash) {
+	// The changes to an account empty before the transaction can't be applied
+	// faithfully, as whether the account is deleted depends on whether the
+	// changes were reverted.
+	if _, ok := t.written[addr]; !ok {
+		if t.StateDB.Exist(addr) && t.StateDB.Empty(addr) {
+			t.access.unsupported = true
+		}
+		t.written[addr] = struct{}{}
+	}
+	t.access.writes[stateKey{kind: kind, addr: addr, slot: slot}] = struct{}{}
+}
+
+func (t *stateTracker) writeBalance(addr common.Address) {
+	if _, ok := t.balances[addr]; !ok {
+		t.balances[addr] = new(uint256.Int).Set(t.StateDB.GetBalance(addr))
+	}
+	t.write(balanceKey, addr, common.Hash{})
+}
+
+func (t *stateTracker) wipe(addr common.Address) {
+	t.writeBalance(addr)
+	t.write(nonceKey, addr, common.Hash{})
+	t.write(codeKey, addr, common.Hash{})
+	t.access.wiped[addr] = struct{}{}
+	t.access.unsupported = true
+}
+
+func (t *stateTracker) CreateAccount(addr common.Address) {
+	t.readAccount(addr)
+	if t.StateDB.Exist(addr) {
+		t.wipe(addr)
+	} else {
+		t.writeBalance(addr)
+		t.write(nonceKey, addr, common.Hash{})
+		t.write(codeKey, addr, common.Hash{})
+	}
+	t.StateDB.CreateAccount(addr)
+}
+
+func (t *stateTracker) SubBalance(addr common.Address, amount *uint256.Int) {
+	t.writeBalance(addr)
+	t.StateDB.SubBalance(addr, amount)
+}
+
+func (t *stateTracker) AddBalance(addr common.Address, amount *uint256.Int) {
+	t.writeBalance(addr)
+	t.StateDB.AddBalance(addr, amount)
+}
+
+func (t *stateTracker) GetBalance(addr common.Address) *uint256.Int {
+	t.read(balanceKey, addr, common.Hash{})
+	return t.StateDB.GetBalance(addr)
+}
+
+func (t *stateTracker) GetNonce(addr common.Address) uint64 {
+	t.read(nonceKey, addr, common.Hash{})
+	return t.StateDB.GetNonce(addr)
+}
+
+func (t *stateTracker) SetNonce(addr common.Address, nonce uint64) {
+	t.write(nonceKey, addr, common.Hash{})
+	t.StateDB.SetNonce(addr, nonce)
+}
+
+func (t *stateTracker) GetCodeHash(addr common.Address) common.Hash {
+	t.read(codeKey, addr, common.Hash{})
+	return t.StateDB.GetCodeHash(addr)
+}
+
+func (t *stateTracker) GetCode(addr common.Address) []byte {
+	t.read(codeKey, addr, common.Hash{})
+	return t.StateDB.GetCode(addr)
+}
+
+func (t *stateTracker) SetCode(addr common.Address, code []byte) {
+	t.write(codeKey, addr, common.Hash{})
+	t.StateDB.SetCode(addr, code)
+}
+
+func (t *stateTracker) GetCodeSize(addr common.Address) int {
+	t.read(codeKey, addr, common.Hash{})
+	return t.StateDB.GetCodeSize(addr)
+}
+
+func (t *stateTracker) GetCommittedState(addr common.Address, slot common.Hash) common.Hash {
+	t.read(storageKey, addr, slot)
+	return t.StateDB.GetCommittedState(addr, slot)
+}
+
+func (t *stateTracker) GetState(addr common.Address, slot common.Hash) common.Hash {
+	t.read(storageKey, addr, slot)
+	return t.StateDB.GetState(addr, slot)
+}
+
+func (t *stateTracker) SetState(addr common.Address, slot common.Hash, value common.Hash) {
+	t.write(storageKey, addr, slot)
+	t.StateDB.SetState(addr, slot, value)
+}
+
+func (t *stateTracker) SelfDestruct(addr common.Address) {
+	t.read(balanceKey, addr, common.Hash{})
+	t.wipe(addr)
+	t.StateDB.SelfDestruct(addr)
+}
+
+func (t *stateTracker) Selfdestruct6780(addr common.Address) {
+	t.StateDB.Selfdestruct6780(addr)
+	if t.StateDB.HasSelfDestructed(addr) {
+		t.wipe(addr)
+	}
+}
+
+func (t *stateTracker) Exist(addr common.Address) bool {
+	t.readAccount(addr)
+	return t.StateDB.Exist(addr)
+}
+
+func (t *stateTracker) Empty(addr common.Address) bool {
+	t.readAccount(addr)
+	return t.StateDB.Empty(addr)
+}
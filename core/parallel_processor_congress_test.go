@@ -0,0 +1,233 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// The Congress engine depends on package core, so the test chain running it
+// lives in the external test package.
+package core_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/congress"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/triedb"
+)
+
+var (
+	// congressValidatorsContract stands in for the validators system contract,
+	// recording the block reward it receives in the slot of the block number.
+	congressValidatorsContract = common.HexToAddress("0x000000000000000000000000000000000000f000")
+
+	// congressPunishContract stands in for the punish system contract, recording
+	// the punished validator in the slot of the block number.
+	congressPunishContract = common.HexToAddress("0x000000000000000000000000000000000000f001")
+
+	// congressProposalContract stands in for the proposal system contract,
+	// answering 3 to any query (receiver address and increase period).
+	congressProposalContract = common.HexToAddress("0x000000000000000000000000000000000000f002")
+
+	// congressCounter increments a single storage slot, every call conflicts
+	// with the previous ones.
+	congressCounter = common.HexToAddress("0x000000000000000000000000000000000000c0c0")
+)
+
+// TestParallelStateProcessorCongress is the differential test of the parallel
+// processor against the sequential one on a Congress chain, where every
+// transaction pays its fee to the fee recorder, and the engine distributes it
+// and punishes the validators signing out-of-turn with system calls when
+// finalizing the blocks.
+func TestParallelStateProcessorCongress(t *testing.T) {
+	var (
+		validators = make([]*ecdsa.PrivateKey, 3)
+		senders    = make([]*ecdsa.PrivateKey, 8)
+		addrs      = make([]common.Address, len(senders))
+		alloc      = types.GenesisAlloc{
+			congressValidatorsContract: {Code: []byte{byte(vm.CALLVALUE), byte(vm.NUMBER), byte(vm.SSTORE)}},
+			congressPunishContract:     {Code: []byte{byte(vm.PUSH1), 4, byte(vm.CALLDATALOAD), byte(vm.NUMBER), byte(vm.SSTORE)}},
+			congressProposalContract: {Code: []byte{
+				byte(vm.PUSH1), 3, byte(vm.PUSH1), 0, byte(vm.MSTORE), byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN),
+			}},
+			congressCounter: {Code: []byte{
+				byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.PUSH1), 1, byte(vm.ADD), byte(vm.PUSH1), 0, byte(vm.SSTORE),
+			}},
+		}
+	)
+	for i := range validators {
+		validators[i], _ = crypto.GenerateKey()
+	}
+	sort.Slice(validators, func(i, j int) bool {
+		return bytes.Compare(crypto.PubkeyToAddress(validators[i].PublicKey).Bytes(), crypto.PubkeyToAddress(validators[j].PublicKey).Bytes()) < 0
+	})
+	extra := make([]byte, 32)
+	for _, key := range validators {
+		extra = append(extra, crypto.PubkeyToAddress(key.PublicKey).Bytes()...)
+	}
+	extra = append(extra, make([]byte, crypto.SignatureLength)...)
+
+	for i := range senders {
+		senders[i], _ = crypto.GenerateKey()
+		addrs[i] = crypto.PubkeyToAddress(senders[i].PublicKey)
+		alloc[addrs[i]] = types.Account{Balance: new(big.Int).Mul(big.NewInt(params.Ether), big.NewInt(1000))}
+	}
+	// Like the main network, the chain runs without the Berlin and London forks
+	config := *params.TestChainConfig
+	config.BerlinBlock, config.LondonBlock = nil, nil
+	config.ArrowGlacierBlock, config.GrayGlacierBlock = nil, nil
+	config.Ethash = nil
+	config.Congress = &params.CongressConfig{Period: 1, Epoch: 1000}
+
+	var (
+		gspec  = &core.Genesis{Config: &config, ExtraData: extra, GasLimit: 30_000_000, Alloc: alloc}
+		signer = types.LatestSigner(gspec.Config)
+		rng    = rand.New(rand.NewSource(1))
+
+		db     = rawdb.NewMemoryDatabase()
+		engine = congress.New(gspec.Config, db)
+	)
+	chain, err := core.NewBlockChain(db, nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+	engine.SetStateFn(chain.StateAt)
+
+	var (
+		gendb      = rawdb.NewMemoryDatabase()
+		parent     = gspec.MustCommit(gendb, triedb.NewDatabase(gendb, triedb.HashDefaults))
+		sequential = core.NewStateProcessor(gspec.Config, chain, engine)
+		parallel   = core.NewParallelStateProcessor(gspec.Config, chain, engine, 4)
+	)
+	for number := uint64(1); number <= 16; number++ {
+		// Rotate the signers so that some blocks are signed out-of-turn, without
+		// any validator signing twice in a row
+		key := validators[(number+number/4)%uint64(len(validators))]
+		difficulty := big.NewInt(1)
+		if key == validators[number%uint64(len(validators))] {
+			difficulty = big.NewInt(2)
+		}
+		blocks, _ := core.GenerateChain(gspec.Config, parent, engine, gendb, 1, func(_ int, b *core.BlockGen) {
+			b.SetCoinbase(crypto.PubkeyToAddress(key.PublicKey))
+			b.SetDifficulty(difficulty)
+			b.SetExtra(make([]byte, 32+crypto.SignatureLength))
+
+			// Leave some blocks empty, skipping the block reward distribution
+			if number%5 == 0 {
+				return
+			}
+			for j := 0; j < 16; j++ {
+				var (
+					from  = senders[rng.Intn(len(senders))]
+					to    = &addrs[rng.Intn(len(addrs))]
+					value = new(big.Int)
+					data  []byte
+				)
+				switch rng.Intn(5) {
+				case 0: // value transfer between senders
+					value.SetUint64(uint64(rng.Intn(1000)))
+				case 1: // value transfer to the validator of the block
+					coinbase := crypto.PubkeyToAddress(key.PublicKey)
+					to, value = &coinbase, big.NewInt(1)
+				case 2: // value transfer to the fee recorder
+					to, value = &consensus.FeeRecorder, big.NewInt(1)
+				case 3:
+					to = &congressCounter
+				case 4:
+					to, data = nil, []byte{byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.RETURN)}
+				}
+				tx := types.MustSignNewTx(from, signer, &types.LegacyTx{
+					Nonce:    b.TxNonce(crypto.PubkeyToAddress(from.PublicKey)),
+					GasPrice: big.NewInt(int64(1 + rng.Intn(params.GWei))),
+					Gas:      100000,
+					To:       to,
+					Value:    value,
+					Data:     data,
+				})
+				b.AddTxWithChain(chain, tx)
+			}
+		})
+		header := blocks[0].Header()
+		sig, err := crypto.Sign(congress.SealHash(header).Bytes(), key)
+		if err != nil {
+			t.Fatalf("block %d: failed to sign: %v", number, err)
+		}
+		copy(header.Extra[len(header.Extra)-crypto.SignatureLength:], sig)
+		block := blocks[0].WithSeal(header)
+
+		head := chain.CurrentBlock()
+		seqState, _ := chain.StateAt(head.Root)
+		seqReceipts, seqLogs, seqGas, err := sequential.Process(block, seqState, vm.Config{})
+		if err != nil {
+			t.Fatalf("block %d: sequential processing failed: %v", number, err)
+		}
+		parState, _ := chain.StateAt(head.Root)
+		parReceipts, parLogs, parGas, err := parallel.Process(block, parState, vm.Config{})
+		if err != nil {
+			t.Fatalf("block %d: parallel processing failed: %v", number, err)
+		}
+		if seqGas != parGas {
+			t.Fatalf("block %d: gas used mismatch: have %d, want %d", number, parGas, seqGas)
+		}
+		if have, want := parState.IntermediateRoot(true), seqState.IntermediateRoot(true); have != want {
+			t.Fatalf("block %d: state root mismatch: have %x, want %x", number, have, want)
+		}
+		have, _ := json.Marshal(parReceipts)
+		want, _ := json.Marshal(seqReceipts)
+		if string(have) != string(want) {
+			t.Fatalf("block %d: receipts mismatch:\nhave %s\nwant %s", number, have, want)
+		}
+		have, _ = json.Marshal(parLogs)
+		want, _ = json.Marshal(seqLogs)
+		if string(have) != string(want) {
+			t.Fatalf("block %d: logs mismatch:\nhave %s\nwant %s", number, have, want)
+		}
+		// The fees must have been handed over to the validators contract
+		if fee := parState.GetBalance(consensus.FeeRecorder); !fee.IsZero() {
+			t.Fatalf("block %d: fee recorder not emptied: %v", number, fee)
+		}
+		if _, err := chain.InsertChain(types.Blocks{block}); err != nil {
+			t.Fatalf("block %d: failed to insert into chain: %v", number, err)
+		}
+		parent = block
+	}
+	// Make sure the system calls were exercised by the chain
+	state, _ := chain.State()
+	var rewarded, punished bool
+	for number := uint64(1); number <= 16; number++ {
+		slot := common.BigToHash(new(big.Int).SetUint64(number))
+		if state.GetState(congressValidatorsContract, slot) != (common.Hash{}) {
+			rewarded = true
+		}
+		if state.GetState(congressPunishContract, slot) != (common.Hash{}) {
+			punished = true
+		}
+	}
+	if !rewarded || !punished {
+		t.Fatalf("system calls not exercised: rewarded %v, punished %v", rewarded, punished)
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	// parallelCounter increments a single storage slot, every call conflicts
+	// with the previous ones.
+	parallelCounter = common.HexToAddress("0x000000000000000000000000000000000000c0c0")
+
+	// parallelToken moves a unit from the slot of the caller to the slot of the
+	// address in the call data and logs the transfer, calls conflict only if
+	// they share an account.
+	parallelToken = common.HexToAddress("0x000000000000000000000000000000000000c0c1")
+
+	// parallelToucher calls the address in the call data without value, then
+	// reverts if the second word of the call data is non-zero.
+	parallelToucher = common.HexToAddress("0x000000000000000000000000000000000000c0c2")
+
+	// parallelDestructor sends its balance to the caller and self-destructs.
+	parallelDestructor = common.HexToAddress("0x000000000000000000000000000000000000c0c3")
+
+	// parallelEmpty is an existing empty account.
+	parallelEmpty = common.HexToAddress("0x000000000000000000000000000000000000e0e0")
+)
+
+// parallelCreatorCode is init code deploying a contract logging its caller.
+var parallelCreatorCode = []byte{
+	byte(vm.CALLER), byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.LOG1),
+	byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.RETURN),
+}
+
+// newParallelTestChain generates a chain of blocks filled with transactions of
+// the given senders, with some of them conflicting with each other.
+func newParallelTestChain(t *testing.T, blocks int) (*Genesis, []*types.Block) {
+	var (
+		keys  = make([]*ecdsa.PrivateKey, 8)
+		addrs = make([]common.Address, len(keys))
+		alloc = types.GenesisAlloc{
+			parallelCounter: {Code: []byte{
+				byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.PUSH1), 1, byte(vm.ADD), byte(vm.PUSH1), 0, byte(vm.SSTORE),
+			}},
+			parallelToken: {Code: []byte{
+				byte(vm.PUSH1), 1, byte(vm.CALLER), byte(vm.SLOAD), byte(vm.SUB), byte(vm.CALLER), byte(vm.SSTORE),
+				byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.DUP1), byte(vm.SLOAD), byte(vm.PUSH1), 1, byte(vm.ADD), byte(vm.SWAP1), byte(vm.SSTORE),
+				byte(vm.CALLER), byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.LOG1),
+			}},
+			parallelToucher: {Code: []byte{
+				byte(vm.PUSH1), 0, byte(vm.DUP1), byte(vm.DUP1), byte(vm.DUP1), byte(vm.DUP1),
+				byte(vm.PUSH1), 0, byte(vm.CALLDATALOAD), byte(vm.GAS), byte(vm.CALL), byte(vm.POP),
+				byte(vm.PUSH1), 32, byte(vm.CALLDATALOAD), byte(vm.PUSH1), 20, byte(vm.JUMPI), byte(vm.STOP),
+				byte(vm.JUMPDEST), byte(vm.PUSH1), 0, byte(vm.DUP1), byte(vm.REVERT),
+			}},
+			parallelDestructor: {Code: []byte{byte(vm.CALLER), byte(vm.SELFDESTRUCT)}, Balance: big.NewInt(1000)},
+			parallelEmpty:      {Balance: new(big.Int)},
+		}
+	)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		addrs[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+		alloc[addrs[i]] = types.Account{Balance: new(big.Int).Mul(big.NewInt(params.Ether), big.NewInt(1000))}
+	}
+	var (
+		rng    = rand.New(rand.NewSource(1))
+		engine = beacon.NewFaker()
+		gspec  = &Genesis{Config: params.MergedTestChainConfig, Alloc: alloc}
+		signer = types.LatestSigner(gspec.Config)
+	)
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create generator chain: %v", err)
+	}
+	defer chain.Stop()
+
+	_, generated, _ := GenerateChainWithGenesis(gspec, engine, blocks, func(n int, b *BlockGen) {
+		for j := 0; j < 24; j++ {
+			var (
+				key  = keys[rng.Intn(len(keys))]
+				to   = &addrs[rng.Intn(len(addrs))]
+				data []byte
+			)
+			value := new(big.Int)
+			switch rng.Intn(8) {
+			case 0: // value transfer between senders
+				value.SetUint64(uint64(rng.Intn(1000)))
+			case 1: // value transfer to a new account
+				to, value = new(common.Address), big.NewInt(1)
+				rng.Read(to[:])
+			case 2:
+				to = &parallelCounter
+			case 3, 4:
+				to, data = &parallelToken, common.LeftPadBytes(addrs[rng.Intn(len(addrs))].Bytes(), 32)
+			case 5:
+				target := parallelEmpty
+				if rng.Intn(2) == 0 {
+					target = common.BigToAddress(big.NewInt(int64(rng.Intn(10))))
+				}
+				to, data = &parallelToucher, append(common.LeftPadBytes(target.Bytes(), 32), common.LeftPadBytes([]byte{byte(rng.Intn(2))}, 32)...)
+			case 6:
+				to, value = &parallelDestructor, big.NewInt(1)
+			case 7:
+				to, data = nil, parallelCreatorCode
+			}
+			tx := types.MustSignNewTx(key, signer, &types.DynamicFeeTx{
+				ChainID:   gspec.Config.ChainID,
+				Nonce:     b.TxNonce(crypto.PubkeyToAddress(key.PublicKey)),
+				GasTipCap: big.NewInt(1),
+				GasFeeCap: newGwei(10),
+				Gas:       200000,
+				To:        to,
+				Value:     value,
+				Data:      data,
+			})
+			b.AddTxWithChain(chain, tx)
+		}
+	})
+	return gspec, generated
+}
+
+// TestParallelStateProcessor is a differential test of the parallel processor
+// against the sequential one: every block must produce the same state, receipts
+// and logs.
+func TestParallelStateProcessor(t *testing.T) {
+	gspec, blocks := newParallelTestChain(t, 16)
+
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, beacon.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	var (
+		sequential = NewStateProcessor(gspec.Config, chain, chain.engine)
+		parallel   = NewParallelStateProcessor(gspec.Config, chain, chain.engine, 4)
+	)
+	for _, block := range blocks {
+		parent := chain.CurrentBlock()
+
+		seqState, _ := chain.StateAt(parent.Root)
+		seqReceipts, seqLogs, seqGas, err := sequential.Process(block, seqState, vm.Config{})
+		if err != nil {
+			t.Fatalf("block %d: sequential processing failed: %v", block.NumberU64(), err)
+		}
+		parState, _ := chain.StateAt(parent.Root)
+		parReceipts, parLogs, parGas, err := parallel.Process(block, parState, vm.Config{})
+		if err != nil {
+			t.Fatalf("block %d: parallel processing failed: %v", block.NumberU64(), err)
+		}
+		if seqGas != parGas {
+			t.Fatalf("block %d: gas used mismatch: have %d, want %d", block.NumberU64(), parGas, seqGas)
+		}
+		if have, want := parState.IntermediateRoot(true), seqState.IntermediateRoot(true); have != want {
+			t.Fatalf("block %d: state root mismatch: have %x, want %x", block.NumberU64(), have, want)
+		}
+		have, _ := json.Marshal(parReceipts)
+		want, _ := json.Marshal(seqReceipts)
+		if string(have) != string(want) {
+			t.Fatalf("block %d: receipts mismatch:\nhave %s\nwant %s", block.NumberU64(), have, want)
+		}
+		have, _ = json.Marshal(parLogs)
+		want, _ = json.Marshal(seqLogs)
+		if string(have) != string(want) {
+			t.Fatalf("block %d: logs mismatch:\nhave %s\nwant %s", block.NumberU64(), have, want)
+		}
+		if _, err := chain.InsertChain(types.Blocks{block}); err != nil {
+			t.Fatalf("block %d: failed to insert into chain: %v", block.NumberU64(), err)
+		}
+	}
+}
+
+// TestParallelBlockChain tests that a chain configured to execute transactions
+// in parallel imports blocks.
+func TestParallelBlockChain(t *testing.T) {
+	gspec, blocks := newParallelTestChain(t, 8)
+
+	config := *defaultCacheConfig
+	config.ParallelWorkers = 4
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), &config, gspec, nil, beacon.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, ok := chain.Processor().(*ParallelStateProcessor); !ok {
+		t.Fatalf("wrong processor: have %T, want *ParallelStateProcessor", chain.Processor())
+	}
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// validatePaymasterSelector is the selector of the paymaster contract method
+//
+//	function validatePaymaster(address sender, address to, uint256 maxCost, bytes data) returns (bool)
+//
+// called before a sponsored transaction is executed. The paymaster pays for the
+// gas of the transaction if the method returns true. The call is made from the
+// system address, so that the paymaster can tell it apart from regular calls.
+var validatePaymasterSelector = crypto.Keccak256([]byte("validatePaymaster(address,address,uint256,bytes)"))[:4]
+
+// ValidatePaymaster calls the paymaster of a sponsored message to check that it
+// agrees to pay for the gas of the message. The call may use at most the given
+// gas, the gas left is returned.
+func ValidatePaymaster(evm *vm.EVM, msg *Message, gas uint64) (uint64, error) {
+	// The call is traced as a system call by the tracers supporting them, the
+	// others expect a single top level call per transaction.
+	if tracer, ok := evm.Config.Tracer.(vm.SystemCallLogger); ok {
+		tracer.CaptureSystemCallStart()
+		defer tracer.CaptureSystemCallEnd()
+	} else if tracer := evm.Config.Tracer; tracer != nil {
+		evm.Config.Tracer = nil
+		defer func() { evm.Config.Tracer = tracer }()
+	}
+	ret, left, err := evm.Call(vm.AccountRef(params.SystemAddress), *msg.Paymaster, packValidatePaymaster(msg), gas, common.U2560)
+	if err != nil {
+		return left, fmt.Errorf("%w: paymaster %v: %v", ErrPaymasterRejected, msg.Paymaster.Hex(), err)
+	}
+	if len(ret) != 32 || new(big.Int).SetBytes(ret).Cmp(common.Big1) != 0 {
+		return left, fmt.Errorf("%w: paymaster %v", ErrPaymasterRejected, msg.Paymaster.Hex())
+	}
+	return left, nil
+}
+
+// packValidatePaymaster ABI-encodes the validatePaymaster call of a message.
+func packValidatePaymaster(msg *Message) []byte {
+	var to common.Address
+	if msg.To != nil {
+		to = *msg.To
+	}
+	price := msg.GasFeeCap
+	if price == nil {
+		price = msg.GasPrice
+	}
+	maxCost := new(big.Int).Mul(new(big.Int).SetUint64(msg.GasLimit), price)
+
+	input := make([]byte, 0, 4+5*32+len(msg.Data)+31)
+	input = append(input, validatePaymasterSelector...)
+	input = append(input, common.LeftPadBytes(msg.From.Bytes(), 32)...)
+	input = append(input, common.LeftPadBytes(to.Bytes(), 32)...)
+	input = append(input, math.U256Bytes(maxCost)...)
+	input = append(input, math.U256Bytes(big.NewInt(4*32))...)
+	input = append(input, math.U256Bytes(big.NewInt(int64(len(msg.Data))))...)
+	input = append(input, common.RightPadBytes(msg.Data, (len(msg.Data)+31)/32*32)...)
+	return input
+}
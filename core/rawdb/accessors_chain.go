@@ -278,6 +278,61 @@ func WriteTxIndexTail(db ethdb.KeyValueWriter, number uint64) {
 	}
 }
 
+// ReadAddrIndexTail retrieves the number of oldest block whose transactions
+// have been indexed by their participating addresses, or nil if nothing has
+// been indexed yet.
+func ReadAddrIndexTail(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(addrIndexTailKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteAddrIndexTail stores the number of oldest block indexed by participating
+// addresses into database.
+func WriteAddrIndexTail(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(addrIndexTailKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store the address index tail", "err", err)
+	}
+}
+
+// ReadLogIndexTail retrieves the number of oldest block whose logs have been
+// indexed, or nil if nothing has been indexed yet.
+func ReadLogIndexTail(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(logIndexTailKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteLogIndexTail stores the number of oldest block whose logs have been
+// indexed into database.
+func WriteLogIndexTail(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(logIndexTailKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store the log index tail", "err", err)
+	}
+}
+
+// DeleteLogIndexTail removes the log index tail flag, marking the log index as
+// not maintained anymore.
+func DeleteLogIndexTail(db ethdb.KeyValueWriter) {
+	if err := db.Delete(logIndexTailKey); err != nil {
+		log.Crit("Failed to delete the log index tail", "err", err)
+	}
+}
+
+// DeleteAddrIndexTail removes the address index tail flag, marking the address
+// index as not maintained anymore.
+func DeleteAddrIndexTail(db ethdb.KeyValueWriter) {
+	if err := db.Delete(addrIndexTailKey); err != nil {
+		log.Crit("Failed to delete the address index tail", "err", err)
+	}
+}
+
 // ReadHeaderRange returns the rlp-encoded headers, starting at 'number', and going
 // backwards towards genesis. This method assumes that the caller already has
 // placed a cap on count, to prevent DoS issues.
@@ -433,9 +488,11 @@ func ReadBodyRLP(db ethdb.Reader, hash common.Hash, number uint64) rlp.RawValue
 		// Check if the data is in ancients
 		if isCanon(reader, number, hash) {
 			data, _ = reader.Ancient(ChainFreezerBodiesTable, number)
-			return nil
+			if len(data) > 0 {
+				return nil
+			}
 		}
-		// If not, try reading from leveldb
+		// If not (or pruned from ancients), try reading from leveldb
 		data, _ = db.Get(blockBodyKey(number, hash))
 		return nil
 	})
@@ -576,9 +633,11 @@ func ReadReceiptsRLP(db ethdb.Reader, hash common.Hash, number uint64) rlp.RawVa
 		// Check if the data is in ancients
 		if isCanon(reader, number, hash) {
 			data, _ = reader.Ancient(ChainFreezerReceiptTable, number)
-			return nil
+			if len(data) > 0 {
+				return nil
+			}
 		}
-		// If not, try reading from leveldb
+		// If not (or pruned from ancients), try reading from leveldb
 		data, _ = db.Get(blockReceiptsKey(number, hash))
 		return nil
 	})
@@ -18,10 +18,12 @@ package rawdb
 
 import (
 	"bytes"
+	"encoding/binary"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
@@ -93,6 +95,111 @@ func DeleteTxLookupEntries(db ethdb.KeyValueWriter, hashes []common.Hash) {
 	}
 }
 
+// AddressTxEntry is the position of a transaction in the canonical chain, as
+// indexed by the addresses participating in it.
+type AddressTxEntry struct {
+	BlockNumber uint64
+	Index       uint32
+	Hash        common.Hash
+}
+
+// txParticipants returns the addresses participating in a transaction, namely
+// its sender and its recipient or the contract it creates.
+func txParticipants(signer types.Signer, tx *types.Transaction) []common.Address {
+	var participants []common.Address
+	from, err := types.Sender(signer, tx)
+	if err == nil {
+		participants = append(participants, from)
+	}
+	if to := tx.To(); to != nil {
+		if err != nil || *to != from {
+			participants = append(participants, *to)
+		}
+	} else if err == nil {
+		participants = append(participants, crypto.CreateAddress(from, tx.Nonce()))
+	}
+	return participants
+}
+
+// WriteAddressTxEntries stores the address index entries of the transactions
+// in a block, given the participants of each transaction.
+func WriteAddressTxEntries(db ethdb.KeyValueWriter, number uint64, hashes []common.Hash, participants [][]common.Address) {
+	for i, addrs := range participants {
+		for _, addr := range addrs {
+			if err := db.Put(addressTxKey(addr, number, uint32(i)), hashes[i].Bytes()); err != nil {
+				log.Crit("Failed to store address index entry", "err", err)
+			}
+		}
+	}
+}
+
+// WriteAddressTxEntriesByBlock stores the address index entries of every
+// transaction in a block.
+func WriteAddressTxEntriesByBlock(db ethdb.KeyValueWriter, block *types.Block, signer types.Signer) {
+	var (
+		txs          = block.Transactions()
+		hashes       = make([]common.Hash, len(txs))
+		participants = make([][]common.Address, len(txs))
+	)
+	for i, tx := range txs {
+		hashes[i], participants[i] = tx.Hash(), txParticipants(signer, tx)
+	}
+	WriteAddressTxEntries(db, block.NumberU64(), hashes, participants)
+}
+
+// DeleteAddressTxEntries removes the address index entries of the transactions
+// in a block, given the participants of each transaction.
+func DeleteAddressTxEntries(db ethdb.KeyValueWriter, number uint64, participants [][]common.Address) {
+	for i, addrs := range participants {
+		for _, addr := range addrs {
+			if err := db.Delete(addressTxKey(addr, number, uint32(i))); err != nil {
+				log.Crit("Failed to delete address index entry", "err", err)
+			}
+		}
+	}
+}
+
+// DeleteAddressTxEntriesByBlock removes the address index entries of every
+// transaction in a block.
+func DeleteAddressTxEntriesByBlock(db ethdb.KeyValueWriter, block *types.Block, signer types.Signer) {
+	txs := block.Transactions()
+	participants := make([][]common.Address, len(txs))
+	for i, tx := range txs {
+		participants[i] = txParticipants(signer, tx)
+	}
+	DeleteAddressTxEntries(db, block.NumberU64(), participants)
+}
+
+// ReadAddressTxEntries retrieves at most limit index entries of the transactions
+// the given address participated in, in chain order. The iteration starts at
+// the given block number and transaction index, and stops after block to.
+func ReadAddressTxEntries(db ethdb.Iteratee, address common.Address, from uint64, index uint32, to uint64, limit int) []AddressTxEntry {
+	var (
+		prefix  = append(append([]byte{}, addressTxPrefix...), address.Bytes()...)
+		start   = addressTxKey(address, from, index)[len(prefix):]
+		it      = db.NewIterator(prefix, start)
+		entries []AddressTxEntry
+	)
+	defer it.Release()
+
+	for len(entries) < limit && it.Next() {
+		key := it.Key()
+		if len(key) != len(prefix)+12 || len(it.Value()) != common.HashLength {
+			continue
+		}
+		number := binary.BigEndian.Uint64(key[len(prefix):])
+		if number > to {
+			break
+		}
+		entries = append(entries, AddressTxEntry{
+			BlockNumber: number,
+			Index:       binary.BigEndian.Uint32(key[len(prefix)+8:]),
+			Hash:        common.BytesToHash(it.Value()),
+		})
+	}
+	return entries
+}
+
 // ReadTransaction retrieves a specific transaction from the database, along with
 // its added positional metadata.
 func ReadTransaction(db ethdb.Reader, hash common.Hash) (*types.Transaction, common.Hash, uint64, uint64) {
@@ -179,3 +286,113 @@ func DeleteBloombits(db ethdb.Database, bit uint, from uint64, to uint64) {
 		log.Crit("Failed to delete bloom bits", "err", it.Error())
 	}
 }
+
+// DeleteBloomIndex removes all compressed bloom bits vectors along with the
+// progress of the bloombits indexer.
+func DeleteBloomIndex(db ethdb.Database) {
+	for _, prefix := range [][]byte{bloomBitsPrefix, BloomBitsIndexPrefix} {
+		it := db.NewIterator(prefix, nil)
+		batch := db.NewBatch()
+		for it.Next() {
+			if bytes.Equal(prefix, bloomBitsPrefix) && len(it.Key()) != len(bloomBitsPrefix)+2+8+32 {
+				continue
+			}
+			batch.Delete(it.Key())
+			if batch.ValueSize() > ethdb.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					log.Crit("Failed to delete bloom index", "err", err)
+				}
+				batch.Reset()
+			}
+		}
+		if it.Error() != nil {
+			log.Crit("Failed to delete bloom index", "err", it.Error())
+		}
+		it.Release()
+		if err := batch.Write(); err != nil {
+			log.Crit("Failed to delete bloom index", "err", err)
+		}
+	}
+}
+
+// logIndexKeys returns the log index keys of a block with the given receipts,
+// one for every distinct emitting address and positional topic.
+func logIndexKeys(number uint64, receipts types.Receipts) [][]byte {
+	var (
+		keys [][]byte
+		seen = make(map[string]struct{})
+	)
+	add := func(key []byte) {
+		if _, ok := seen[string(key)]; !ok {
+			seen[string(key)] = struct{}{}
+			keys = append(keys, key)
+		}
+	}
+	for _, receipt := range receipts {
+		for _, l := range receipt.Logs {
+			add(logAddressKey(l.Address, number))
+			for i, topic := range l.Topics {
+				add(logTopicKey(i, topic, number))
+			}
+		}
+	}
+	return keys
+}
+
+// WriteLogIndexEntries stores the log index entries of a block, marking the
+// addresses and positional topics of all the logs emitted in it.
+func WriteLogIndexEntries(db ethdb.KeyValueWriter, number uint64, receipts types.Receipts) {
+	for _, key := range logIndexKeys(number, receipts) {
+		if err := db.Put(key, nil); err != nil {
+			log.Crit("Failed to store log index entry", "err", err)
+		}
+	}
+}
+
+// DeleteLogIndexEntries removes the log index entries of a block.
+func DeleteLogIndexEntries(db ethdb.KeyValueWriter, number uint64, receipts types.Receipts) {
+	for _, key := range logIndexKeys(number, receipts) {
+		if err := db.Delete(key); err != nil {
+			log.Crit("Failed to delete log index entry", "err", err)
+		}
+	}
+}
+
+// readLogIndexBlocks iterates the log index entries with the given prefix and
+// returns the block numbers within the range [from, to] in ascending order.
+func readLogIndexBlocks(db ethdb.Iteratee, prefix []byte, from, to uint64) []uint64 {
+	var (
+		start   = make([]byte, 8)
+		numbers []uint64
+	)
+	binary.BigEndian.PutUint64(start, from)
+
+	it := db.NewIterator(prefix, start)
+	defer it.Release()
+
+	for it.Next() {
+		if len(it.Key()) != len(prefix)+8 {
+			continue
+		}
+		number := binary.BigEndian.Uint64(it.Key()[len(prefix):])
+		if number > to {
+			break
+		}
+		numbers = append(numbers, number)
+	}
+	return numbers
+}
+
+// ReadLogAddressBlocks returns the numbers of the blocks within the range
+// [from, to] which contain logs emitted by the given address.
+func ReadLogAddressBlocks(db ethdb.Iteratee, address common.Address, from, to uint64) []uint64 {
+	prefix := logAddressKey(address, 0)
+	return readLogIndexBlocks(db, prefix[:len(prefix)-8], from, to)
+}
+
+// ReadLogTopicBlocks returns the numbers of the blocks within the range
+// [from, to] which contain logs with the given topic at the given position.
+func ReadLogTopicBlocks(db ethdb.Iteratee, position int, topic common.Hash, from, to uint64) []uint64 {
+	prefix := logTopicKey(position, topic, 0)
+	return readLogIndexBlocks(db, prefix[:len(prefix)-8], from, to)
+}
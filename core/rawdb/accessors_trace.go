@@ -0,0 +1,95 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ReadBlockTraces retrieves the persisted trace results of a block, produced
+// with the tracer configuration identified by the given config hash. The blob
+// is opaque to the database layer and is interpreted by the tracing package.
+func ReadBlockTraces(db ethdb.KeyValueReader, number uint64, hash common.Hash, config common.Hash) []byte {
+	data, _ := db.Get(blockTracesKey(number, hash, config))
+	return data
+}
+
+// HasBlockTraces verifies the existence of persisted trace results of a block
+// for the given tracer configuration.
+func HasBlockTraces(db ethdb.KeyValueReader, number uint64, hash common.Hash, config common.Hash) bool {
+	has, _ := db.Has(blockTracesKey(number, hash, config))
+	return has
+}
+
+// WriteBlockTraces stores the trace results of a block produced with the tracer
+// configuration identified by the given config hash.
+func WriteBlockTraces(db ethdb.KeyValueWriter, number uint64, hash common.Hash, config common.Hash, blob []byte) {
+	if err := db.Put(blockTracesKey(number, hash, config), blob); err != nil {
+		log.Crit("Failed to store block traces", "err", err)
+	}
+}
+
+// DeleteBlockTraces removes the trace results of a block for the given tracer
+// configuration.
+func DeleteBlockTraces(db ethdb.KeyValueWriter, number uint64, hash common.Hash, config common.Hash) {
+	if err := db.Delete(blockTracesKey(number, hash, config)); err != nil {
+		log.Crit("Failed to delete block traces", "err", err)
+	}
+}
+
+// DeleteBlockTracesRange removes all persisted trace results, regardless of the
+// block hash and tracer configuration, of the blocks in the range [from, to).
+// The number of deleted entries is returned.
+func DeleteBlockTracesRange(db ethdb.KeyValueStore, from, to uint64) (int, error) {
+	if from >= to {
+		return 0, nil
+	}
+	var (
+		batch   = db.NewBatch()
+		deleted int
+		it      = db.NewIterator(blockTracesPrefix, encodeBlockNumber(from))
+	)
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(blockTracesPrefix)+8+2*common.HashLength {
+			continue
+		}
+		if binary.BigEndian.Uint64(key[len(blockTracesPrefix):]) >= to {
+			break
+		}
+		if err := batch.Delete(key); err != nil {
+			return deleted, err
+		}
+		deleted++
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return deleted, err
+			}
+			batch.Reset()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return deleted, err
+	}
+	return deleted, batch.Write()
+}
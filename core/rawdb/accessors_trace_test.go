@@ -0,0 +1,67 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Tests block trace storage and range based deletion.
+func TestBlockTracesStorage(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	var (
+		callConfig = common.HexToHash("0x01")
+		flatConfig = common.HexToHash("0x02")
+	)
+	for i := uint64(0); i < 10; i++ {
+		hash := common.Hash{byte(i + 1)}
+		WriteBlockTraces(db, i, hash, callConfig, []byte{byte(i)})
+		WriteBlockTraces(db, i, hash, flatConfig, []byte{byte(i), 0xff})
+	}
+	hash := common.Hash{6}
+	if blob := ReadBlockTraces(db, 5, hash, callConfig); !bytes.Equal(blob, []byte{5}) {
+		t.Fatalf("block traces mismatch: have %x, want %x", blob, []byte{5})
+	}
+	if blob := ReadBlockTraces(db, 5, hash, common.Hash{}); blob != nil {
+		t.Fatalf("unexpected block traces for unknown config: %x", blob)
+	}
+	DeleteBlockTraces(db, 5, hash, callConfig)
+	if HasBlockTraces(db, 5, hash, callConfig) {
+		t.Fatal("deleted block traces still present")
+	}
+	if !HasBlockTraces(db, 5, hash, flatConfig) {
+		t.Fatal("unrelated block traces deleted")
+	}
+	deleted, err := DeleteBlockTracesRange(db, 3, 8)
+	if err != nil {
+		t.Fatalf("failed to delete trace range: %v", err)
+	}
+	if deleted != 9 {
+		t.Fatalf("deleted entry count mismatch: have %d, want %d", deleted, 9)
+	}
+	for i := uint64(0); i < 10; i++ {
+		hash := common.Hash{byte(i + 1)}
+		want := i < 3 || i >= 8
+		if have := HasBlockTraces(db, i, hash, flatConfig); have != want {
+			t.Fatalf("block #%d: trace presence mismatch: have %v, want %v", i, have, want)
+		}
+	}
+}
@@ -84,15 +84,55 @@ func InitDatabaseFromFreezer(db ethdb.Database) {
 }
 
 type blockTxHashes struct {
-	number uint64
-	hashes []common.Hash
+	number       uint64
+	hashes       []common.Hash
+	participants [][]common.Address // Addresses participating in each transaction, if requested
+}
+
+// txIndexKind describes an index derived from the transactions of the canonical
+// chain, which is maintained from a tail block onwards.
+type txIndexKind struct {
+	name      string                                       // Name of the index in log messages
+	signer    types.Signer                                 // Signer deriving the transaction participants, nil if not needed
+	index     func(ethdb.KeyValueWriter, *blockTxHashes)   // Writes the index entries of a block
+	unindex   func(ethdb.KeyValueWriter, *blockTxHashes)   // Deletes the index entries of a block
+	writeTail func(db ethdb.KeyValueWriter, number uint64) // Stores the tail of the index
+}
+
+// txLookupIndex is the transaction hash to block number index.
+var txLookupIndex = &txIndexKind{
+	name: "transactions",
+	index: func(db ethdb.KeyValueWriter, block *blockTxHashes) {
+		WriteTxLookupEntries(db, block.number, block.hashes)
+	},
+	unindex: func(db ethdb.KeyValueWriter, block *blockTxHashes) {
+		DeleteTxLookupEntries(db, block.hashes)
+	},
+	writeTail: WriteTxIndexTail,
+}
+
+// addressIndex returns the participating address to transaction index, using
+// the given signer to derive the senders.
+func addressIndex(signer types.Signer) *txIndexKind {
+	return &txIndexKind{
+		name:   "address transactions",
+		signer: signer,
+		index: func(db ethdb.KeyValueWriter, block *blockTxHashes) {
+			WriteAddressTxEntries(db, block.number, block.hashes, block.participants)
+		},
+		unindex: func(db ethdb.KeyValueWriter, block *blockTxHashes) {
+			DeleteAddressTxEntries(db, block.number, block.participants)
+		},
+		writeTail: WriteAddrIndexTail,
+	}
 }
 
 // iterateTransactions iterates over all transactions in the (canon) block
-// number(s) given, and yields the hashes on a channel. If there is a signal
+// number(s) given, and yields the hashes on a channel. If a signer is given,
+// the participants of the transactions are derived too. If there is a signal
 // received from interrupt channel, the iteration will be aborted and result
 // channel will be closed.
-func iterateTransactions(db ethdb.Database, from uint64, to uint64, reverse bool, interrupt chan struct{}) chan *blockTxHashes {
+func iterateTransactions(db ethdb.Database, from uint64, to uint64, reverse bool, signer types.Signer, interrupt chan struct{}) chan *blockTxHashes {
 	// One thread sequentially reads data from db
 	type numberRlp struct {
 		number uint64
@@ -147,13 +187,20 @@ func iterateTransactions(db ethdb.Database, from uint64, to uint64, reverse bool
 				log.Warn("Failed to decode block body", "block", data.number, "error", err)
 				return
 			}
-			var hashes []common.Hash
+			var (
+				hashes       []common.Hash
+				participants [][]common.Address
+			)
 			for _, tx := range body.Transactions {
 				hashes = append(hashes, tx.Hash())
+				if signer != nil {
+					participants = append(participants, txParticipants(signer, tx))
+				}
 			}
 			result := &blockTxHashes{
-				hashes: hashes,
-				number: data.number,
+				hashes:       hashes,
+				number:       data.number,
+				participants: participants,
 			}
 			// Feed the block to the aggregator, or abort on interrupt
 			select {
@@ -178,13 +225,13 @@ func iterateTransactions(db ethdb.Database, from uint64, to uint64, reverse bool
 //
 // There is a passed channel, the whole procedure will be interrupted if any
 // signal received.
-func indexTransactions(db ethdb.Database, from uint64, to uint64, interrupt chan struct{}, hook func(uint64) bool, report bool) {
+func indexTransactions(db ethdb.Database, kind *txIndexKind, from uint64, to uint64, interrupt chan struct{}, hook func(uint64) bool, report bool) {
 	// short circuit for invalid range
 	if from >= to {
 		return
 	}
 	var (
-		hashesCh = iterateTransactions(db, from, to, true, interrupt)
+		hashesCh = iterateTransactions(db, from, to, true, kind.signer, interrupt)
 		batch    = db.NewBatch()
 		start    = time.Now()
 		logged   = start.Add(-7 * time.Second)
@@ -213,12 +260,12 @@ func indexTransactions(db ethdb.Database, from uint64, to uint64, interrupt chan
 			// Next block available, pop it off and index it
 			delivery := queue.PopItem()
 			lastNum = delivery.number
-			WriteTxLookupEntries(batch, delivery.number, delivery.hashes)
+			kind.index(batch, delivery)
 			blocks++
 			txs += len(delivery.hashes)
 			// If enough data was accumulated in memory or we're at the last block, dump to disk
 			if batch.ValueSize() > ethdb.IdealBatchSize {
-				WriteTxIndexTail(batch, lastNum) // Also write the tail here
+				kind.writeTail(batch, lastNum) // Also write the tail here
 				if err := batch.Write(); err != nil {
 					log.Crit("Failed writing batch to db", "error", err)
 					return
@@ -227,7 +274,7 @@ func indexTransactions(db ethdb.Database, from uint64, to uint64, interrupt chan
 			}
 			// If we've spent too much time already, notify the user of what we're doing
 			if time.Since(logged) > 8*time.Second {
-				log.Info("Indexing transactions", "blocks", blocks, "txs", txs, "tail", lastNum, "total", to-from, "elapsed", common.PrettyDuration(time.Since(start)))
+				log.Info("Indexing "+kind.name, "blocks", blocks, "txs", txs, "tail", lastNum, "total", to-from, "elapsed", common.PrettyDuration(time.Since(start)))
 				logged = time.Now()
 			}
 		}
@@ -235,7 +282,7 @@ func indexTransactions(db ethdb.Database, from uint64, to uint64, interrupt chan
 	// Flush the new indexing tail and the last committed data. It can also happen
 	// that the last batch is empty because nothing to index, but the tail has to
 	// be flushed anyway.
-	WriteTxIndexTail(batch, lastNum)
+	kind.writeTail(batch, lastNum)
 	if err := batch.Write(); err != nil {
 		log.Crit("Failed writing batch to db", "error", err)
 		return
@@ -246,9 +293,9 @@ func indexTransactions(db ethdb.Database, from uint64, to uint64, interrupt chan
 	}
 	select {
 	case <-interrupt:
-		logger("Transaction indexing interrupted", "blocks", blocks, "txs", txs, "tail", lastNum, "elapsed", common.PrettyDuration(time.Since(start)))
+		logger("Indexing "+kind.name+" interrupted", "blocks", blocks, "txs", txs, "tail", lastNum, "elapsed", common.PrettyDuration(time.Since(start)))
 	default:
-		logger("Indexed transactions", "blocks", blocks, "txs", txs, "tail", lastNum, "elapsed", common.PrettyDuration(time.Since(start)))
+		logger("Indexed "+kind.name, "blocks", blocks, "txs", txs, "tail", lastNum, "elapsed", common.PrettyDuration(time.Since(start)))
 	}
 }
 
@@ -262,25 +309,109 @@ func indexTransactions(db ethdb.Database, from uint64, to uint64, interrupt chan
 // There is a passed channel, the whole procedure will be interrupted if any
 // signal received.
 func IndexTransactions(db ethdb.Database, from uint64, to uint64, interrupt chan struct{}, report bool) {
-	indexTransactions(db, from, to, interrupt, nil, report)
+	indexTransactions(db, txLookupIndex, from, to, interrupt, nil, report)
 }
 
 // indexTransactionsForTesting is the internal debug version with an additional hook.
 func indexTransactionsForTesting(db ethdb.Database, from uint64, to uint64, interrupt chan struct{}, hook func(uint64) bool) {
-	indexTransactions(db, from, to, interrupt, hook, false)
+	indexTransactions(db, txLookupIndex, from, to, interrupt, hook, false)
+}
+
+// IndexLogs creates the log index entries of the specified block range. The
+// from is included while to is excluded.
+//
+// Just like IndexTransactions, the canonical chain is iterated in reverse order
+// and the log index tail flag is written periodically, so that the indexing
+// procedure can be resumed quickly next time.
+//
+// There is a passed channel, the whole procedure will be interrupted if any
+// signal received.
+func IndexLogs(db ethdb.Database, from uint64, to uint64, interrupt chan struct{}, report bool) {
+	// short circuit for invalid range
+	if from >= to {
+		return
+	}
+	var (
+		batch  = db.NewBatch()
+		start  = time.Now()
+		logged = start.Add(-7 * time.Second)
+
+		lastNum      = to
+		blocks, logs = 0, 0 // for stats reporting
+	)
+loop:
+	for lastNum > from {
+		select {
+		case <-interrupt:
+			break loop
+		default:
+		}
+		number := lastNum - 1
+		receipts := ReadRawReceipts(db, ReadCanonicalHash(db, number), number)
+		if receipts == nil {
+			log.Warn("Failed to index logs of block, receipts missing", "number", number)
+			break
+		}
+		WriteLogIndexEntries(batch, number, receipts)
+		for _, receipt := range receipts {
+			logs += len(receipt.Logs)
+		}
+		lastNum = number
+		blocks++
+
+		// If enough data was accumulated in memory, dump to disk
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			WriteLogIndexTail(batch, lastNum) // Also write the tail here
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed writing batch to db", "error", err)
+				return
+			}
+			batch.Reset()
+		}
+		// If we've spent too much time already, notify the user of what we're doing
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Indexing logs", "blocks", blocks, "logs", logs, "tail", lastNum, "total", to-from, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	// Flush the new indexing tail and the last committed data.
+	WriteLogIndexTail(batch, lastNum)
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed writing batch to db", "error", err)
+		return
+	}
+	logger := log.Debug
+	if report {
+		logger = log.Info
+	}
+	if lastNum > from {
+		logger("Indexing logs interrupted", "blocks", blocks, "logs", logs, "tail", lastNum, "elapsed", common.PrettyDuration(time.Since(start)))
+	} else {
+		logger("Indexed logs", "blocks", blocks, "logs", logs, "tail", lastNum, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+}
+
+// IndexAddressTransactions creates the participating address to transaction
+// indices of the specified block range. The from is included while to is
+// excluded. The senders of the transactions are recovered with the signer.
+//
+// There is a passed channel, the whole procedure will be interrupted if any
+// signal received.
+func IndexAddressTransactions(db ethdb.Database, signer types.Signer, from uint64, to uint64, interrupt chan struct{}, report bool) {
+	indexTransactions(db, addressIndex(signer), from, to, interrupt, nil, report)
 }
 
 // unindexTransactions removes txlookup indices of the specified block range.
 //
 // There is a passed channel, the whole procedure will be interrupted if any
 // signal received.
-func unindexTransactions(db ethdb.Database, from uint64, to uint64, interrupt chan struct{}, hook func(uint64) bool, report bool) {
+func unindexTransactions(db ethdb.Database, kind *txIndexKind, from uint64, to uint64, interrupt chan struct{}, hook func(uint64) bool, report bool) {
 	// short circuit for invalid range
 	if from >= to {
 		return
 	}
 	var (
-		hashesCh = iterateTransactions(db, from, to, false, interrupt)
+		hashesCh = iterateTransactions(db, from, to, false, kind.signer, interrupt)
 		batch    = db.NewBatch()
 		start    = time.Now()
 		logged   = start.Add(-7 * time.Second)
@@ -306,7 +437,7 @@ func unindexTransactions(db ethdb.Database, from uint64, to uint64, interrupt ch
 			}
 			delivery := queue.PopItem()
 			nextNum = delivery.number + 1
-			DeleteTxLookupEntries(batch, delivery.hashes)
+			kind.unindex(batch, delivery)
 			txs += len(delivery.hashes)
 			blocks++
 
@@ -314,7 +445,7 @@ func unindexTransactions(db ethdb.Database, from uint64, to uint64, interrupt ch
 			// A batch counts the size of deletion as '1', so we need to flush more
 			// often than that.
 			if blocks%1000 == 0 {
-				WriteTxIndexTail(batch, nextNum)
+				kind.writeTail(batch, nextNum)
 				if err := batch.Write(); err != nil {
 					log.Crit("Failed writing batch to db", "error", err)
 					return
@@ -323,7 +454,7 @@ func unindexTransactions(db ethdb.Database, from uint64, to uint64, interrupt ch
 			}
 			// If we've spent too much time already, notify the user of what we're doing
 			if time.Since(logged) > 8*time.Second {
-				log.Info("Unindexing transactions", "blocks", blocks, "txs", txs, "total", to-from, "elapsed", common.PrettyDuration(time.Since(start)))
+				log.Info("Unindexing "+kind.name, "blocks", blocks, "txs", txs, "total", to-from, "elapsed", common.PrettyDuration(time.Since(start)))
 				logged = time.Now()
 			}
 		}
@@ -331,7 +462,7 @@ func unindexTransactions(db ethdb.Database, from uint64, to uint64, interrupt ch
 	// Flush the new indexing tail and the last committed data. It can also happen
 	// that the last batch is empty because nothing to unindex, but the tail has to
 	// be flushed anyway.
-	WriteTxIndexTail(batch, nextNum)
+	kind.writeTail(batch, nextNum)
 	if err := batch.Write(); err != nil {
 		log.Crit("Failed writing batch to db", "error", err)
 		return
@@ -342,9 +473,9 @@ func unindexTransactions(db ethdb.Database, from uint64, to uint64, interrupt ch
 	}
 	select {
 	case <-interrupt:
-		logger("Transaction unindexing interrupted", "blocks", blocks, "txs", txs, "tail", to, "elapsed", common.PrettyDuration(time.Since(start)))
+		logger("Unindexing "+kind.name+" interrupted", "blocks", blocks, "txs", txs, "tail", to, "elapsed", common.PrettyDuration(time.Since(start)))
 	default:
-		logger("Unindexed transactions", "blocks", blocks, "txs", txs, "tail", to, "elapsed", common.PrettyDuration(time.Since(start)))
+		logger("Unindexed "+kind.name, "blocks", blocks, "txs", txs, "tail", to, "elapsed", common.PrettyDuration(time.Since(start)))
 	}
 }
 
@@ -354,10 +485,20 @@ func unindexTransactions(db ethdb.Database, from uint64, to uint64, interrupt ch
 // There is a passed channel, the whole procedure will be interrupted if any
 // signal received.
 func UnindexTransactions(db ethdb.Database, from uint64, to uint64, interrupt chan struct{}, report bool) {
-	unindexTransactions(db, from, to, interrupt, nil, report)
+	unindexTransactions(db, txLookupIndex, from, to, interrupt, nil, report)
 }
 
 // unindexTransactionsForTesting is the internal debug version with an additional hook.
 func unindexTransactionsForTesting(db ethdb.Database, from uint64, to uint64, interrupt chan struct{}, hook func(uint64) bool) {
-	unindexTransactions(db, from, to, interrupt, hook, false)
+	unindexTransactions(db, txLookupIndex, from, to, interrupt, hook, false)
+}
+
+// UnindexAddressTransactions removes the participating address to transaction
+// indices of the specified block range. The from is included while to is
+// excluded. The senders of the transactions are recovered with the signer.
+//
+// There is a passed channel, the whole procedure will be interrupted if any
+// signal received.
+func UnindexAddressTransactions(db ethdb.Database, signer types.Signer, from uint64, to uint64, interrupt chan struct{}, report bool) {
+	unindexTransactions(db, addressIndex(signer), from, to, interrupt, nil, report)
 }
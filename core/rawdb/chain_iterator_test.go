@@ -25,6 +25,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 func TestChainIterator(t *testing.T) {
@@ -80,7 +81,7 @@ func TestChainIterator(t *testing.T) {
 	}
 	for i, c := range cases {
 		var numbers []int
-		hashCh := iterateTransactions(chainDb, c.from, c.to, c.reverse, nil)
+		hashCh := iterateTransactions(chainDb, c.from, c.to, c.reverse, nil, nil)
 		if hashCh != nil {
 			for h := range hashCh {
 				numbers = append(numbers, int(h.number))
@@ -206,3 +207,81 @@ func TestIndexTransactions(t *testing.T) {
 	verify(8, 11, true, 8)
 	verify(0, 8, false, 8)
 }
+
+func TestIndexAddressTransactions(t *testing.T) {
+	// Construct test chain db with signed transactions
+	var (
+		chainDb  = NewMemoryDatabase()
+		key, _   = crypto.GenerateKey()
+		sender   = crypto.PubkeyToAddress(key.PublicKey)
+		to       = common.BytesToAddress([]byte{0x11})
+		signer   = types.LatestSignerForChainID(big.NewInt(1337))
+		txs      []*types.Transaction
+		creation common.Address
+	)
+	block := types.NewBlock(&types.Header{Number: big.NewInt(int64(0))}, nil, nil, nil, newTestHasher())
+	WriteBlock(chainDb, block)
+	WriteCanonicalHash(chainDb, block.Hash(), block.NumberU64())
+
+	for i := uint64(1); i <= 10; i++ {
+		inner := &types.AccessListTx{
+			ChainID:  big.NewInt(1337),
+			Nonce:    i,
+			GasPrice: big.NewInt(11111),
+			Gas:      1111,
+			To:       &to,
+			Value:    big.NewInt(111),
+		}
+		if i == 5 {
+			inner.To, creation = nil, crypto.CreateAddress(sender, i)
+		}
+		tx := types.MustSignNewTx(key, signer, inner)
+		txs = append(txs, tx)
+		block = types.NewBlock(&types.Header{Number: big.NewInt(int64(i))}, []*types.Transaction{tx}, nil, nil, newTestHasher())
+		WriteBlock(chainDb, block)
+		WriteCanonicalHash(chainDb, block.Hash(), block.NumberU64())
+	}
+	IndexAddressTransactions(chainDb, signer, 0, 11, nil, false)
+	if tail := ReadAddrIndexTail(chainDb); tail == nil || *tail != 0 {
+		t.Fatalf("address index tail mismatch: have %v, want 0", tail)
+	}
+	// The sender participated in all transactions, check paging
+	entries := ReadAddressTxEntries(chainDb, sender, 0, 0, 10, 4)
+	if len(entries) != 4 {
+		t.Fatalf("sender page size mismatch: have %d, want 4", len(entries))
+	}
+	for i, entry := range entries {
+		if entry.BlockNumber != uint64(i+1) || entry.Index != 0 || entry.Hash != txs[i].Hash() {
+			t.Fatalf("sender entry %d mismatch: %+v", i, entry)
+		}
+	}
+	if next := ReadAddressTxEntries(chainDb, sender, 5, 0, 10, 100); len(next) != 6 || next[0].Hash != txs[4].Hash() {
+		t.Fatalf("sender second page mismatch: %+v", next)
+	}
+	if bounded := ReadAddressTxEntries(chainDb, sender, 2, 0, 3, 100); len(bounded) != 2 {
+		t.Fatalf("bounded range mismatch: have %d entries, want 2", len(bounded))
+	}
+	// The recipient misses the contract creation, which is indexed by the
+	// address of the created contract instead
+	if entries := ReadAddressTxEntries(chainDb, to, 0, 0, 10, 100); len(entries) != 9 {
+		t.Fatalf("recipient entries mismatch: have %d, want 9", len(entries))
+	}
+	if entries := ReadAddressTxEntries(chainDb, creation, 0, 0, 10, 100); len(entries) != 1 || entries[0].BlockNumber != 5 {
+		t.Fatalf("contract creation entries mismatch: %+v", entries)
+	}
+	// Unindex the first half of the chain
+	UnindexAddressTransactions(chainDb, signer, 0, 6, nil, false)
+	if tail := ReadAddrIndexTail(chainDb); tail == nil || *tail != 6 {
+		t.Fatalf("address index tail mismatch: have %v, want 6", tail)
+	}
+	if entries := ReadAddressTxEntries(chainDb, sender, 0, 0, 10, 100); len(entries) != 5 || entries[0].BlockNumber != 6 {
+		t.Fatalf("sender entries mismatch after unindexing: %+v", entries)
+	}
+	if entries := ReadAddressTxEntries(chainDb, creation, 0, 0, 10, 100); len(entries) != 0 {
+		t.Fatalf("contract creation not unindexed: %+v", entries)
+	}
+	// The transaction lookup index is independent
+	if ReadTxIndexTail(chainDb) != nil {
+		t.Fatal("transaction index tail written by address indexing")
+	}
+}
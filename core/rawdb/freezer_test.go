@@ -334,6 +334,61 @@ func TestFreezerConcurrentReadonly(t *testing.T) {
 	}
 }
 
+func TestFreezerPrunableTail(t *testing.T) {
+	t.Parallel()
+
+	var (
+		dir      = t.TempDir()
+		tables   = map[string]bool{"kept": true, "pruned": true}
+		prunable = map[string]bool{"pruned": true}
+	)
+	f, err := newFreezer(dir, "", false, 2049, tables, prunable)
+	if err != nil {
+		t.Fatal("can't open freezer", err)
+	}
+	_, err = f.ModifyAncients(func(op ethdb.AncientWriteOp) error {
+		for i := uint64(0); i < 10; i++ {
+			if err := op.AppendRaw("kept", i, []byte{byte(i)}); err != nil {
+				return err
+			}
+			if err := op.AppendRaw("pruned", i, []byte{byte(i)}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	_, err = f.TruncateTail(6)
+	require.NoError(t, err)
+	if tail, _ := f.Tail(); tail != 6 {
+		t.Fatalf("tail mismatch: have %d, want 6", tail)
+	}
+	if _, err := f.Ancient("pruned", 5); err == nil {
+		t.Fatal("expected pruned item to be unavailable")
+	}
+	if blob, err := f.Ancient("kept", 0); err != nil || !bytes.Equal(blob, []byte{0}) {
+		t.Fatalf("retained item mismatch: have %x, err %v", blob, err)
+	}
+	require.NoError(t, f.Close())
+
+	// Reopen the freezer, the differing tails must survive the repair and pass
+	// the validation of a readonly freezer.
+	for _, readonly := range []bool{false, true} {
+		f, err = newFreezer(dir, "", readonly, 2049, tables, prunable)
+		if err != nil {
+			t.Fatalf("can't reopen freezer (readonly %v): %v", readonly, err)
+		}
+		if tail, _ := f.Tail(); tail != 6 {
+			t.Fatalf("tail mismatch after reopen: have %d, want 6", tail)
+		}
+		if _, err := f.Ancient("kept", 0); err != nil {
+			t.Fatalf("retained item lost after reopen: %v", err)
+		}
+		require.NoError(t, f.Close())
+	}
+}
+
 func newFreezerForTesting(t *testing.T, tables map[string]bool) (*Freezer, string) {
 	t.Helper()
 
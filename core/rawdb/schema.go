@@ -79,6 +79,13 @@ var (
 	// txIndexTailKey tracks the oldest block whose transactions have been indexed.
 	txIndexTailKey = []byte("TransactionIndexTail")
 
+	// addrIndexTailKey tracks the oldest block whose transactions have been
+	// indexed by the participating addresses.
+	addrIndexTailKey = []byte("AddressIndexTail")
+
+	// logIndexTailKey tracks the oldest block whose logs have been indexed.
+	logIndexTailKey = []byte("LogIndexTail")
+
 	// fastTxLookupLimitKey tracks the transaction lookup limit during fast sync.
 	// This flag is deprecated, it's kept to avoid reporting errors when inspect
 	// database.
@@ -106,6 +113,7 @@ var (
 	blockReceiptsPrefix = []byte("r") // blockReceiptsPrefix + num (uint64 big endian) + hash -> block receipts
 
 	txLookupPrefix        = []byte("l") // txLookupPrefix + hash -> transaction/receipt lookup metadata
+	addressTxPrefix       = []byte("x") // addressTxPrefix + address + num (uint64 big endian) + index (uint32 big endian) -> transaction hash
 	bloomBitsPrefix       = []byte("B") // bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash -> bloom bits
 	SnapshotAccountPrefix = []byte("a") // SnapshotAccountPrefix + account hash -> account trie value
 	SnapshotStoragePrefix = []byte("o") // SnapshotStoragePrefix + account hash + storage hash -> storage trie value
@@ -135,6 +143,11 @@ var (
 	CliqueSnapshotPrefix   = []byte("clique-")
 	CongressSnapshotPrefix = []byte("congress-")
 
+	blockTracesPrefix = []byte("trace-") // blockTracesPrefix + num (uint64 big endian) + hash + config hash -> block traces
+
+	logAddressPrefix = []byte("logs-a") // logAddressPrefix + address + num (uint64 big endian) -> nil
+	logTopicPrefix   = []byte("logs-t") // logTopicPrefix + position + topic + num (uint64 big endian) -> nil
+
 	BestUpdateKey         = []byte("update-")    // bigEndian64(syncPeriod) -> RLP(types.LightClientUpdate)  (nextCommittee only referenced by root hash)
 	FixedCommitteeRootKey = []byte("fixedRoot-") // bigEndian64(syncPeriod) -> committee root hash
 	SyncCommitteeKey      = []byte("committee-") // bigEndian64(syncPeriod) -> serialized committee
@@ -198,6 +211,35 @@ func txLookupKey(hash common.Hash) []byte {
 	return append(txLookupPrefix, hash.Bytes()...)
 }
 
+// addressTxKey = addressTxPrefix + address + num (uint64 big endian) + index (uint32 big endian)
+func addressTxKey(address common.Address, number uint64, index uint32) []byte {
+	key := make([]byte, len(addressTxPrefix)+common.AddressLength+12)
+	copy(key, addressTxPrefix)
+	copy(key[len(addressTxPrefix):], address.Bytes())
+	binary.BigEndian.PutUint64(key[len(addressTxPrefix)+common.AddressLength:], number)
+	binary.BigEndian.PutUint32(key[len(addressTxPrefix)+common.AddressLength+8:], index)
+	return key
+}
+
+// logAddressKey = logAddressPrefix + address + num (uint64 big endian)
+func logAddressKey(address common.Address, number uint64) []byte {
+	key := make([]byte, len(logAddressPrefix)+common.AddressLength+8)
+	copy(key, logAddressPrefix)
+	copy(key[len(logAddressPrefix):], address.Bytes())
+	binary.BigEndian.PutUint64(key[len(logAddressPrefix)+common.AddressLength:], number)
+	return key
+}
+
+// logTopicKey = logTopicPrefix + position + topic + num (uint64 big endian)
+func logTopicKey(position int, topic common.Hash, number uint64) []byte {
+	key := make([]byte, len(logTopicPrefix)+1+common.HashLength+8)
+	copy(key, logTopicPrefix)
+	key[len(logTopicPrefix)] = byte(position)
+	copy(key[len(logTopicPrefix)+1:], topic.Bytes())
+	binary.BigEndian.PutUint64(key[len(logTopicPrefix)+1+common.HashLength:], number)
+	return key
+}
+
 // accountSnapshotKey = SnapshotAccountPrefix + hash
 func accountSnapshotKey(hash common.Hash) []byte {
 	return append(SnapshotAccountPrefix, hash.Bytes()...)
@@ -227,6 +269,17 @@ func bloomBitsKey(bit uint, section uint64, hash common.Hash) []byte {
 	return key
 }
 
+// blockTracesKey = blockTracesPrefix + num (uint64 big endian) + hash + config hash
+func blockTracesKey(number uint64, hash common.Hash, config common.Hash) []byte {
+	buf := make([]byte, len(blockTracesPrefix)+8+2*common.HashLength)
+	n := copy(buf, blockTracesPrefix)
+	binary.BigEndian.PutUint64(buf[n:], number)
+	n += 8
+	n += copy(buf[n:], hash.Bytes())
+	copy(buf[n:], config.Bytes())
+	return buf
+}
+
 // skeletonHeaderKey = skeletonHeaderPrefix + num (uint64 big endian)
 func skeletonHeaderKey(number uint64) []byte {
 	return append(skeletonHeaderPrefix, encodeBlockNumber(number)...)
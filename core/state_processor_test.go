@@ -17,7 +17,9 @@
 package core
 
 import (
+	"bytes"
 	"crypto/ecdsa"
+	"errors"
 	"math/big"
 	"testing"
 
@@ -29,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
 	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -422,3 +425,99 @@ func GenerateBadBlock(parent *types.Block, engine consensus.Engine, txs types.Tr
 	}
 	return types.NewBlock(header, txs, nil, receipts, trie.NewStackTrie(nil))
 }
+
+// Tests that the history storage contract is installed by the first block of
+// the fork, and then keeps the parent hash of every block.
+func TestProcessParentBlockHash(t *testing.T) {
+	var (
+		config = *params.MergedTestChainConfig
+		engine = beacon.NewFaker()
+	)
+	config.HistoryStorageTime = u64(30) // chain maker blocks are 10 seconds apart, activate at block 3
+	gspec := &Genesis{Config: &config}
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 6, nil)
+
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	defer chain.Stop()
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	// The contract must not exist before the fork
+	statedb, _ := chain.StateAt(blocks[1].Root())
+	if statedb.GetCodeSize(params.HistoryStorageAddress) != 0 {
+		t.Fatal("history storage contract installed before the fork")
+	}
+	statedb, _ = chain.StateAt(chain.CurrentBlock().Root)
+	if code := statedb.GetCode(params.HistoryStorageAddress); !bytes.Equal(code, params.HistoryStorageCode) {
+		t.Fatalf("history storage contract not installed: code %x", code)
+	}
+	// Only the parents of the blocks after the fork are stored
+	for number := uint64(0); number < uint64(len(blocks)); number++ {
+		want := common.Hash{}
+		if number >= 2 {
+			want = chain.GetHeaderByNumber(number).Hash()
+		}
+		slot := common.BigToHash(new(big.Int).SetUint64(number % params.HistoryServeWindow))
+		if have := statedb.GetState(params.HistoryStorageAddress, slot); have != want {
+			t.Errorf("block %d: hash mismatch: have %x, want %x", number, have, want)
+		}
+	}
+	// Contracts can read the hashes through the history storage contract
+	var (
+		head    = chain.CurrentHeader()
+		context = NewEVMBlockContext(head, chain, nil)
+		evm     = vm.NewEVM(context, vm.TxContext{}, statedb, &config, vm.Config{})
+		input   = common.BigToHash(big.NewInt(4))
+	)
+	ret, _, err := evm.Call(vm.AccountRef(common.Address{1}), params.HistoryStorageAddress, input[:], 100_000, common.U2560)
+	if err != nil {
+		t.Fatalf("failed to read block hash: %v", err)
+	}
+	if want := chain.GetHeaderByNumber(4).Hash(); common.BytesToHash(ret) != want {
+		t.Errorf("read hash mismatch: have %x, want %x", ret, want)
+	}
+}
+
+// Tests that blob transactions are rejected from the blob disable fork on.
+func TestBlobTxsDisabled(t *testing.T) {
+	config := *params.MergedTestChainConfig
+	config.BlobDisableTime = u64(10)
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	evm := vm.NewEVM(vm.BlockContext{
+		BlockNumber: new(big.Int),
+		Time:        9,
+		BaseFee:     new(big.Int),
+		BlobBaseFee: new(big.Int),
+		Transfer:    Transfer,
+		CanTransfer: CanTransfer,
+	}, vm.TxContext{GasPrice: new(big.Int)}, statedb, &config, vm.Config{NoBaseFee: true})
+
+	to := common.Address{0x01}
+	msg := &Message{
+		To:                &to,
+		Value:             new(big.Int),
+		GasLimit:          params.TxGas,
+		GasPrice:          new(big.Int),
+		GasFeeCap:         new(big.Int),
+		GasTipCap:         new(big.Int),
+		BlobGasFeeCap:     new(big.Int),
+		BlobHashes:        []common.Hash{{0x01}},
+		SkipAccountChecks: true,
+	}
+	if _, err := ApplyMessage(evm, msg, new(GasPool).AddGas(params.TxGas)); err != nil {
+		t.Fatalf("blob message before the fork failed: %v", err)
+	}
+	evm.Context.Time = 10
+	if _, err := ApplyMessage(evm, msg, new(GasPool).AddGas(params.TxGas)); !errors.Is(err, ErrBlobTxsDisabled) {
+		t.Fatalf("blob message error mismatch: have %v, want %v", err, ErrBlobTxsDisabled)
+	}
+	// Plain messages are still accepted
+	msg.BlobHashes, msg.BlobGasFeeCap = nil, nil
+	if _, err := ApplyMessage(evm, msg, new(GasPool).AddGas(params.TxGas)); err != nil {
+		t.Fatalf("plain message failed: %v", err)
+	}
+}
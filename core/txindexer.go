@@ -21,6 +21,7 @@ import (
 	"fmt"
 
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
 )
@@ -44,7 +45,16 @@ type txIndexer struct {
 	//  * 0: means the entire chain should be indexed
 	//  * N: means the latest N blocks [HEAD-N+1, HEAD] should be indexed
 	//       and all others shouldn't.
-	limit    uint64
+	limit uint64
+
+	// addrSigner derives the transaction senders for the address index, it
+	// is nil if the address index is not maintained.
+	addrSigner types.Signer
+
+	// logs indicates whether the logs of the entire chain are indexed by
+	// their emitting address and topics.
+	logs bool
+
 	db       ethdb.Database
 	progress chan chan TxIndexProgress
 	term     chan chan struct{}
@@ -54,11 +64,22 @@ type txIndexer struct {
 // newTxIndexer initializes the transaction indexer.
 func newTxIndexer(limit uint64, chain *BlockChain) *txIndexer {
 	indexer := &txIndexer{
-		limit:    limit,
-		db:       chain.db,
-		progress: make(chan chan TxIndexProgress),
-		term:     make(chan chan struct{}),
-		closed:   make(chan struct{}),
+		limit:      limit,
+		addrSigner: chain.addrSigner,
+		logs:       chain.cacheConfig.LogIndex,
+		db:         chain.db,
+		progress:   make(chan chan TxIndexProgress),
+		term:       make(chan chan struct{}),
+		closed:     make(chan struct{}),
+	}
+	// Forget the address index if it's not maintained anymore, otherwise the
+	// blocks imported in the meantime would be treated as indexed once it is
+	// enabled again.
+	if indexer.addrSigner == nil && rawdb.ReadAddrIndexTail(chain.db) != nil {
+		rawdb.DeleteAddrIndexTail(chain.db)
+	}
+	if !indexer.logs && rawdb.ReadLogIndexTail(chain.db) != nil {
+		rawdb.DeleteLogIndexTail(chain.db)
 	}
 	go indexer.loop(chain)
 
@@ -68,7 +89,7 @@ func newTxIndexer(limit uint64, chain *BlockChain) *txIndexer {
 	} else {
 		msg = fmt.Sprintf("last %d blocks", limit)
 	}
-	log.Info("Initialized transaction indexer", "range", msg)
+	log.Info("Initialized transaction indexer", "range", msg, "addresses", indexer.addrSigner != nil, "logs", indexer.logs)
 
 	return indexer
 }
@@ -83,20 +104,67 @@ func (indexer *txIndexer) run(tail *uint64, head uint64, stop chan struct{}, don
 	if head == 0 {
 		return
 	}
+	indexer.update(tail, head, indexer.limit, stop, rawdb.IndexTransactions, rawdb.UnindexTransactions)
+
+	// Maintain the address index over the same range, if it's enabled.
+	if indexer.addrSigner != nil {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		var (
+			signer = indexer.addrSigner
+			index  = func(db ethdb.Database, from, to uint64, stop chan struct{}, report bool) {
+				rawdb.IndexAddressTransactions(db, signer, from, to, stop, report)
+			}
+			unindex = func(db ethdb.Database, from, to uint64, stop chan struct{}, report bool) {
+				rawdb.UnindexAddressTransactions(db, signer, from, to, stop, report)
+			}
+		)
+		indexer.update(rawdb.ReadAddrIndexTail(indexer.db), head, indexer.limit, stop, index, unindex)
+	}
+	// Maintain the log index over the entire chain, if it's enabled.
+	if indexer.logs {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		indexer.update(rawdb.ReadLogIndexTail(indexer.db), head, 0, stop, rawdb.IndexLogs, nil)
+	}
+}
+
+// indexFn creates or removes the entries of a transaction or log index in the
+// block range [from, to).
+type indexFn func(db ethdb.Database, from, to uint64, stop chan struct{}, report bool)
+
+// update moves an index with the given tail to the indexing range of the latest
+// limit blocks relative to the chain head. The unindex function is only needed
+// if the limit is not zero.
+func (indexer *txIndexer) update(tail *uint64, head uint64, limit uint64, stop chan struct{}, index, unindex indexFn) {
+	// Block bodies below the history tail were pruned, transactions in them
+	// can neither be indexed nor unindexed anymore, the existing indexes are
+	// retained instead.
+	pruned := HistoryTail(indexer.db)
+
 	// The tail flag is not existent, it means the node is just initialized
 	// and all blocks in the chain (part of them may from ancient store) are
 	// not indexed yet, index the chain according to the configured limit.
 	if tail == nil {
 		from := uint64(0)
-		if indexer.limit != 0 && head >= indexer.limit {
-			from = head - indexer.limit + 1
+		if limit != 0 && head >= limit {
+			from = head - limit + 1
 		}
-		rawdb.IndexTransactions(indexer.db, from, head+1, stop, true)
+		if from < pruned {
+			from = pruned
+		}
+		index(indexer.db, from, head+1, stop, true)
 		return
 	}
 	// The tail flag is existent (which means indexes in [tail, head] should be
 	// present), while the whole chain are requested for indexing.
-	if indexer.limit == 0 || head < indexer.limit {
+	if limit == 0 || head < limit {
 		if *tail > 0 {
 			// It can happen when chain is rewound to a historical point which
 			// is even lower than the indexes tail, recap the indexing target
@@ -105,18 +173,32 @@ func (indexer *txIndexer) run(tail *uint64, head uint64, stop chan struct{}, don
 			if end > head+1 {
 				end = head + 1
 			}
-			rawdb.IndexTransactions(indexer.db, 0, end, stop, true)
+			if pruned < end {
+				index(indexer.db, pruned, end, stop, true)
+			}
 		}
 		return
 	}
 	// The tail flag is existent, adjust the index range according to configured
 	// limit and the latest chain head.
-	if head-indexer.limit+1 < *tail {
+	if head-limit+1 < *tail {
 		// Reindex a part of missing indices and rewind index tail to HEAD-limit
-		rawdb.IndexTransactions(indexer.db, head-indexer.limit+1, *tail, stop, true)
+		from := head - limit + 1
+		if from < pruned {
+			from = pruned
+		}
+		if from < *tail {
+			index(indexer.db, from, *tail, stop, true)
+		}
 	} else {
 		// Unindex a part of stale indices and forward index tail to HEAD-limit
-		rawdb.UnindexTransactions(indexer.db, *tail, head-indexer.limit+1, stop, false)
+		from := *tail
+		if from < pruned {
+			from = pruned
+		}
+		if from < head-limit+1 {
+			unindex(indexer.db, from, head-limit+1, stop, false)
+		}
 	}
 }
 
@@ -19,12 +19,15 @@ package core
 import (
 	"math/big"
 	"os"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/params"
@@ -241,3 +244,190 @@ func TestTxIndexer(t *testing.T) {
 		os.RemoveAll(frdir)
 	}
 }
+
+// TestAddressIndex tests that transactions are indexed by their participating
+// addresses, and that the entries of reorged blocks are dropped.
+func TestAddressIndex(t *testing.T) {
+	limit := uint64(0)
+	testAddressIndex(t, &limit)
+}
+
+// TestAddressIndexWithoutIndexer tests that the address index is maintained by
+// the live-indexing path alone if no transaction indexer runs.
+func TestAddressIndexWithoutIndexer(t *testing.T) {
+	testAddressIndex(t, nil)
+}
+
+func testAddressIndex(t *testing.T, limit *uint64) {
+	var (
+		key, _    = crypto.GenerateKey()
+		sender    = crypto.PubkeyToAddress(key.PublicKey)
+		recipient = common.HexToAddress("0xdeadbeef")
+		created   = crypto.CreateAddress(sender, 1)
+
+		gspec = &Genesis{
+			Config:  params.TestChainConfig,
+			Alloc:   types.GenesisAlloc{sender: {Balance: big.NewInt(1000000000000000000)}},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		engine = ethash.NewFaker()
+		signer = types.LatestSigner(gspec.Config)
+	)
+	gendb := rawdb.NewMemoryDatabase()
+	genchain, _ := NewBlockChain(gendb, nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	defer genchain.Stop()
+
+	genDb, blocks, _ := GenerateChainWithGenesis(gspec, engine, 4, func(i int, gen *BlockGen) {
+		var tx *types.Transaction
+		if i == 1 {
+			tx = types.NewContractCreation(uint64(i), nil, 100000, big.NewInt(10*params.InitialBaseFee), []byte{0x00})
+		} else {
+			tx = types.NewTransaction(uint64(i), recipient, big.NewInt(1000), params.TxGas, big.NewInt(10*params.InitialBaseFee), nil)
+		}
+		tx, _ = types.SignTx(tx, signer, key)
+		gen.AddTxWithChain(genchain, tx)
+	})
+	// Fork off after the second block with empty blocks
+	forks, _ := GenerateChain(gspec.Config, blocks[1], engine, genDb, 4, func(i int, gen *BlockGen) {
+		gen.SetCoinbase(common.Address{0x01})
+	})
+
+	cacheConfig := *defaultCacheConfig
+	cacheConfig.AddressIndex = true
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), &cacheConfig, gspec, nil, engine, vm.Config{}, nil, limit)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	for chain.txIndexer != nil {
+		if progress, err := chain.TxIndexProgress(); err == nil && progress.Done() && rawdb.ReadAddrIndexTail(chain.db) != nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	check := func(address common.Address, want []uint64) {
+		t.Helper()
+		entries, err := chain.GetAddressTransactions(address, 0, 0, chain.CurrentBlock().Number.Uint64(), 100)
+		if err != nil {
+			t.Fatalf("failed to retrieve address transactions: %v", err)
+		}
+		if len(entries) != len(want) {
+			t.Fatalf("entry count mismatch for %x: have %d, want %d", address, len(entries), len(want))
+		}
+		for i, entry := range entries {
+			if entry.BlockNumber != want[i] || entry.Index != 0 {
+				t.Fatalf("entry %d mismatch for %x: have #%d/%d, want #%d/0", i, address, entry.BlockNumber, entry.Index, want[i])
+			}
+			if tx := chain.GetBlockByNumber(entry.BlockNumber).Transactions()[entry.Index]; tx.Hash() != entry.Hash {
+				t.Fatalf("entry %d hash mismatch for %x", i, address)
+			}
+		}
+	}
+	check(sender, []uint64{1, 2, 3, 4})
+	check(recipient, []uint64{1, 3, 4})
+	check(created, []uint64{2})
+
+	// Reorg onto the fork, the entries above the fork point must be dropped
+	if _, err := chain.InsertChain(forks); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+	if head := chain.CurrentBlock().Hash(); head != forks[len(forks)-1].Hash() {
+		t.Fatalf("reorg failed: head %x", head)
+	}
+	check(sender, []uint64{1, 2})
+	check(recipient, []uint64{1})
+	check(created, []uint64{2})
+}
+
+// TestLogIndex tests that the logs of blocks imported before the log index was
+// enabled are indexed in the background, and that reorged blocks are dropped.
+func TestLogIndex(t *testing.T) {
+	var (
+		key, _   = crypto.GenerateKey()
+		sender   = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.HexToAddress("0xc0de")
+		topic    = common.HexToHash("0xaa")
+
+		gspec = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: types.GenesisAlloc{
+				sender:   {Balance: big.NewInt(1000000000000000000)},
+				contract: {Balance: common.Big0, Code: common.FromHex("60aa60006000a100")}, // LOG1(0, 0, 0xaa)
+			},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		engine = ethash.NewFaker()
+		signer = types.LatestSigner(gspec.Config)
+		nonce  = uint64(0)
+	)
+	genchain, _ := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	defer genchain.Stop()
+
+	genDb, blocks, _ := GenerateChainWithGenesis(gspec, engine, 8, func(i int, gen *BlockGen) {
+		if i%2 == 0 {
+			tx, _ := types.SignTx(types.NewTransaction(nonce, contract, common.Big0, 100000, big.NewInt(10*params.InitialBaseFee), nil), signer, key)
+			gen.AddTxWithChain(genchain, tx)
+			nonce++
+		}
+	})
+	forks, _ := GenerateChain(gspec.Config, blocks[3], engine, genDb, 6, func(i int, gen *BlockGen) {
+		gen.SetCoinbase(common.Address{0x01})
+	})
+	// Import the chain without log indexing, enable it afterwards
+	var (
+		db    = rawdb.NewMemoryDatabase()
+		limit = uint64(0)
+	)
+	chain, err := NewBlockChain(db, nil, gspec, nil, engine, vm.Config{}, nil, &limit)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	chain.Stop()
+	if rawdb.ReadLogIndexTail(db) != nil {
+		t.Fatal("log index tail written while disabled")
+	}
+	cacheConfig := *defaultCacheConfig
+	cacheConfig.LogIndex = true
+	chain, err = NewBlockChain(db, &cacheConfig, gspec, nil, engine, vm.Config{}, nil, &limit)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	for {
+		if tail := rawdb.ReadLogIndexTail(db); tail != nil && *tail == 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	check := func(want []uint64) {
+		t.Helper()
+		head := chain.CurrentBlock().Number.Uint64()
+		if have := rawdb.ReadLogAddressBlocks(db, contract, 0, head); !reflect.DeepEqual(have, want) {
+			t.Fatalf("address blocks mismatch: have %v, want %v", have, want)
+		}
+		if have := rawdb.ReadLogTopicBlocks(db, 0, topic, 0, head); !reflect.DeepEqual(have, want) {
+			t.Fatalf("topic blocks mismatch: have %v, want %v", have, want)
+		}
+		if have := rawdb.ReadLogTopicBlocks(db, 1, topic, 0, head); len(have) != 0 {
+			t.Fatalf("unexpected blocks with topic at position 1: %v", have)
+		}
+	}
+	check([]uint64{1, 3, 5, 7})
+
+	// Reorg onto the fork, the entries above the fork point must be dropped
+	if _, err := chain.InsertChain(forks); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+	if head := chain.CurrentBlock().Hash(); head != forks[len(forks)-1].Hash() {
+		t.Fatalf("reorg failed: head %x", head)
+	}
+	check([]uint64{1, 3})
+}
@@ -18,9 +18,11 @@
 package legacypool
 
 import (
+	"container/heap"
 	"errors"
 	"math"
 	"math/big"
+	"slices"
 	"sort"
 	"sync"
 	"sync/atomic"
@@ -33,6 +35,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
@@ -58,6 +61,15 @@ var (
 	// ErrTxPoolOverflow is returned if the transaction pool is full and can't accept
 	// another remote transaction.
 	ErrTxPoolOverflow = errors.New("txpool is full")
+
+	// ErrOutOfOrderTxFromDelegated is returned when the transaction with gapped
+	// nonce received from the accounts with delegation or pending delegation.
+	ErrOutOfOrderTxFromDelegated = errors.New("gapped-nonce tx from delegated accounts")
+
+	// ErrAuthorityReserved is returned if a transaction has an authorization
+	// signed by an address which already has in-flight transactions known to the
+	// pool.
+	ErrAuthorityReserved = errors.New("authority already reserved")
 )
 
 var (
@@ -222,6 +234,8 @@ type LegacyPool struct {
 	all     *lookup                      // All transactions to allow lookups
 	priced  *pricedList                  // All transactions sorted by price
 
+	sponsored map[common.Address]*uint256.Int // Gas cost sponsored by each paymaster in the pending lists
+
 	reqResetCh      chan *txpoolResetRequest
 	reqPromoteCh    chan *accountSet
 	queueTxEventCh  chan *types.Transaction
@@ -252,6 +266,7 @@ func New(config Config, chain BlockChain) *LegacyPool {
 		pending:         make(map[common.Address]*list),
 		queue:           make(map[common.Address]*list),
 		beats:           make(map[common.Address]time.Time),
+		sponsored:       make(map[common.Address]*uint256.Int),
 		all:             newLookup(),
 		reqResetCh:      make(chan *txpoolResetRequest),
 		reqPromoteCh:    make(chan *accountSet),
@@ -274,10 +289,11 @@ func New(config Config, chain BlockChain) *LegacyPool {
 }
 
 // Filter returns whether the given transaction can be consumed by the legacy
-// pool, specifically, whether it is a Legacy, AccessList or Dynamic transaction.
+// pool, specifically, whether it is a Legacy, AccessList, Dynamic, SetCode or
+// Sponsored transaction.
 func (pool *LegacyPool) Filter(tx *types.Transaction) bool {
 	switch tx.Type() {
-	case types.LegacyTxType, types.AccessListTxType, types.DynamicFeeTxType:
+	case types.LegacyTxType, types.AccessListTxType, types.DynamicFeeTxType, types.SetCodeTxType, types.SponsoredTxType:
 		return true
 	default:
 		return false
@@ -609,9 +625,11 @@ func (pool *LegacyPool) validateTxBasics(tx *types.Transaction, local bool) erro
 		Accept: 0 |
 			1<<types.LegacyTxType |
 			1<<types.AccessListTxType |
-			1<<types.DynamicFeeTxType,
-		MaxSize: txMaxSize,
-		MinTip:  pool.gasTip.Load().ToBig(),
+			1<<types.DynamicFeeTxType |
+			1<<types.SetCodeTxType,
+		AcceptSponsored: true,
+		MaxSize:         txMaxSize,
+		MinTip:          pool.gasTip.Load().ToBig(),
 	}
 	if local {
 		opts.MinTip = new(big.Int)
@@ -653,10 +671,107 @@ func (pool *LegacyPool) validateTx(tx *types.Transaction, local bool) error {
 			}
 			return nil
 		},
+		ExistingPaymasterExpenditure: func(paymaster common.Address) *big.Int {
+			if total := pool.sponsored[paymaster]; total != nil {
+				return total.ToBig()
+			}
+			return new(big.Int)
+		},
+		ExistingPaymasterCost: func(addr common.Address, nonce uint64, paymaster common.Address) *big.Int {
+			if list := pool.pending[addr]; list != nil {
+				if tx := list.txs.Get(nonce); tx != nil && tx.Paymaster() != nil && *tx.Paymaster() == paymaster {
+					return tx.PaymasterCost()
+				}
+			}
+			return nil
+		},
+		ValidatePaymaster: pool.validatePaymaster,
 	}
 	if err := txpool.ValidateTransactionWithState(tx, pool.signer, opts); err != nil {
 		return err
 	}
+	return pool.validateAuth(tx)
+}
+
+// validatePaymaster simulates the validatePaymaster call of a sponsored
+// transaction on top of the current pool state.
+func (pool *LegacyPool) validatePaymaster(tx *types.Transaction) error {
+	head := pool.currentHead.Load()
+	msg, err := core.TransactionToMessage(tx, pool.signer, head.BaseFee)
+	if err != nil {
+		return err
+	}
+	context := vm.BlockContext{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		GetHash:     func(uint64) common.Hash { return common.Hash{} },
+		Coinbase:    head.Coinbase,
+		BlockNumber: new(big.Int).Add(head.Number, common.Big1),
+		Time:        head.Time,
+		Difficulty:  head.Difficulty,
+		BaseFee:     head.BaseFee,
+		GasLimit:    head.GasLimit,
+	}
+	if head.Difficulty.Sign() == 0 {
+		context.Random = &head.MixDigest
+	}
+	// Any state changes of the paymaster are discarded after the simulation
+	snapshot := pool.currentState.Snapshot()
+	defer pool.currentState.RevertToSnapshot(snapshot)
+
+	evm := vm.NewEVM(context, core.NewEVMTxContext(msg), pool.currentState, pool.chainconfig, vm.Config{})
+	_, err = core.ValidatePaymaster(evm, msg, params.PaymasterValidationGas)
+	return err
+}
+
+// checkDelegationLimit determines if the tx sender is delegated or has a
+// pending delegation, and if so, ensures they have at most one in-flight
+// **executable** transaction, e.g. disallow stacked and gapped transactions
+// from the account.
+func (pool *LegacyPool) checkDelegationLimit(tx *types.Transaction) error {
+	from, _ := types.Sender(pool.signer, tx) // validated
+
+	// Short circuit if the sender has neither delegation nor pending delegation.
+	if _, delegated := types.ParseDelegation(pool.currentState.GetCode(from)); !delegated && !pool.all.hasAuth(from) {
+		return nil
+	}
+	pending := pool.pending[from]
+	if pending == nil {
+		// Transaction with gapped nonce is not supported for delegated accounts
+		if pool.pendingNonces.get(from) != tx.Nonce() {
+			return ErrOutOfOrderTxFromDelegated
+		}
+		return nil
+	}
+	// Transaction replacement is supported
+	if pending.Contains(tx.Nonce()) {
+		return nil
+	}
+	return txpool.ErrInflightTxLimitReached
+}
+
+// validateAuth verifies that the transaction complies with code authorization
+// restrictions brought by SetCode transaction type.
+func (pool *LegacyPool) validateAuth(tx *types.Transaction) error {
+	// Allow at most one in-flight tx for delegated accounts or those with a
+	// pending authorization.
+	if err := pool.checkDelegationLimit(tx); err != nil {
+		return err
+	}
+	// For symmetry, allow at most one in-flight tx for any authority with a
+	// pending transaction.
+	for _, auth := range tx.SetCodeAuthorities() {
+		var count int
+		if pending := pool.pending[auth]; pending != nil {
+			count += pending.Len()
+		}
+		if queue := pool.queue[auth]; queue != nil {
+			count += queue.Len()
+		}
+		if count > 1 {
+			return ErrAuthorityReserved
+		}
+	}
 	return nil
 }
 
@@ -900,6 +1015,7 @@ func (pool *LegacyPool) promoteTx(addr common.Address, hash common.Hash, tx *typ
 	// Try to insert the transaction into the pending queue
 	if pool.pending[addr] == nil {
 		pool.pending[addr] = newList(true)
+		pool.pending[addr].sponsored = pool.sponsored
 	}
 	list := pool.pending[addr]
 
@@ -1647,6 +1763,18 @@ func (pool *LegacyPool) truncateQueue() {
 // is always explicitly triggered by SetBaseFee and it would be unnecessary and wasteful
 // to trigger a re-heap is this function
 func (pool *LegacyPool) demoteUnexecutables() {
+	// Gather the remaining budget of the paymasters unable to cover the gas of
+	// all their sponsored pending transactions anymore
+	budgets := make(map[common.Address]*uint256.Int)
+	for paymaster, total := range pool.sponsored {
+		if balance := pool.currentState.GetBalance(paymaster); balance.Cmp(total) < 0 {
+			budgets[paymaster] = balance.Clone()
+		}
+	}
+	var unsponsored map[common.Hash]struct{}
+	if len(budgets) > 0 {
+		unsponsored = pool.unsponsored(budgets)
+	}
 	// Iterate over all accounts and demote any non-executable transactions
 	gasLimit := pool.currentHead.Load().GasLimit
 	for addr, list := range pool.pending {
@@ -1666,6 +1794,17 @@ func (pool *LegacyPool) demoteUnexecutables() {
 			log.Trace("Removed unpayable pending transaction", "hash", hash)
 			pool.all.Remove(hash)
 		}
+		// Drop all sponsored transactions their paymaster can't pay for anymore
+		if len(unsponsored) > 0 {
+			removed, demoted := list.FilterSponsored(unsponsored)
+			for _, tx := range removed {
+				hash := tx.Hash()
+				log.Trace("Removed unsponsored pending transaction", "hash", hash)
+				pool.all.Remove(hash)
+			}
+			drops = append(drops, removed...)
+			invalids = append(invalids, demoted...)
+		}
 		pendingNofundsMeter.Mark(int64(len(drops)))
 
 		for _, tx := range invalids {
@@ -1701,6 +1840,47 @@ func (pool *LegacyPool) demoteUnexecutables() {
 	}
 }
 
+// unsponsored hands out the remaining budgets of the paymasters to their sponsored
+// pending transactions, the best paying ones first and every account in nonce
+// order, and returns the transactions the budgets can't cover anymore. Once a
+// transaction of an account is left uncovered, the later ones are invalidated
+// anyway and don't consume any budget.
+func (pool *LegacyPool) unsponsored(budgets map[common.Address]*uint256.Int) map[common.Hash]struct{} {
+	heads := make(sponsoredHeap, 0, len(pool.pending))
+	for addr, list := range pool.pending {
+		var txs types.Transactions
+		for _, tx := range list.Flatten() {
+			if paymaster := tx.Paymaster(); paymaster != nil && budgets[*paymaster] != nil {
+				txs = append(txs, tx)
+			}
+		}
+		if len(txs) > 0 {
+			heads = append(heads, &sponsoredTxs{addr: addr, txs: txs})
+		}
+	}
+	heap.Init(&heads)
+
+	drops := make(map[common.Hash]struct{})
+	for len(heads) > 0 {
+		head := heads[0]
+		tx := head.txs[0]
+
+		budget, cost := budgets[*tx.Paymaster()], uint256.MustFromBig(tx.PaymasterCost())
+		if budget.Cmp(cost) < 0 {
+			drops[tx.Hash()] = struct{}{}
+			heap.Pop(&heads)
+			continue
+		}
+		budget.Sub(budget, cost)
+		if head.txs = head.txs[1:]; len(head.txs) == 0 {
+			heap.Pop(&heads)
+		} else {
+			heap.Fix(&heads, 0)
+		}
+	}
+	return drops
+}
+
 // addressByHeartbeat is an account address tagged with its last activity timestamp.
 type addressByHeartbeat struct {
 	address   common.Address
@@ -1800,6 +1980,8 @@ type lookup struct {
 	lock    sync.RWMutex
 	locals  map[common.Hash]*types.Transaction
 	remotes map[common.Hash]*types.Transaction
+
+	auths map[common.Address][]common.Hash // All accounts with a pooled authorization
 }
 
 // newLookup returns a new lookup structure.
@@ -1807,6 +1989,7 @@ func newLookup() *lookup {
 	return &lookup{
 		locals:  make(map[common.Hash]*types.Transaction),
 		remotes: make(map[common.Hash]*types.Transaction),
+		auths:   make(map[common.Address][]common.Hash),
 	}
 }
 
@@ -1905,6 +2088,7 @@ func (t *lookup) Add(tx *types.Transaction, local bool) {
 	} else {
 		t.remotes[tx.Hash()] = tx
 	}
+	t.addAuthorities(tx)
 }
 
 // Remove removes a transaction from the lookup.
@@ -1920,6 +2104,7 @@ func (t *lookup) Remove(hash common.Hash) {
 		log.Error("No transaction found to be deleted", "hash", hash)
 		return
 	}
+	t.removeAuthorities(tx)
 	t.slots -= numSlots(tx)
 	slotsGauge.Update(int64(t.slots))
 
@@ -1956,6 +2141,49 @@ func (t *lookup) RemotesBelowTip(threshold *big.Int) types.Transactions {
 	return found
 }
 
+// addAuthorities tracks the supplied tx in relation to each authority it
+// specifies.
+func (t *lookup) addAuthorities(tx *types.Transaction) {
+	for _, addr := range tx.SetCodeAuthorities() {
+		list := t.auths[addr]
+		if slices.Contains(list, tx.Hash()) {
+			// Don't add duplicates.
+			continue
+		}
+		t.auths[addr] = append(list, tx.Hash())
+	}
+}
+
+// removeAuthorities stops tracking the supplied tx in relation to its
+// authorities.
+func (t *lookup) removeAuthorities(tx *types.Transaction) {
+	hash := tx.Hash()
+	for _, addr := range tx.SetCodeAuthorities() {
+		list := t.auths[addr]
+		// Remove tx from tracker.
+		if i := slices.Index(list, hash); i >= 0 {
+			list = append(list[:i], list[i+1:]...)
+		} else {
+			log.Error("Authority with untracked tx", "addr", addr, "hash", hash)
+		}
+		if len(list) == 0 {
+			// If list is newly empty, delete it entirely.
+			delete(t.auths, addr)
+			continue
+		}
+		t.auths[addr] = list
+	}
+}
+
+// hasAuth returns a flag indicating whether there are pending authorizations
+// from the specified address.
+func (t *lookup) hasAuth(addr common.Address) bool {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return len(t.auths[addr]) > 0
+}
+
 // numSlots calculates the number of slots needed for a single transaction.
 func numSlots(tx *types.Transaction) int {
 	return int((tx.Size() + txSlotSize - 1) / txSlotSize)
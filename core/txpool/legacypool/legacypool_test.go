@@ -35,6 +35,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/params"
@@ -80,8 +81,9 @@ func (bc *testBlockChain) Config() *params.ChainConfig {
 
 func (bc *testBlockChain) CurrentBlock() *types.Header {
 	return &types.Header{
-		Number:   new(big.Int),
-		GasLimit: bc.gasLimit.Load(),
+		Number:     new(big.Int),
+		Difficulty: common.Big1,
+		GasLimit:   bc.gasLimit.Load(),
 	}
 }
 
@@ -129,6 +131,39 @@ func dynamicFeeTx(nonce uint64, gaslimit uint64, gasFee *big.Int, tip *big.Int,
 	return tx
 }
 
+func pricedSetCodeTx(nonce uint64, gaslimit uint64, gasFee, tip *uint256.Int, key *ecdsa.PrivateKey, authKeys []*ecdsa.PrivateKey) *types.Transaction {
+	var authList []types.SetCodeAuthorization
+	for _, authKey := range authKeys {
+		auth, _ := types.SignSetCode(authKey, types.SetCodeAuthorization{
+			ChainID: *uint256.MustFromBig(params.TestChainConfig.ChainID),
+			Address: common.Address{0x42},
+		})
+		authList = append(authList, auth)
+	}
+	return types.MustSignNewTx(key, types.LatestSignerForChainID(params.TestChainConfig.ChainID), &types.SetCodeTx{
+		ChainID:   uint256.MustFromBig(params.TestChainConfig.ChainID),
+		Nonce:     nonce,
+		GasTipCap: tip,
+		GasFeeCap: gasFee,
+		Gas:       gaslimit,
+		Value:     uint256.NewInt(100),
+		AuthList:  authList,
+	})
+}
+
+func sponsoredTx(nonce uint64, gaslimit uint64, gasFee *big.Int, tip *big.Int, paymaster common.Address, key *ecdsa.PrivateKey) *types.Transaction {
+	return types.MustSignNewTx(key, types.LatestSignerForChainID(params.TestChainConfig.ChainID), &types.SponsoredTx{
+		ChainID:   params.TestChainConfig.ChainID,
+		Nonce:     nonce,
+		GasTipCap: tip,
+		GasFeeCap: gasFee,
+		Gas:       gaslimit,
+		To:        &common.Address{},
+		Value:     big.NewInt(0),
+		Paymaster: paymaster,
+	})
+}
+
 func makeAddressReserver() txpool.AddressReserver {
 	var (
 		reserved = make(map[common.Address]struct{})
@@ -200,6 +235,26 @@ func validatePoolInternals(pool *LegacyPool) error {
 			return fmt.Errorf("pending nonce mismatch: have %v, want %v", nonce, last+1)
 		}
 	}
+	// Ensure the sponsored gas tracked per paymaster matches the pending set
+	sponsored := make(map[common.Address]*big.Int)
+	for _, list := range pool.pending {
+		for _, tx := range list.txs.items {
+			if paymaster := tx.Paymaster(); paymaster != nil && tx.PaymasterCost().Sign() > 0 {
+				if sponsored[*paymaster] == nil {
+					sponsored[*paymaster] = new(big.Int)
+				}
+				sponsored[*paymaster].Add(sponsored[*paymaster], tx.PaymasterCost())
+			}
+		}
+	}
+	if len(sponsored) != len(pool.sponsored) {
+		return fmt.Errorf("sponsoring paymaster count mismatch: have %d, want %d", len(pool.sponsored), len(sponsored))
+	}
+	for paymaster, total := range sponsored {
+		if have := pool.sponsored[paymaster]; have == nil || have.ToBig().Cmp(total) != 0 {
+			return fmt.Errorf("paymaster %x sponsored cost mismatch: have %v, want %v", paymaster, have, total)
+		}
+	}
 	return nil
 }
 
@@ -2530,6 +2585,79 @@ func TestSlotCount(t *testing.T) {
 	}
 }
 
+// Tests that set code transactions are only accepted after Prague, and that
+// accounts with a delegation or a pooled authorization may have at most one
+// in-flight transaction.
+func TestSetCodeTransactions(t *testing.T) {
+	t.Parallel()
+
+	var (
+		keyA, _ = crypto.GenerateKey()
+		keyB, _ = crypto.GenerateKey()
+		keyC, _ = crypto.GenerateKey()
+		addrA   = crypto.PubkeyToAddress(keyA.PublicKey)
+		addrB   = crypto.PubkeyToAddress(keyB.PublicKey)
+		addrC   = crypto.PubkeyToAddress(keyC.PublicKey)
+		fee     = uint256.NewInt(1000)
+		tip     = uint256.NewInt(1)
+	)
+	// Set code transactions are rejected before Prague
+	pool, _ := setupPoolWithConfig(eip1559Config)
+	testAddBalance(pool, addrA, big.NewInt(params.Ether))
+	if err := pool.addRemoteSync(pricedSetCodeTx(0, 250000, fee, tip, keyA, []*ecdsa.PrivateKey{keyB})); !errors.Is(err, core.ErrTxTypeNotSupported) {
+		t.Fatalf("pre-Prague set code tx: have %v, want %v", err, core.ErrTxTypeNotSupported)
+	}
+	pool.Close()
+
+	pragueConfig := *eip1559Config
+	pragueConfig.PragueTime = new(uint64)
+	pool, _ = setupPoolWithConfig(&pragueConfig)
+	defer pool.Close()
+
+	testAddBalance(pool, addrA, big.NewInt(params.Ether))
+	testAddBalance(pool, addrB, big.NewInt(params.Ether))
+	testAddBalance(pool, addrC, big.NewInt(params.Ether))
+
+	// A set code tx needs at least one authorization
+	if err := pool.addRemoteSync(pricedSetCodeTx(0, 250000, fee, tip, keyA, nil)); err == nil {
+		t.Fatal("set code tx without authorizations accepted")
+	}
+	// A authorizes a delegation of B, which may then send a single transaction
+	if err := pool.addRemoteSync(pricedSetCodeTx(0, 250000, fee, tip, keyA, []*ecdsa.PrivateKey{keyB})); err != nil {
+		t.Fatalf("failed to add set code tx: %v", err)
+	}
+	if err := pool.addRemoteSync(dynamicFeeTx(1, 100000, fee.ToBig(), tip.ToBig(), keyB)); !errors.Is(err, ErrOutOfOrderTxFromDelegated) {
+		t.Fatalf("gapped tx of pending delegation: have %v, want %v", err, ErrOutOfOrderTxFromDelegated)
+	}
+	if err := pool.addRemoteSync(dynamicFeeTx(0, 100000, fee.ToBig(), tip.ToBig(), keyB)); err != nil {
+		t.Fatalf("failed to add tx of pending delegation: %v", err)
+	}
+	if err := pool.addRemoteSync(dynamicFeeTx(1, 100000, fee.ToBig(), tip.ToBig(), keyB)); !errors.Is(err, txpool.ErrInflightTxLimitReached) {
+		t.Fatalf("stacked tx of pending delegation: have %v, want %v", err, txpool.ErrInflightTxLimitReached)
+	}
+	// C stacks transactions, so it can't authorize a delegation anymore
+	if err := pool.addRemoteSync(dynamicFeeTx(0, 100000, fee.ToBig(), tip.ToBig(), keyC)); err != nil {
+		t.Fatalf("failed to add tx: %v", err)
+	}
+	if err := pool.addRemoteSync(dynamicFeeTx(1, 100000, fee.ToBig(), tip.ToBig(), keyC)); err != nil {
+		t.Fatalf("failed to add tx: %v", err)
+	}
+	if err := pool.addRemoteSync(pricedSetCodeTx(1, 250000, fee, tip, keyA, []*ecdsa.PrivateKey{keyC})); !errors.Is(err, ErrAuthorityReserved) {
+		t.Fatalf("authorization of reserved account: have %v, want %v", err, ErrAuthorityReserved)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	// Once the set code tx leaves the pool, B is no longer limited
+	pool.mu.Lock()
+	pool.removeTx(pool.pending[addrA].txs.Get(0).Hash(), true, true)
+	pool.mu.Unlock()
+
+	if err := pool.addRemoteSync(dynamicFeeTx(1, 100000, fee.ToBig(), tip.ToBig(), keyB)); err != nil {
+		t.Fatalf("failed to add tx after delegation was dropped: %v", err)
+	}
+}
+
 // Benchmarks the speed of validating the contents of the pending queue of the
 // transaction pool.
 func BenchmarkPendingDemotion100(b *testing.B)   { benchmarkPendingDemotion(b, 100) }
@@ -2670,3 +2798,89 @@ func BenchmarkMultiAccountBatchInsert(b *testing.B) {
 		pool.addRemotesSync([]*types.Transaction{tx})
 	}
 }
+
+// Tests that the pool doesn't accept more sponsored transactions than their
+// paymaster can pay for, and drops the pending ones it can't cover anymore.
+func TestSponsoredTransactions(t *testing.T) {
+	t.Parallel()
+
+	config := *eip1559Config
+	config.PaymasterTime = new(uint64)
+	pool, _ := setupPoolWithConfig(&config)
+	defer pool.Close()
+
+	var (
+		keyA, _   = crypto.GenerateKey()
+		keyB, _   = crypto.GenerateKey()
+		keyC, _   = crypto.GenerateKey()
+		paymaster = common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+		fee       = big.NewInt(1000)
+		tip       = big.NewInt(1)
+	)
+	// The paymaster approves everything and can pay for 200000 gas
+	pool.mu.Lock()
+	pool.currentState.SetCode(paymaster, []byte{
+		byte(vm.PUSH1), 1,
+		byte(vm.PUSH1), 0,
+		byte(vm.MSTORE),
+		byte(vm.PUSH1), 32,
+		byte(vm.PUSH1), 0,
+		byte(vm.RETURN),
+	})
+	pool.mu.Unlock()
+	testAddBalance(pool, paymaster, big.NewInt(200000*1000))
+
+	if err := pool.addRemoteSync(sponsoredTx(0, 100000, fee, tip, paymaster, keyA)); err != nil {
+		t.Fatalf("failed to add sponsored tx: %v", err)
+	}
+	if err := pool.addRemoteSync(sponsoredTx(0, 100000, fee, tip, paymaster, keyB)); err != nil {
+		t.Fatalf("failed to add sponsored tx: %v", err)
+	}
+	// Each transaction is affordable on its own, but not on top of the pooled ones
+	if err := pool.addRemoteSync(sponsoredTx(0, 100000, fee, tip, paymaster, keyC)); !errors.Is(err, core.ErrInsufficientFunds) {
+		t.Fatalf("overdrafting sponsored tx: have %v, want %v", err, core.ErrInsufficientFunds)
+	}
+	// Replacements only account for the difference to the replaced transaction
+	if err := pool.addRemoteSync(sponsoredTx(0, 50000, big.NewInt(1100), big.NewInt(2), paymaster, keyA)); err != nil {
+		t.Fatalf("failed to replace sponsored tx: %v", err)
+	}
+	if err := pool.addRemoteSync(sponsoredTx(0, 40000, fee, tip, paymaster, keyC)); err != nil {
+		t.Fatalf("failed to add sponsored tx: %v", err)
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	// Reduce the paymaster funds and check that it's left covering the best
+	// paying transactions: A, with too little left for either B or C
+	testAddBalance(pool, paymaster, big.NewInt(-110000*1000))
+	<-pool.requestReset(nil, nil)
+
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	if pending, _ := pool.Stats(); pending != 1 {
+		t.Fatalf("pending transactions mismatch: have %d, want %d", pending, 1)
+	}
+	for key, want := range map[*ecdsa.PrivateKey]bool{keyA: true, keyB: false, keyC: false} {
+		addr := crypto.PubkeyToAddress(key.PublicKey)
+		if _, have := pool.pending[addr]; have != want {
+			t.Errorf("account %x pending mismatch: have %v, want %v", addr, have, want)
+		}
+	}
+	if total := pool.sponsored[paymaster]; total.Cmp(uint256.NewInt(55000*1000)) != 0 {
+		t.Fatalf("sponsored total mismatch: have %v, want %v", total, 55000*1000)
+	}
+	// Drain the paymaster and check that all its transactions are dropped
+	testAddBalance(pool, paymaster, big.NewInt(-90000*1000))
+	<-pool.requestReset(nil, nil)
+
+	if pending, queued := pool.Stats(); pending != 0 || queued != 0 {
+		t.Fatalf("transactions not dropped: pending %d, queued %d", pending, queued)
+	}
+	if pool.all.Count() != 0 {
+		t.Fatalf("total transaction mismatch: have %d, want 0", pool.all.Count())
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
@@ -276,6 +276,8 @@ type list struct {
 	costcap   *uint256.Int // Price of the highest costing transaction (reset only if exceeds balance)
 	gascap    uint64       // Gas limit of the highest spending transaction (reset only if exceeds block limit)
 	totalcost *uint256.Int // Total cost of all transactions in the list
+
+	sponsored map[common.Address]*uint256.Int // Gas cost sponsored per paymaster, shared across lists (nil if untracked)
 }
 
 // newList creates a new transaction list for maintaining nonce-indexable fast,
@@ -332,6 +334,7 @@ func (l *list) Add(tx *types.Transaction, priceBump uint64) (bool, *types.Transa
 		return false, nil
 	}
 	l.totalcost.Add(l.totalcost, cost)
+	l.addSponsoredCost(tx)
 
 	// Otherwise overwrite the old transaction with the current one
 	l.txs.Put(tx)
@@ -396,6 +399,33 @@ func (l *list) Filter(costLimit *uint256.Int, gasLimit uint64) (types.Transactio
 	return removed, invalids
 }
 
+// FilterSponsored removes the given sponsored transactions from the list,
+// which their paymaster can't cover anymore. Strict-mode invalidated
+// transactions are also returned.
+func (l *list) FilterSponsored(drops map[common.Hash]struct{}) (types.Transactions, types.Transactions) {
+	var removed types.Transactions
+	for _, tx := range l.txs.flatten() {
+		if _, ok := drops[tx.Hash()]; ok {
+			removed = append(removed, tx)
+		}
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	for _, tx := range removed {
+		l.txs.Remove(tx.Nonce())
+	}
+	var invalids types.Transactions
+	if l.strict {
+		lowest := removed[0].Nonce()
+		invalids = l.txs.filter(func(tx *types.Transaction) bool { return tx.Nonce() > lowest })
+		l.txs.reheap()
+	}
+	l.subTotalCost(removed)
+	l.subTotalCost(invalids)
+	return removed, invalids
+}
+
 // Cap places a hard limit on the number of items, returning all transactions
 // exceeding that limit.
 func (l *list) Cap(threshold int) types.Transactions {
@@ -467,6 +497,49 @@ func (l *list) subTotalCost(txs []*types.Transaction) {
 		if underflow {
 			panic("totalcost underflow")
 		}
+		l.subSponsoredCost(tx)
+	}
+}
+
+// addSponsoredCost adds the gas cost of a sponsored transaction to the total
+// tracked for its paymaster.
+func (l *list) addSponsoredCost(tx *types.Transaction) {
+	paymaster := tx.Paymaster()
+	if l.sponsored == nil || paymaster == nil {
+		return
+	}
+	cost := uint256.MustFromBig(tx.PaymasterCost())
+	if cost.IsZero() {
+		return
+	}
+	total, ok := l.sponsored[*paymaster]
+	if !ok {
+		total = new(uint256.Int)
+		l.sponsored[*paymaster] = total
+	}
+	total.Add(total, cost)
+}
+
+// subSponsoredCost subtracts the gas cost of a sponsored transaction from the
+// total tracked for its paymaster.
+func (l *list) subSponsoredCost(tx *types.Transaction) {
+	paymaster := tx.Paymaster()
+	if l.sponsored == nil || paymaster == nil {
+		return
+	}
+	cost := uint256.MustFromBig(tx.PaymasterCost())
+	if cost.IsZero() {
+		return
+	}
+	total, ok := l.sponsored[*paymaster]
+	if !ok {
+		panic("sponsored cost underflow")
+	}
+	if _, underflow := total.SubOverflow(total, cost); underflow {
+		panic("sponsored cost underflow")
+	}
+	if total.IsZero() {
+		delete(l.sponsored, *paymaster)
 	}
 }
 
@@ -685,3 +758,45 @@ func (l *pricedList) SetBaseFee(baseFee *big.Int) {
 	l.urgent.baseFee = baseFee
 	l.Reheap()
 }
+
+// sponsoredTxs is the sponsored transactions of an account still to be covered
+// by their paymasters, in nonce order.
+type sponsoredTxs struct {
+	addr common.Address
+	txs  types.Transactions
+}
+
+// sponsoredHeap is a heap of accounts ordered by the price of their next
+// sponsored transaction, the highest fee cap and then tip first. Ties are
+// broken by nonce and address to keep the order deterministic.
+type sponsoredHeap []*sponsoredTxs
+
+func (h sponsoredHeap) Len() int      { return len(h) }
+func (h sponsoredHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h sponsoredHeap) Less(i, j int) bool {
+	a, b := h[i].txs[0], h[j].txs[0]
+	if c := a.GasFeeCapCmp(b); c != 0 {
+		return c > 0
+	}
+	if c := a.GasTipCapCmp(b); c != 0 {
+		return c > 0
+	}
+	if a.Nonce() != b.Nonce() {
+		return a.Nonce() < b.Nonce()
+	}
+	return h[i].addr.Cmp(h[j].addr) < 0
+}
+
+func (h *sponsoredHeap) Push(x interface{}) {
+	*h = append(*h, x.(*sponsoredTxs))
+}
+
+func (h *sponsoredHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	old[n-1] = nil
+	*h = old[0 : n-1]
+	return x
+}
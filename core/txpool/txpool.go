@@ -47,8 +47,50 @@ var (
 	// This is mostly a sanity metric to ensure there's no bug that would make
 	// some subpool hog all the reservations due to mis-accounting.
 	reservationsGaugeName = "txpool/reservations"
+
+	// rejectedTxCounter counts the transactions rejected by the pool, labelled
+	// by the reason of the rejection.
+	rejectedTxCounter = metrics.NewRegisteredCounterVec("txpool/rejected", nil, "reason")
 )
 
+// rejectReasons maps the errors of rejected transactions to the reason label of
+// the rejection metric. Errors not listed are reported as "other".
+var rejectReasons = []struct {
+	err    error
+	reason string
+}{
+	{ErrAlreadyKnown, "known"},
+	{ErrInvalidSender, "invalid_sender"},
+	{ErrUnderpriced, "underpriced"},
+	{ErrReplaceUnderpriced, "replacement_underpriced"},
+	{ErrAccountLimitExceeded, "account_limit"},
+	{ErrGasLimit, "gas_limit"},
+	{ErrNegativeValue, "negative_value"},
+	{ErrOversizedData, "oversized"},
+	{ErrFutureReplacePending, "future_replace_pending"},
+	{ErrAlreadyReserved, "reserved"},
+	{core.ErrBlacklistAddr, "blacklist"},
+	{core.ErrNonceTooLow, "nonce_too_low"},
+	{core.ErrNonceTooHigh, "nonce_too_high"},
+	{core.ErrInsufficientFunds, "insufficient_funds"},
+	{core.ErrIntrinsicGas, "intrinsic_gas"},
+	{core.ErrTxTypeNotSupported, "type_not_supported"},
+	{core.ErrTipAboveFeeCap, "tip_above_fee_cap"},
+	{core.ErrTipVeryHigh, "tip_very_high"},
+	{core.ErrFeeCapVeryHigh, "fee_cap_very_high"},
+	{core.ErrMaxInitCodeSizeExceeded, "init_code_size"},
+}
+
+// rejectReason returns the reason label of a transaction rejection error.
+func rejectReason(err error) string {
+	for _, r := range rejectReasons {
+		if errors.Is(err, r.err) {
+			return r.reason
+		}
+	}
+	return "other"
+}
+
 // BlockChain defines the minimal set of methods needed to back a tx pool with
 // a chain. Exists to allow mocking the live chain out of tests.
 type BlockChain interface {
@@ -348,6 +390,11 @@ func (p *TxPool) Add(txs []*types.Transaction, local bool, sync bool) []error {
 		errs[i] = errsets[split][0]
 		errsets[split] = errsets[split][1:]
 	}
+	for _, err := range errs {
+		if err != nil {
+			rejectedTxCounter.With(rejectReason(err)).Inc(1)
+		}
+	}
 	return errs
 }
 
@@ -44,9 +44,10 @@ var (
 type ValidationOptions struct {
 	Config *params.ChainConfig // Chain configuration to selectively validate based on current fork rules
 
-	Accept  uint8    // Bitmap of transaction types that should be accepted for the calling pool
-	MaxSize uint64   // Maximum size of a transaction that the caller can meaningfully handle
-	MinTip  *big.Int // Minimum gas tip needed to allow a transaction into the caller pool
+	Accept          uint8    // Bitmap of transaction types that should be accepted for the calling pool
+	AcceptSponsored bool     // Whether sponsored transactions, outside of the bitmap range, are accepted
+	MaxSize         uint64   // Maximum size of a transaction that the caller can meaningfully handle
+	MinTip          *big.Int // Minimum gas tip needed to allow a transaction into the caller pool
 }
 
 // ValidateTransaction is a helper method to check whether a transaction is valid
@@ -57,7 +58,11 @@ type ValidationOptions struct {
 // rules without duplicating code and running the risk of missed updates.
 func ValidateTransaction(tx *types.Transaction, head *types.Header, signer types.Signer, opts *ValidationOptions) error {
 	// Ensure transactions not implemented by the calling pool are rejected
-	if opts.Accept&(1<<tx.Type()) == 0 {
+	if tx.Type() == types.SponsoredTxType {
+		if !opts.AcceptSponsored {
+			return fmt.Errorf("%w: tx type %v not supported by this pool", core.ErrTxTypeNotSupported, tx.Type())
+		}
+	} else if opts.Accept&(1<<tx.Type()) == 0 {
 		return fmt.Errorf("%w: tx type %v not supported by this pool", core.ErrTxTypeNotSupported, tx.Type())
 	}
 	// Before performing any expensive validations, sanity check that the tx is
@@ -76,6 +81,15 @@ func ValidateTransaction(tx *types.Transaction, head *types.Header, signer types
 	if !opts.Config.IsCancun(head.Number, head.Time) && tx.Type() == types.BlobTxType {
 		return fmt.Errorf("%w: type %d rejected, pool not yet in Cancun", core.ErrTxTypeNotSupported, tx.Type())
 	}
+	if opts.Config.IsBlobDisabled(head.Number, head.Time) && tx.Type() == types.BlobTxType {
+		return core.ErrBlobTxsDisabled
+	}
+	if !rules.IsPrague && tx.Type() == types.SetCodeTxType {
+		return fmt.Errorf("%w: type %d rejected, pool not yet in Prague", core.ErrTxTypeNotSupported, tx.Type())
+	}
+	if !rules.IsPaymaster && tx.Type() == types.SponsoredTxType {
+		return fmt.Errorf("%w: type %d rejected, pool not yet in the paymaster fork", core.ErrTxTypeNotSupported, tx.Type())
+	}
 	// Check whether the init code size has been exceeded
 	if opts.Config.IsShanghai(head.Number, head.Time) && tx.To() == nil && len(tx.Data()) > params.MaxInitCodeSize {
 		return fmt.Errorf("%w: code size %v, limit %v", core.ErrMaxInitCodeSizeExceeded, len(tx.Data()), params.MaxInitCodeSize)
@@ -112,12 +126,13 @@ func ValidateTransaction(tx *types.Transaction, head *types.Header, signer types
 			toAddress = tx.To()
 		}
 		if params.InBlacklistV1(from, *toAddress) {
+			core.BlacklistRejectedCounter.With("txpool").Inc(1)
 			return core.ErrBlacklistAddr
 		}
 	}
 	// Ensure the transaction has more gas than the bare minimum needed to cover
 	// the transaction metadata
-	intrGas, err := core.IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, true, opts.Config.IsIstanbul(head.Number), opts.Config.IsShanghai(head.Number, head.Time))
+	intrGas, err := core.IntrinsicGas(tx.Data(), tx.AccessList(), tx.SetCodeAuthorizations(), tx.To() == nil, true, opts.Config.IsIstanbul(head.Number), opts.Config.IsShanghai(head.Number, head.Time), opts.Config.GasScheduleAt(head.Time))
 	if err != nil {
 		return err
 	}
@@ -154,6 +169,11 @@ func ValidateTransaction(tx *types.Transaction, head *types.Header, signer types
 			return err
 		}
 	}
+	if tx.Type() == types.SetCodeTxType {
+		if len(tx.SetCodeAuthorizations()) == 0 {
+			return fmt.Errorf("set code tx must have at least one authorization tuple")
+		}
+	}
 	return nil
 }
 
@@ -209,6 +229,22 @@ type ValidationOptionsWithState struct {
 	// ExistingCost is a mandatory callback to retrieve an already pooled
 	// transaction's cost with the given nonce to check for overdrafts.
 	ExistingCost func(addr common.Address, nonce uint64) *big.Int
+
+	// ExistingPaymasterExpenditure is a callback to retrieve the cumulative gas
+	// cost sponsored by a paymaster for the already pooled transactions to check
+	// for paymaster overdrafts. It is mandatory if sponsored transactions are
+	// accepted.
+	ExistingPaymasterExpenditure func(paymaster common.Address) *big.Int
+
+	// ExistingPaymasterCost is a callback to retrieve the gas cost sponsored by
+	// a paymaster for an already pooled transaction with the given nonce. It is
+	// mandatory if sponsored transactions are accepted.
+	ExistingPaymasterCost func(addr common.Address, nonce uint64, paymaster common.Address) *big.Int
+
+	// ValidatePaymaster is an optional callback to simulate the approval of
+	// a sponsored transaction by its paymaster. If this method is not set,
+	// sponsored transactions are only checked against the paymaster balance.
+	ValidatePaymaster func(tx *types.Transaction) error
 }
 
 // ValidateTransactionWithState is a helper method to check whether a transaction
@@ -247,8 +283,44 @@ func ValidateTransactionWithState(tx *types.Transaction, signer types.Signer, op
 	}
 	// Check if transaction is in blacklist
 	if core.IsAddressBlacklisted(from, tx.To()) {
+		core.BlacklistRejectedCounter.With("txpool").Inc(1)
 		return core.ErrBlacklistAddr
 	}
+	for _, auth := range tx.SetCodeAuthorizations() {
+		if authority, err := auth.Authority(); err == nil && core.IsAddressBlacklisted(authority, &auth.Address) {
+			core.BlacklistRejectedCounter.With("txpool").Inc(1)
+			return core.ErrBlacklistAddr
+		}
+	}
+	// Ensure the paymaster of sponsored transactions can and agrees to pay for
+	// the gas of the transaction
+	if paymaster := tx.Paymaster(); paymaster != nil {
+		if core.IsAddressBlacklisted(*paymaster, nil) {
+			core.BlacklistRejectedCounter.With("txpool").Inc(1)
+			return core.ErrBlacklistAddr
+		}
+		var (
+			balance = opts.State.GetBalance(*paymaster).ToBig()
+			cost    = tx.PaymasterCost()
+		)
+		if balance.Cmp(cost) < 0 {
+			return fmt.Errorf("%w: paymaster %v balance %v, tx gas cost %v", core.ErrInsufficientFunds, paymaster.Hex(), balance, cost)
+		}
+		// Ensure the paymaster can also cover the gas of the transactions it
+		// already sponsors in the pool, accounting for replacements
+		need := new(big.Int).Add(opts.ExistingPaymasterExpenditure(*paymaster), cost)
+		if prev := opts.ExistingPaymasterCost(from, tx.Nonce(), *paymaster); prev != nil {
+			need.Sub(need, prev)
+		}
+		if balance.Cmp(need) < 0 {
+			return fmt.Errorf("%w: paymaster %v balance %v, sponsored cost %v, overshot %v", core.ErrInsufficientFunds, paymaster.Hex(), balance, need, new(big.Int).Sub(need, balance))
+		}
+		if opts.ValidatePaymaster != nil {
+			if err := opts.ValidatePaymaster(tx); err != nil {
+				return err
+			}
+		}
+	}
 	// Ensure the transactor has enough funds to cover for replacements or nonce
 	// expansions without overdrafts
 	spent := opts.ExistingExpenditure(from)
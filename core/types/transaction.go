@@ -49,6 +49,11 @@ const (
 	AccessListTxType = 0x01
 	DynamicFeeTxType = 0x02
 	BlobTxType       = 0x03
+	SetCodeTxType    = 0x04
+
+	// SponsoredTxType is the JuChain paymaster sponsored transaction, placed
+	// away from the Ethereum type range to avoid future collisions.
+	SponsoredTxType = 0x70
 )
 
 // Transaction is an Ethereum transaction.
@@ -206,6 +211,10 @@ func (tx *Transaction) decodeTyped(b []byte) (TxData, error) {
 		inner = new(DynamicFeeTx)
 	case BlobTxType:
 		inner = new(BlobTx)
+	case SetCodeTxType:
+		inner = new(SetCodeTx)
+	case SponsoredTxType:
+		inner = new(SponsoredTx)
 	default:
 		return nil, ErrTxTypeNotSupported
 	}
@@ -309,8 +318,12 @@ func (tx *Transaction) To() *common.Address {
 	return copyAddressPtr(tx.inner.to())
 }
 
-// Cost returns (gas * gasPrice) + (blobGas * blobGasPrice) + value.
+// Cost returns (gas * gasPrice) + (blobGas * blobGasPrice) + value. The gas of
+// sponsored transactions is paid by the paymaster, their cost is the value.
 func (tx *Transaction) Cost() *big.Int {
+	if tx.Type() == SponsoredTxType {
+		return tx.Value()
+	}
 	total := new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(tx.Gas()))
 	if tx.Type() == BlobTxType {
 		total.Add(total, new(big.Int).Mul(tx.BlobGasFeeCap(), new(big.Int).SetUint64(tx.BlobGas())))
@@ -446,6 +459,58 @@ func (tx *Transaction) WithoutBlobTxSidecar() *Transaction {
 	return cpy
 }
 
+// SetCodeAuthorizations returns the authorizations list of the transaction.
+func (tx *Transaction) SetCodeAuthorizations() []SetCodeAuthorization {
+	setcodetx, ok := tx.inner.(*SetCodeTx)
+	if !ok {
+		return nil
+	}
+	return setcodetx.AuthList
+}
+
+// Paymaster returns the paymaster paying the gas of a sponsored transaction,
+// or nil for other transaction types.
+func (tx *Transaction) Paymaster() *common.Address {
+	sponsoredtx, ok := tx.inner.(*SponsoredTx)
+	if !ok {
+		return nil
+	}
+	paymaster := sponsoredtx.Paymaster
+	return &paymaster
+}
+
+// PaymasterCost returns the gas cost the paymaster of a sponsored transaction
+// commits to pay at most (gas * gasFeeCap), or nil for other transaction types.
+func (tx *Transaction) PaymasterCost() *big.Int {
+	if tx.Type() != SponsoredTxType {
+		return nil
+	}
+	return new(big.Int).Mul(tx.GasFeeCap(), new(big.Int).SetUint64(tx.Gas()))
+}
+
+// SetCodeAuthorities returns a list of unique authorities from the
+// authorization list.
+func (tx *Transaction) SetCodeAuthorities() []common.Address {
+	setcodetx, ok := tx.inner.(*SetCodeTx)
+	if !ok {
+		return nil
+	}
+	var (
+		marks = make(map[common.Address]bool)
+		auths = make([]common.Address, 0, len(setcodetx.AuthList))
+	)
+	for _, auth := range setcodetx.AuthList {
+		if addr, err := auth.Authority(); err == nil {
+			if marks[addr] {
+				continue
+			}
+			marks[addr] = true
+			auths = append(auths, addr)
+		}
+	}
+	return auths
+}
+
 // SetTime sets the decoding time of a transaction. This is used by tests to set
 // arbitrary times and by persistent transaction pools when loading old txs from
 // disk.
@@ -0,0 +1,143 @@
+// Copyright 2016 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// TestParseDelegation tests a few possible delegation designator values and
+// ensures they are parsed correctly.
+func TestParseDelegation(t *testing.T) {
+	addr := common.Address{0x42}
+	for _, tt := range []struct {
+		val  []byte
+		want *common.Address
+	}{
+		{ // simple correct delegation
+			val:  append(DelegationPrefix, addr.Bytes()...),
+			want: &addr,
+		},
+		{ // wrong address size
+			val: append(DelegationPrefix, addr.Bytes()[0:19]...),
+		},
+		{ // short address
+			val: append(DelegationPrefix, 0x42),
+		},
+		{ // long address
+			val: append(append(DelegationPrefix, addr.Bytes()...), 0x42),
+		},
+		{ // wrong prefix size
+			val: append(DelegationPrefix[:2], addr.Bytes()...),
+		},
+		{ // wrong prefix
+			val: append([]byte{0xef, 0x01, 0x01}, addr.Bytes()...),
+		},
+		{ // wrong prefix
+			val: append([]byte{0xef, 0x00, 0x00}, addr.Bytes()...),
+		},
+		{ // no prefix
+			val: addr.Bytes(),
+		},
+		{ // no address
+			val: DelegationPrefix,
+		},
+	} {
+		got, ok := ParseDelegation(tt.val)
+		if ok && tt.want == nil {
+			t.Fatalf("expected fail, got %s", got.Hex())
+		}
+		if !ok && tt.want != nil {
+			t.Fatalf("failed to parse, want %s", tt.want.Hex())
+		}
+	}
+}
+
+// TestSetCodeTxSigning checks that set code transactions are only accepted by
+// the signer once Prague is active, and that they survive the binary and JSON
+// encodings with their authorizations intact.
+func TestSetCodeTxSigning(t *testing.T) {
+	var (
+		key, _  = crypto.GenerateKey()
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		chainID = big.NewInt(210000)
+		config  = &params.ChainConfig{ChainID: chainID, LondonBlock: common.Big0, PragueTime: new(uint64)}
+		signer  = LatestSigner(config)
+	)
+	auth, err := SignSetCode(key, SetCodeAuthorization{
+		ChainID: *uint256.MustFromBig(chainID),
+		Address: common.Address{0xaa},
+		Nonce:   1,
+	})
+	if err != nil {
+		t.Fatalf("failed to sign authorization: %v", err)
+	}
+	tx, err := SignNewTx(key, signer, &SetCodeTx{
+		ChainID:   uint256.MustFromBig(chainID),
+		To:        common.Address{0xbb},
+		Gas:       100000,
+		GasFeeCap: uint256.NewInt(10),
+		GasTipCap: uint256.NewInt(1),
+		AuthList:  []SetCodeAuthorization{auth},
+	})
+	if err != nil {
+		t.Fatalf("failed to sign tx: %v", err)
+	}
+	if from, err := Sender(signer, tx); err != nil || from != addr {
+		t.Fatalf("wrong sender: have %x (%v), want %x", from, err, addr)
+	}
+	if _, err := Sender(NewCancunSigner(chainID), tx); err == nil {
+		t.Fatal("pre-Prague signer accepted set code tx")
+	}
+	if authorities := tx.SetCodeAuthorities(); len(authorities) != 1 || authorities[0] != addr {
+		t.Fatalf("wrong authorities: have %x, want [%x]", authorities, addr)
+	}
+	// Round trip the binary encoding
+	enc, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to encode tx: %v", err)
+	}
+	var dec Transaction
+	if err := dec.UnmarshalBinary(enc); err != nil {
+		t.Fatalf("failed to decode tx: %v", err)
+	}
+	if dec.Hash() != tx.Hash() {
+		t.Fatalf("binary round trip changed hash: have %x, want %x", dec.Hash(), tx.Hash())
+	}
+	// Round trip the JSON encoding
+	js, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatalf("failed to encode tx to JSON: %v", err)
+	}
+	var jdec Transaction
+	if err := json.Unmarshal(js, &jdec); err != nil {
+		t.Fatalf("failed to decode tx from JSON: %v", err)
+	}
+	if jdec.Hash() != tx.Hash() {
+		t.Fatalf("JSON round trip changed hash: have %x, want %x", jdec.Hash(), tx.Hash())
+	}
+	if auths := jdec.SetCodeAuthorizations(); len(auths) != 1 || auths[0] != auth {
+		t.Fatalf("JSON round trip changed authorizations: have %+v, want [%+v]", auths, auth)
+	}
+}
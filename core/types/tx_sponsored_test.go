@@ -0,0 +1,102 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestSponsoredTxSigning(t *testing.T) {
+	var (
+		key, _    = crypto.GenerateKey()
+		addr      = crypto.PubkeyToAddress(key.PublicKey)
+		chainID   = big.NewInt(210000)
+		paymaster = common.Address{0xcc}
+		config    = &params.ChainConfig{ChainID: chainID, LondonBlock: common.Big0, PragueTime: new(uint64), PaymasterTime: new(uint64)}
+		signer    = LatestSigner(config)
+	)
+	tx, err := SignNewTx(key, signer, &SponsoredTx{
+		ChainID:   chainID,
+		To:        &common.Address{0xbb},
+		Gas:       100000,
+		GasFeeCap: big.NewInt(10),
+		GasTipCap: big.NewInt(1),
+		Value:     big.NewInt(5),
+		Paymaster: paymaster,
+	})
+	if err != nil {
+		t.Fatalf("failed to sign tx: %v", err)
+	}
+	if from, err := Sender(signer, tx); err != nil || from != addr {
+		t.Fatalf("wrong sender: have %x (%v), want %x", from, err, addr)
+	}
+	if _, err := Sender(NewPragueSigner(chainID), tx); err == nil {
+		t.Fatal("pre-paymaster signer accepted sponsored tx")
+	}
+	if have := tx.Paymaster(); have == nil || *have != paymaster {
+		t.Fatalf("wrong paymaster: have %v, want %x", have, paymaster)
+	}
+	if cost := tx.Cost(); cost.Cmp(big.NewInt(5)) != 0 {
+		t.Fatalf("wrong cost: have %v, want 5", cost)
+	}
+	// The paymaster is part of the signed payload
+	other, _ := SignNewTx(key, signer, &SponsoredTx{
+		ChainID:   chainID,
+		To:        &common.Address{0xbb},
+		Gas:       100000,
+		GasFeeCap: big.NewInt(10),
+		GasTipCap: big.NewInt(1),
+		Value:     big.NewInt(5),
+		Paymaster: common.Address{0xdd},
+	})
+	if signer.Hash(tx) == signer.Hash(other) {
+		t.Fatal("signing hash doesn't cover the paymaster")
+	}
+	// Round trip the binary encoding
+	enc, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to encode tx: %v", err)
+	}
+	var dec Transaction
+	if err := dec.UnmarshalBinary(enc); err != nil {
+		t.Fatalf("failed to decode tx: %v", err)
+	}
+	if dec.Hash() != tx.Hash() {
+		t.Fatalf("binary round trip changed hash: have %x, want %x", dec.Hash(), tx.Hash())
+	}
+	// Round trip the JSON encoding
+	js, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatalf("failed to encode tx to JSON: %v", err)
+	}
+	var jdec Transaction
+	if err := json.Unmarshal(js, &jdec); err != nil {
+		t.Fatalf("failed to decode tx from JSON: %v", err)
+	}
+	if jdec.Hash() != tx.Hash() {
+		t.Fatalf("JSON round trip changed hash: have %x, want %x", jdec.Hash(), tx.Hash())
+	}
+	if have := jdec.Paymaster(); have == nil || *have != paymaster {
+		t.Fatalf("JSON round trip changed paymaster: have %v, want %x", have, paymaster)
+	}
+}
@@ -16,6 +16,21 @@
 
 package vm
 
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+)
+
+// analysisCacheSize is the maximum total size of the cached code bitmaps. A
+// bitmap takes one byte per eight bytes of code, so the cache holds the
+// analysis of a few thousand contracts of the maximum code size.
+const analysisCacheSize = 16 * 1024 * 1024
+
+// analysisCache holds the JUMPDEST analysis of deployed code by code hash. It is
+// shared across EVM instances, so that the analysis of frequently called
+// contracts is done once instead of once per transaction.
+var analysisCache = lru.NewSizeConstrainedCache[common.Hash, bitvec](analysisCacheSize)
+
 const (
 	set2BitsMask = uint16(0b11)
 	set3BitsMask = uint16(0b111)
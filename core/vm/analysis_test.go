@@ -20,7 +20,9 @@ import (
 	"math/bits"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
 )
 
 func TestJumpDestAnalysis(t *testing.T) {
@@ -59,6 +61,29 @@ func TestJumpDestAnalysis(t *testing.T) {
 	}
 }
 
+// TestJumpDestAnalysisCache tests that the analysis of deployed code is shared
+// across contracts which don't share a parent context.
+func TestJumpDestAnalysisCache(t *testing.T) {
+	var (
+		code = []byte{byte(PUSH1), byte(JUMPDEST), byte(JUMPDEST)}
+		hash = crypto.Keccak256Hash(code)
+		addr = common.Address{0x01}
+	)
+	for i := 0; i < 2; i++ {
+		contract := NewContract(AccountRef(common.Address{}), AccountRef(addr), new(uint256.Int), 0)
+		contract.SetCallCode(&addr, hash, code)
+		if contract.validJumpdest(uint256.NewInt(1)) {
+			t.Fatalf("contract %d: push data accepted as jump destination", i)
+		}
+		if !contract.validJumpdest(uint256.NewInt(2)) {
+			t.Fatalf("contract %d: jump destination rejected", i)
+		}
+		if _, ok := analysisCache.Get(hash); !ok {
+			t.Fatalf("contract %d: analysis not cached", i)
+		}
+	}
+}
+
 const analysisCodeSize = 1200 * 1024
 
 func BenchmarkJumpdestAnalysis_1200k(bench *testing.B) {
@@ -108,9 +108,13 @@ func (c *Contract) isCode(udest uint64) bool {
 		// Does parent context have the analysis?
 		analysis, exist := c.jumpdests[c.CodeHash]
 		if !exist {
-			// Do the analysis and save in parent context
-			// We do not need to store it in c.analysis
-			analysis = codeBitmap(c.Code)
+			// Check the cache shared across transactions, do the analysis
+			// only if the code hasn't been seen lately, and save it in the
+			// parent context. We do not need to store it in c.analysis
+			if analysis, exist = analysisCache.Get(c.CodeHash); !exist {
+				analysis = codeBitmap(c.Code)
+				analysisCache.Add(c.CodeHash, analysis)
+			}
 			c.jumpdests[c.CodeHash] = analysis
 		}
 		// Also stash it in current contract for faster access
@@ -17,6 +17,11 @@
 package vm
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -42,3 +47,36 @@ func FuzzPrecompiledContracts(f *testing.F) {
 		}
 	})
 }
+
+// FuzzPrecompiledP256Verify checks that the P256VERIFY precompile accepts the
+// signatures of random messages, and rejects them once a bit of the input is
+// flipped.
+func FuzzPrecompiledP256Verify(f *testing.F) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add([]byte("hello"), uint8(0), uint8(0))
+	f.Fuzz(func(t *testing.T, msg []byte, pos uint8, bit uint8) {
+		hash := sha256.Sum256(msg)
+		r, s, err := ecdsa.Sign(rand.Reader, key, hash[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		input := make([]byte, 160)
+		copy(input, hash[:])
+		r.FillBytes(input[32:64])
+		s.FillBytes(input[64:96])
+		key.X.FillBytes(input[96:128])
+		key.Y.FillBytes(input[128:160])
+
+		p := &p256Verify{}
+		if res, err := p.Run(input); err != nil || !bytes.Equal(res, common.LeftPadBytes([]byte{1}, 32)) {
+			t.Fatalf("valid signature rejected: res %x, err %v", res, err)
+		}
+		input[int(pos)%len(input)] ^= 1 << (bit % 8)
+		if res, err := p.Run(input); err != nil || len(res) != 0 {
+			t.Fatalf("invalid signature accepted: res %x, err %v", res, err)
+		}
+	})
+}
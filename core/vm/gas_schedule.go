@@ -0,0 +1,152 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// storageGas are the gas constants of the storage opcodes since EIP-2200.
+type storageGas struct {
+	sload        uint64 // SLOAD_GAS of EIP-2200, the warm read cost replaces it in EIP-2929
+	coldSload    uint64 // COLD_SLOAD_COST of EIP-2929
+	warmRead     uint64 // WARM_STORAGE_READ_COST of EIP-2929
+	sstoreSet    uint64 // SSTORE_SET_GAS
+	sstoreReset  uint64 // SSTORE_RESET_GAS
+	clearsRefund uint64 // SSTORE_CLEARS_SCHEDULE
+}
+
+var (
+	// storageGasEIP2200 are the storage gas constants of EIP-2200 and EIP-2929.
+	storageGasEIP2200 = storageGas{
+		sload:        params.SloadGasEIP2200,
+		coldSload:    params.ColdSloadCostEIP2929,
+		warmRead:     params.WarmStorageReadCostEIP2929,
+		sstoreSet:    params.SstoreSetGasEIP2200,
+		sstoreReset:  params.SstoreResetGasEIP2200,
+		clearsRefund: params.SstoreClearsScheduleRefundEIP2200,
+	}
+	// storageGasEIP3529 are the storage gas constants with the refunds reduced
+	// by EIP-3529.
+	storageGasEIP3529 = storageGas{
+		sload:        params.SloadGasEIP2200,
+		coldSload:    params.ColdSloadCostEIP2929,
+		warmRead:     params.WarmStorageReadCostEIP2929,
+		sstoreSet:    params.SstoreSetGasEIP2200,
+		sstoreReset:  params.SstoreResetGasEIP2200,
+		clearsRefund: params.SstoreClearsScheduleRefundEIP3529,
+	}
+)
+
+// newStorageGas returns the storage gas constants of the given base, with the
+// overrides of the gas schedule applied.
+func newStorageGas(base storageGas, schedule *params.GasSchedule) storageGas {
+	return storageGas{
+		sload:        schedule.StorageGas(params.StorageSloadGas, base.sload),
+		coldSload:    schedule.StorageGas(params.StorageColdSloadCost, base.coldSload),
+		warmRead:     schedule.StorageGas(params.StorageWarmStorageReadCost, base.warmRead),
+		sstoreSet:    schedule.StorageGas(params.StorageSstoreSetGas, base.sstoreSet),
+		sstoreReset:  schedule.StorageGas(params.StorageSstoreResetGas, base.sstoreReset),
+		clearsRefund: schedule.StorageGas(params.StorageSstoreClearsScheduleRefund, base.clearsRefund),
+	}
+}
+
+// validate checks that the refunds of restored slots can't underflow.
+func (gas storageGas) validate() error {
+	switch {
+	case gas.sstoreSet < gas.sload || gas.sstoreReset < gas.sload:
+		return fmt.Errorf("SSTORE set (%d) and reset (%d) gas below SLOAD gas (%d)", gas.sstoreSet, gas.sstoreReset, gas.sload)
+	case gas.sstoreSet < gas.warmRead || gas.sstoreReset < gas.coldSload+gas.warmRead:
+		return fmt.Errorf("SSTORE set (%d) and reset (%d) gas below the cold (%d) and warm (%d) storage access costs", gas.sstoreSet, gas.sstoreReset, gas.coldSload, gas.warmRead)
+	}
+	return nil
+}
+
+// ValidateGasSchedule checks that a gas schedule only overrides known opcodes,
+// precompiled contracts and intrinsic and storage gas constants. Opcodes with a
+// dynamic gas cost in any fork, such as SLOAD, SSTORE and the CALL family, can't
+// be overridden, as their constant gas is only a part of their cost. The costs
+// of the storage opcodes are overridden with the storage gas constants instead.
+func ValidateGasSchedule(schedule *params.GasSchedule) error {
+	if err := schedule.Validate(); err != nil {
+		return err
+	}
+	for name := range schedule.Opcodes {
+		op, ok := stringToOp[name]
+		if !ok {
+			return fmt.Errorf("gas schedule at timestamp %d: unknown opcode %q", schedule.Time, name)
+		}
+		if pragueInstructionSet[op].dynamicGas != nil {
+			return fmt.Errorf("gas schedule at timestamp %d: opcode %q has a dynamic gas cost", schedule.Time, name)
+		}
+	}
+	for addr := range schedule.Precompiles {
+		_, ok := PrecompiledContractsPrague[addr]
+		if !ok {
+			_, ok = PrecompiledContractsP256Verify[addr]
+		}
+		if !ok {
+			return fmt.Errorf("gas schedule at timestamp %d: %v is not a precompiled contract", schedule.Time, addr)
+		}
+	}
+	if err := newStorageGas(storageGasEIP2200, schedule).validate(); err != nil {
+		return fmt.Errorf("gas schedule at timestamp %d: %v", schedule.Time, err)
+	}
+	if err := newStorageGas(storageGasEIP3529, schedule).validate(); err != nil {
+		return fmt.Errorf("gas schedule at timestamp %d: %v", schedule.Time, err)
+	}
+	return nil
+}
+
+// applyGasSchedule overrides the constant gas of the opcodes named by the gas
+// schedule, and the gas of the storage opcodes of the forks since Istanbul. The
+// jump table must be a copy, not one of the shared tables.
+func applyGasSchedule(jt *JumpTable, schedule *params.GasSchedule, rules params.Rules) {
+	for name, gas := range schedule.Opcodes {
+		jt[StringToOp(name)].constantGas = gas
+	}
+	if len(schedule.Storage) == 0 {
+		return
+	}
+	switch {
+	case rules.IsLondon:
+		gas := newStorageGas(storageGasEIP3529, schedule)
+		jt[SLOAD].dynamicGas = makeGasSLoadFunc(gas)
+		jt[SSTORE].dynamicGas = makeGasSStoreFunc(gas)
+	case rules.IsBerlin:
+		gas := newStorageGas(storageGasEIP2200, schedule)
+		jt[SLOAD].dynamicGas = makeGasSLoadFunc(gas)
+		jt[SSTORE].dynamicGas = makeGasSStoreFunc(gas)
+	case rules.IsIstanbul:
+		gas := newStorageGas(storageGasEIP2200, schedule)
+		jt[SLOAD].constantGas = gas.sload
+		jt[SSTORE].dynamicGas = makeGasSStoreEIP2200Func(gas)
+	}
+}
+
+// fixedGasPrecompile wraps a precompiled contract whose gas cost is overridden
+// by the gas schedule.
+type fixedGasPrecompile struct {
+	PrecompiledContract
+	gas uint64
+}
+
+func (p *fixedGasPrecompile) RequiredGas(input []byte) uint64 {
+	return p.gas
+}
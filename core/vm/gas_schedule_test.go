@@ -0,0 +1,160 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGasSchedule(t *testing.T) {
+	config := *params.TestChainConfig
+	config.GasSchedules = []*params.GasSchedule{{
+		Time:        10,
+		Opcodes:     map[string]uint64{"ADD": 1, "MUL": 20},
+		Precompiles: map[common.Address]uint64{common.BytesToAddress([]byte{2}): 5},
+	}}
+	require.NoError(t, ValidateGasSchedule(config.GasSchedules[0]))
+
+	// Before the activation, the shared tables and the protocol costs are used
+	evm := NewEVM(BlockContext{BlockNumber: new(big.Int), Time: 9}, TxContext{}, nil, &config, Config{})
+	require.Equal(t, &londonInstructionSet, evm.interpreter.table)
+	p, ok := evm.precompile(common.BytesToAddress([]byte{2}))
+	require.True(t, ok)
+	require.Equal(t, params.Sha256BaseGas, p.RequiredGas(nil))
+
+	// After the activation, the overrides are applied to a copy of the table
+	evm = NewEVM(BlockContext{BlockNumber: new(big.Int), Time: 10}, TxContext{}, nil, &config, Config{})
+	require.Equal(t, uint64(1), evm.interpreter.table[ADD].constantGas)
+	require.Equal(t, uint64(20), evm.interpreter.table[MUL].constantGas)
+	require.Equal(t, GasFastestStep, evm.interpreter.table[SUB].constantGas)
+	require.Equal(t, GasFastestStep, londonInstructionSet[ADD].constantGas)
+
+	p, ok = evm.precompile(common.BytesToAddress([]byte{2}))
+	require.True(t, ok)
+	require.Equal(t, uint64(5), p.RequiredGas(make([]byte, 1024)))
+	p, ok = evm.precompile(common.BytesToAddress([]byte{1}))
+	require.True(t, ok)
+	require.Equal(t, params.EcrecoverGas, p.RequiredGas(nil))
+}
+
+// Tests that the overridden costs are the ones charged by the executed code.
+func TestGasScheduleExecution(t *testing.T) {
+	config := *params.TestChainConfig
+	config.GasSchedules = []*params.GasSchedule{{
+		Time:    10,
+		Opcodes: map[string]uint64{"ADD": 1, "MUL": 20},
+	}}
+	var (
+		addr = common.Address{0x01}
+		// PUSH1 2, PUSH1 3, ADD, PUSH1 4, MUL, POP, STOP
+		code = []byte{byte(PUSH1), 2, byte(PUSH1), 3, byte(ADD), byte(PUSH1), 4, byte(MUL), byte(POP), byte(STOP)}
+	)
+	for _, test := range []struct {
+		time uint64
+		want uint64
+	}{
+		{time: 9, want: 3*GasFastestStep + GasFastestStep + GasFastStep + GasQuickStep},
+		{time: 10, want: 3*GasFastestStep + 1 + 20 + GasQuickStep},
+	} {
+		statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		statedb.SetCode(addr, code)
+
+		evm := NewEVM(BlockContext{BlockNumber: new(big.Int), Time: test.time, Transfer: func(StateDB, common.Address, common.Address, *uint256.Int) {}}, TxContext{}, statedb, &config, Config{})
+		_, left, err := evm.Call(AccountRef(common.Address{}), addr, nil, 100000, new(uint256.Int))
+		require.NoError(t, err)
+		require.Equal(t, test.want, 100000-left, "time %d", test.time)
+	}
+}
+
+// Tests that the overridden storage gas constants are charged and refunded by
+// SLOAD and SSTORE, before and after EIP-2929.
+func TestGasScheduleStorage(t *testing.T) {
+	var (
+		addr = common.Address{0x01}
+		// PUSH1 1, PUSH1 0, SSTORE, PUSH1 0, SLOAD, POP, PUSH1 0, PUSH1 0, SSTORE, STOP
+		code = []byte{
+			byte(PUSH1), 1, byte(PUSH1), 0, byte(SSTORE), byte(PUSH1), 0, byte(SLOAD), byte(POP),
+			byte(PUSH1), 0, byte(PUSH1), 0, byte(SSTORE), byte(STOP),
+		}
+		istanbul = *params.TestChainConfig
+		london   = *params.TestChainConfig
+	)
+	istanbul.BerlinBlock, istanbul.LondonBlock = nil, nil
+	istanbul.ArrowGlacierBlock, istanbul.GrayGlacierBlock = nil, nil
+
+	for _, test := range []struct {
+		config *params.ChainConfig
+		time   uint64
+		gas    uint64
+		refund uint64
+	}{
+		{config: &istanbul, time: 9, gas: 5*GasFastestStep + GasQuickStep + params.SstoreSetGasEIP2200 + 2*params.SloadGasEIP2200, refund: params.SstoreSetGasEIP2200 - params.SloadGasEIP2200},
+		{config: &istanbul, time: 10, gas: 5*GasFastestStep + GasQuickStep + 5000 + 2*200, refund: 5000 - 200},
+		{config: &london, time: 9, gas: 5*GasFastestStep + GasQuickStep + params.ColdSloadCostEIP2929 + params.SstoreSetGasEIP2200 + 2*params.WarmStorageReadCostEIP2929, refund: params.SstoreSetGasEIP2200 - params.WarmStorageReadCostEIP2929},
+		{config: &london, time: 10, gas: 5*GasFastestStep + GasQuickStep + 1000 + 5000 + 2*50, refund: 5000 - 50},
+	} {
+		config := *test.config
+		config.GasSchedules = []*params.GasSchedule{{
+			Time: 10,
+			Storage: map[string]uint64{
+				params.StorageSloadGas:            200,
+				params.StorageColdSloadCost:       1000,
+				params.StorageWarmStorageReadCost: 50,
+				params.StorageSstoreSetGas:        5000,
+			},
+		}}
+		require.NoError(t, ValidateGasSchedule(config.GasSchedules[0]))
+
+		statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		statedb.SetCode(addr, code)
+		statedb.AddAddressToAccessList(addr)
+
+		evm := NewEVM(BlockContext{BlockNumber: new(big.Int), Time: test.time, Transfer: func(StateDB, common.Address, common.Address, *uint256.Int) {}}, TxContext{}, statedb, &config, Config{})
+		_, left, err := evm.Call(AccountRef(common.Address{}), addr, nil, 100000, new(uint256.Int))
+		require.NoError(t, err)
+		require.Equal(t, test.gas, 100000-left, "london %v, time %d", evm.chainRules.IsLondon, test.time)
+		require.Equal(t, test.refund, statedb.GetRefund(), "london %v, time %d", evm.chainRules.IsLondon, test.time)
+	}
+}
+
+func TestValidateGasSchedule(t *testing.T) {
+	for i, schedule := range []*params.GasSchedule{
+		{Opcodes: map[string]uint64{"FOO": 1}},
+		{Opcodes: map[string]uint64{"SLOAD": 50}},
+		{Opcodes: map[string]uint64{"SSTORE": 50}},
+		{Opcodes: map[string]uint64{"CALL": 50}},
+		{Opcodes: map[string]uint64{"MLOAD": 1}},
+		{Precompiles: map[common.Address]uint64{common.BytesToAddress([]byte{0xff}): 1}},
+		{Intrinsic: map[string]uint64{"FOO": 1}},
+		{Storage: map[string]uint64{"FOO": 1}},
+		{Storage: map[string]uint64{params.StorageSstoreSetGas: 10, params.StorageSloadGas: 20}},
+		{Storage: map[string]uint64{params.StorageSstoreResetGas: 2000}},
+	} {
+		if err := ValidateGasSchedule(schedule); err == nil {
+			t.Errorf("test %d: invalid schedule accepted", i)
+		}
+	}
+}
@@ -17,6 +17,8 @@
 package vm
 
 import (
+	"context"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -25,10 +27,11 @@ import (
 
 // Config are the configuration options for the Interpreter
 type Config struct {
-	Tracer                  EVMLogger // Opcode logger
-	NoBaseFee               bool      // Forces the EIP-1559 baseFee to 0 (needed for 0 price calls)
-	EnablePreimageRecording bool      // Enables recording of SHA3/keccak preimages
-	ExtraEips               []int     // Additional EIPS that are to be enabled
+	Tracer                  EVMLogger       // Opcode logger
+	NoBaseFee               bool            // Forces the EIP-1559 baseFee to 0 (needed for 0 price calls)
+	EnablePreimageRecording bool            // Enables recording of SHA3/keccak preimages
+	ExtraEips               []int           // Additional EIPS that are to be enabled
+	TraceContext            context.Context // Trace of the block processing the execution is part of (optional)
 }
 
 // ScopeContext contains the things that are per-call, such as stack and memory,
@@ -56,6 +59,8 @@ func NewEVMInterpreter(evm *EVM) *EVMInterpreter {
 	// If jump table was not initialised we set the default one.
 	var table *JumpTable
 	switch {
+	case evm.chainRules.IsPrague:
+		table = &pragueInstructionSet
 	case evm.chainRules.IsCancun:
 		table = &cancunInstructionSet
 	case evm.chainRules.IsShanghai:
@@ -82,7 +87,8 @@ func NewEVMInterpreter(evm *EVM) *EVMInterpreter {
 		table = &frontierInstructionSet
 	}
 	var extraEips []int
-	if len(evm.Config.ExtraEips) > 0 {
+	schedule := evm.chainRules.GasSchedule
+	if len(evm.Config.ExtraEips) > 0 || (schedule != nil && (len(schedule.Opcodes) > 0 || len(schedule.Storage) > 0)) {
 		// Deep-copy jumptable to prevent modification of opcodes in other tables
 		table = copyJumpTable(table)
 	}
@@ -95,6 +101,9 @@ func NewEVMInterpreter(evm *EVM) *EVMInterpreter {
 		}
 	}
 	evm.Config.ExtraEips = extraEips
+	if schedule != nil && (len(schedule.Opcodes) > 0 || len(schedule.Storage) > 0) {
+		applyGasSchedule(table, schedule, evm.chainRules)
+	}
 	return &EVMInterpreter{evm: evm, table: table}
 }
 
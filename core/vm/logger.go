@@ -41,3 +41,13 @@ type EVMLogger interface {
 	CaptureState(pc uint64, op OpCode, gas, cost uint64, scope *ScopeContext, rData []byte, depth int, err error)
 	CaptureFault(pc uint64, op OpCode, gas, cost uint64, scope *ScopeContext, depth int, err error)
 }
+
+// SystemCallLogger is an optional interface of the EVMLogger to trace the system
+// calls made on behalf of a transaction, like the validation of its paymaster.
+// Every system call is traced as a top call frame of its own in between the
+// CaptureSystemCallStart and CaptureSystemCallEnd calls. Loggers not
+// implementing it expect a single top call frame and don't see system calls.
+type SystemCallLogger interface {
+	CaptureSystemCallStart()
+	CaptureSystemCallEnd()
+}
@@ -913,3 +913,34 @@ func BenchmarkTracerStepVsCallFrame(b *testing.B) {
 	benchmarkNonModifyingCode(10000000, code, "tracer-step-10M", stepTracer, b)
 	benchmarkNonModifyingCode(10000000, code, "tracer-call-frame-10M", callFrameTracer, b)
 }
+
+// BenchmarkTokenTransfer benchmarks the transfer method of a token contract. Every
+// call runs in a new EVM, reusing the cached JUMPDEST analysis of the code.
+func BenchmarkTokenTransfer(b *testing.B) {
+	// contract Token {
+	//     event Transfer(address indexed from, address indexed to, uint256 value);
+	//     function transfer(address to, uint256 value) public returns (bool) {
+	//         emit Transfer(msg.sender, to, value);
+	//         return true;
+	//     }
+	// }
+	code := common.FromHex("0x608060405234801561001057600080fd5b506004361061002b5760003560e01c8063a9059cbb14610030575b600080fd5b61004a6004803603810190610045919061016a565b610060565b60405161005791906101c5565b60405180910390f35b60008273ffffffffffffffffffffffffffffffffffffffff163373ffffffffffffffffffffffffffffffffffffffff167fddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef846040516100bf91906101ef565b60405180910390a36001905092915050565b600080fd5b600073ffffffffffffffffffffffffffffffffffffffff82169050919050565b6000610101826100d6565b9050919050565b610111816100f6565b811461011c57600080fd5b50565b60008135905061012e81610108565b92915050565b6000819050919050565b61014781610134565b811461015257600080fd5b50565b6000813590506101648161013e565b92915050565b60008060408385031215610181576101806100d1565b5b600061018f8582860161011f565b92505060206101a085828601610155565b9150509250929050565b60008115159050919050565b6101bf816101aa565b82525050565b60006020820190506101da60008301846101b6565b92915050565b6101e981610134565b82525050565b600060208201905061020460008301846101e0565b9291505056fea2646970667358221220b469033f4b77b9565ee84e0a2f04d496b18160d26034d54f9487e57788fd36d564736f6c63430008120033")
+	input := common.FromHex("0xa9059cbb000000000000000000000000000000000000000000000000000000000000beef00000000000000000000000000000000000000000000000000000000000003e8")
+
+	var (
+		address    = common.BytesToAddress([]byte("token"))
+		statedb, _ = state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		cfg        = &Config{State: statedb}
+	)
+	statedb.SetCode(address, code)
+	if ret, _, err := Call(address, input, cfg); err != nil || new(big.Int).SetBytes(ret).Cmp(common.Big1) != 0 {
+		b.Fatalf("transfer failed: ret %x, err %v", ret, err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		snapshot := statedb.Snapshot()
+		Call(address, input, cfg)
+		statedb.RevertToSnapshot(snapshot)
+	}
+}
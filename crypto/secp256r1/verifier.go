@@ -0,0 +1,37 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package secp256r1 implements signature verification on the secp256r1 (P-256)
+// curve, as needed by the RIP-7212 precompile.
+package secp256r1
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"math/big"
+)
+
+// Verify checks the signature (r, s) of the given hash by the public key with
+// the coordinates (x, y). It returns false if the public key isn't a point of
+// the curve, or if r or s are out of range.
+func Verify(hash []byte, r, s, x, y *big.Int) bool {
+	curve := elliptic.P256()
+	if x == nil || y == nil || !curve.IsOnCurve(x, y) {
+		return false
+	}
+	key := &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+	return ecdsa.Verify(key, hash, r, s)
+}
@@ -37,6 +37,7 @@ import (
 	"github.com/ethereum/go-ethereum/eth/tracers"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/miner"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -143,11 +144,30 @@ func (b *EthAPIBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumbe
 		}
 		return b.eth.blockchain.GetBlock(header.Hash(), header.Number.Uint64()), nil
 	}
-	return b.eth.blockchain.GetBlockByNumber(uint64(number)), nil
+	block := b.eth.blockchain.GetBlockByNumber(uint64(number))
+	if block == nil {
+		return nil, b.prunedHistory(uint64(number))
+	}
+	return block, nil
 }
 
 func (b *EthAPIBackend) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
-	return b.eth.blockchain.GetBlockByHash(hash), nil
+	block := b.eth.blockchain.GetBlockByHash(hash)
+	if block == nil {
+		if number := rawdb.ReadHeaderNumber(b.eth.chainDb, hash); number != nil {
+			return nil, b.prunedHistory(*number)
+		}
+	}
+	return block, nil
+}
+
+// prunedHistory returns an error if the body and receipts of the block with
+// the given number were pruned from the database, nil otherwise.
+func (b *EthAPIBackend) prunedHistory(number uint64) error {
+	if number < b.eth.blockchain.HistoryTail() {
+		return ethapi.NewPrunedHistoryError()
+	}
+	return nil
 }
 
 // GetBody returns body of a block. It does not resolve special block numbers.
@@ -158,6 +178,9 @@ func (b *EthAPIBackend) GetBody(ctx context.Context, hash common.Hash, number rp
 	if body := b.eth.blockchain.GetBody(hash); body != nil {
 		return body, nil
 	}
+	if err := b.prunedHistory(uint64(number)); err != nil {
+		return nil, err
+	}
 	return nil, errors.New("block body not found")
 }
 
@@ -175,6 +198,9 @@ func (b *EthAPIBackend) BlockByNumberOrHash(ctx context.Context, blockNrOrHash r
 		}
 		block := b.eth.blockchain.GetBlock(hash, header.Number.Uint64())
 		if block == nil {
+			if err := b.prunedHistory(header.Number.Uint64()); err != nil {
+				return nil, err
+			}
 			return nil, errors.New("header found, but block body is missing")
 		}
 		return block, nil
@@ -235,11 +261,21 @@ func (b *EthAPIBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockN
 }
 
 func (b *EthAPIBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
-	return b.eth.blockchain.GetReceiptsByHash(hash), nil
+	receipts := b.eth.blockchain.GetReceiptsByHash(hash)
+	if receipts == nil {
+		if number := rawdb.ReadHeaderNumber(b.eth.chainDb, hash); number != nil {
+			return nil, b.prunedHistory(*number)
+		}
+	}
+	return receipts, nil
 }
 
 func (b *EthAPIBackend) GetLogs(ctx context.Context, hash common.Hash, number uint64) ([][]*types.Log, error) {
-	return rawdb.ReadLogs(b.eth.chainDb, hash, number), nil
+	logs := rawdb.ReadLogs(b.eth.chainDb, hash, number)
+	if logs == nil {
+		return nil, b.prunedHistory(number)
+	}
+	return logs, nil
 }
 
 func (b *EthAPIBackend) GetTd(ctx context.Context, hash common.Hash) *big.Int {
@@ -324,11 +360,20 @@ func (b *EthAPIBackend) GetTransaction(ctx context.Context, txHash common.Hash)
 		return false, nil, common.Hash{}, 0, 0, err
 	}
 	if lookup == nil || tx == nil {
+		// The transaction is still indexed, but its block body might have
+		// been pruned from the database.
+		if number := rawdb.ReadTxLookupEntry(b.eth.chainDb, txHash); number != nil {
+			return false, nil, common.Hash{}, 0, 0, b.prunedHistory(*number)
+		}
 		return false, nil, common.Hash{}, 0, 0, nil
 	}
 	return true, tx, lookup.BlockHash, lookup.BlockIndex, lookup.Index, nil
 }
 
+func (b *EthAPIBackend) GetAddressTransactions(ctx context.Context, address common.Address, from uint64, index uint32, to uint64, limit int) ([]rawdb.AddressTxEntry, error) {
+	return b.eth.blockchain.GetAddressTransactions(address, from, index, to, limit)
+}
+
 func (b *EthAPIBackend) GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error) {
 	return b.eth.txPool.Nonce(addr), nil
 }
@@ -403,6 +448,9 @@ func (b *EthAPIBackend) RPCTxFeeCap() float64 {
 }
 
 func (b *EthAPIBackend) BloomStatus() (uint64, uint64) {
+	if b.eth.bloomRetired.Load() {
+		return params.BloomBitsBlocks, 0
+	}
 	sections, _, _ := b.eth.bloomIndexer.Sections()
 	return params.BloomBitsBlocks, sections
 }
@@ -23,6 +23,7 @@ import (
 	"math/big"
 	"runtime"
 	"sync"
+	"sync/atomic"
 
 	"github.com/ethereum/go-ethereum/consensus/congress"
 
@@ -87,8 +88,13 @@ type Ethereum struct {
 
 	bloomRequests     chan chan *bloombits.Retrieval // Channel receiving bloom data retrieval requests
 	bloomIndexer      *core.ChainIndexer             // Bloom indexer operating during block imports
+	bloomRetired      atomic.Bool                    // Whether the bloom indexer was superseded by the log index
+	bloomMigrated     chan struct{}                  // Channel closed when the bloombits migration terminates
 	closeBloomHandler chan struct{}
 
+	responseCache      *rpc.ResponseCache // Cache of immutable RPC results, nil if disabled
+	closeResponseCache chan chan struct{} // Channel terminating the response cache invalidation
+
 	APIBackend *EthAPIBackend
 
 	miner     *miner.Miner
@@ -167,6 +173,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		accountManager:    stack.AccountManager(),
 		engine:            engine,
 		closeBloomHandler: make(chan struct{}),
+		responseCache:     stack.ResponseCache(),
 		networkID:         networkID,
 		gasPrice:          config.Miner.GasPrice,
 		etherbase:         config.Miner.Etherbase,
@@ -205,6 +212,10 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 			SnapshotLimit:       config.SnapshotCache,
 			Preimages:           config.Preimages,
 			StateHistory:        config.StateHistory,
+			HistoryPrune:        config.HistoryPrune,
+			AddressIndex:        config.AddressIndex,
+			LogIndex:            config.LogIndex,
+			ParallelWorkers:     config.ParallelWorkers,
 			StateScheme:         scheme,
 		}
 	)
@@ -229,19 +240,29 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		congressEngine.SetChainConfig(eth.blockchain.Config())
 	}
 
-	eth.bloomIndexer.Start(eth.blockchain)
-
-	if config.BlobPool.Datadir != "" {
-		config.BlobPool.Datadir = stack.ResolvePath(config.BlobPool.Datadir)
+	// The bloombits are obsolete once the log index covers the entire chain
+	if config.LogIndex && eth.logIndexComplete() {
+		eth.bloomRetired.Store(true)
+		rawdb.DeleteBloomIndex(chainDb)
+	} else {
+		eth.bloomIndexer.Start(eth.blockchain)
 	}
-	blobPool := blobpool.New(config.BlobPool, eth.blockchain)
 
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = stack.ResolvePath(config.TxPool.Journal)
 	}
-	legacyPool := legacypool.New(config.TxPool, eth.blockchain)
+	subpools := []txpool.SubPool{legacypool.New(config.TxPool, eth.blockchain)}
 
-	eth.txPool, err = txpool.New(config.TxPool.PriceLimit, eth.blockchain, []txpool.SubPool{legacyPool, blobPool})
+	// Chains past the removal of blob transactions don't need the blob pool and
+	// its store. Until then the pool is kept, its validation rejecting the blob
+	// transactions from the fork on.
+	if head := eth.blockchain.CurrentBlock(); !eth.blockchain.Config().IsBlobDisabled(head.Number, head.Time) {
+		if config.BlobPool.Datadir != "" {
+			config.BlobPool.Datadir = stack.ResolvePath(config.BlobPool.Datadir)
+		}
+		subpools = append(subpools, blobpool.New(config.BlobPool, eth.blockchain))
+	}
+	eth.txPool, err = txpool.New(config.TxPool.PriceLimit, eth.blockchain, subpools)
 	if err != nil {
 		return nil, err
 	}
@@ -537,7 +558,16 @@ func (s *Ethereum) Start() error {
 
 	// Start the bloom bits servicing goroutines
 	s.startBloomHandlers(params.BloomBitsBlocks)
+	if s.config.LogIndex && !s.bloomRetired.Load() {
+		s.bloomMigrated = make(chan struct{})
+		go s.migrateBloomIndex()
+	}
 
+	// Drop the cached RPC results of reorged blocks
+	if s.responseCache != nil {
+		s.closeResponseCache = make(chan chan struct{})
+		go s.invalidateResponseCache()
+	}
 	// Regularly update shutdown marker
 	s.shutdownTracker.Start()
 
@@ -568,8 +598,18 @@ func (s *Ethereum) Stop() error {
 	s.handler.Stop()
 
 	// Then stop everything else.
-	s.bloomIndexer.Close()
 	close(s.closeBloomHandler)
+	if s.bloomMigrated != nil {
+		<-s.bloomMigrated
+	}
+	if !s.bloomRetired.Load() {
+		s.bloomIndexer.Close()
+	}
+	if s.closeResponseCache != nil {
+		done := make(chan struct{})
+		s.closeResponseCache <- done
+		<-done
+	}
 	s.txPool.Close()
 	s.miner.Close()
 	s.blockchain.Stop()
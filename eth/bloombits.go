@@ -19,8 +19,11 @@ package eth
 import (
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/bitutil"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/log"
 )
 
 const (
@@ -72,3 +75,41 @@ func (eth *Ethereum) startBloomHandlers(sectionSize uint64) {
 		}()
 	}
 }
+
+// logIndexComplete reports whether the log index covers all the blocks whose
+// receipts are retained in the database.
+func (eth *Ethereum) logIndexComplete() bool {
+	tail := rawdb.ReadLogIndexTail(eth.chainDb)
+	return tail != nil && *tail <= eth.blockchain.HistoryTail()
+}
+
+// migrateBloomIndex waits until the log index is fully built and retires the
+// bloom indexer afterwards, dropping all the bloombits from the database. Range
+// filters are served by the bloombits until then.
+func (eth *Ethereum) migrateBloomIndex() {
+	defer close(eth.bloomMigrated)
+
+	var (
+		headCh = make(chan core.ChainHeadEvent, 1)
+		sub    = eth.blockchain.SubscribeChainHeadEvent(headCh)
+	)
+	defer sub.Unsubscribe()
+
+	for !eth.logIndexComplete() {
+		select {
+		case <-headCh:
+		case <-sub.Err():
+			return
+		case <-eth.closeBloomHandler:
+			return
+		}
+	}
+	if err := eth.bloomIndexer.Close(); err != nil {
+		log.Warn("Failed to stop bloom indexer", "err", err)
+	}
+	eth.bloomRetired.Store(true)
+
+	start := time.Now()
+	rawdb.DeleteBloomIndex(eth.chainDb)
+	log.Info("Migrated bloombits to log index", "elapsed", common.PrettyDuration(time.Since(start)))
+}
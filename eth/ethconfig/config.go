@@ -97,6 +97,9 @@ type Config struct {
 	TxLookupLimit      uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
 	TransactionHistory uint64 `toml:",omitempty"` // The maximum number of blocks from head whose tx indices are reserved.
 	StateHistory       uint64 `toml:",omitempty"` // The maximum number of blocks from head whose state histories are reserved.
+	HistoryPrune       uint64 `toml:",omitempty"` // The number of blocks from head whose bodies and receipts are reserved (0 = all).
+	AddressIndex       bool   `toml:",omitempty"` // Whether to index transactions by sender and recipient, within the transaction history.
+	LogIndex           bool   `toml:",omitempty"` // Whether to index logs by address and topics, superseding the bloombits.
 
 	// State scheme represents the scheme used to store ethereum states and trie
 	// nodes on top. It can be 'hash', 'path', or none which means use the scheme
@@ -144,6 +147,9 @@ type Config struct {
 	// Enables tracking of SHA3 preimages in the VM
 	EnablePreimageRecording bool
 
+	// Number of workers executing block transactions in parallel (0 = sequential)
+	ParallelWorkers int
+
 	// Miscellaneous options
 	DocRoot string `toml:"-"`
 
@@ -168,6 +174,10 @@ type Config struct {
 
 	// Added maximum block span limit to restrict the amount of data read by RPC interfaces
 	RPCMaxBlockSpan uint64 `toml:",omitempty"`
+
+	// StoreTraces enables persisting callTracer/flatCallTracer results of
+	// canonical blocks, serving repeated trace requests from the database.
+	StoreTraces bool `toml:",omitempty"`
 }
 
 // CreateConsensusEngine creates a consensus engine for the given chain config.
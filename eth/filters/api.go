@@ -35,10 +35,11 @@ import (
 )
 
 var (
-	errInvalidTopic      = errors.New("invalid topic(s)")
-	errFilterNotFound    = errors.New("filter not found")
-	errInvalidBlockRange = errors.New("invalid block range params")
-	errExceedMaxTopics   = errors.New("exceed max topics")
+	errInvalidTopic        = errors.New("invalid topic(s)")
+	errFilterNotFound      = errors.New("filter not found")
+	errInvalidBlockRange   = errors.New("invalid block range params")
+	errExceedMaxTopics     = errors.New("exceed max topics")
+	errExceedMaxTxCriteria = errors.New("exceed max transaction criteria")
 )
 
 // The maximum number of topic criteria allowed, vm.LOG4 - vm.LOG0
@@ -47,6 +48,9 @@ const maxTopics = 4
 // The maximum number of allowed topics within a topic criteria
 const maxSubTopics = 1000
 
+// The maximum number of allowed values of a pending transaction criteria field
+const maxTxCriteria = 1000
+
 // filter is a helper struct that holds meta information over the filter type
 // and associated subscription in the event system.
 type filter struct {
@@ -120,14 +124,14 @@ func (api *FilterAPI) timeoutLoop(timeout time.Duration) {
 //
 // It is part of the filter package because this filter can be used through the
 // `eth_getFilterChanges` polling method that is also used for log filters.
-func (api *FilterAPI) NewPendingTransactionFilter(fullTx *bool) rpc.ID {
+func (api *FilterAPI) NewPendingTransactionFilter(crit *PendingTxCriteria) rpc.ID {
 	var (
 		pendingTxs   = make(chan []*types.Transaction)
-		pendingTxSub = api.events.SubscribePendingTxs(pendingTxs)
+		pendingTxSub = api.subscribePendingTxs(crit, pendingTxs)
 	)
 
 	api.filtersMu.Lock()
-	api.filters[pendingTxSub.ID] = &filter{typ: PendingTransactionsSubscription, fullTx: fullTx != nil && *fullTx, deadline: time.NewTimer(api.timeout), txs: make([]*types.Transaction, 0), s: pendingTxSub}
+	api.filters[pendingTxSub.ID] = &filter{typ: PendingTransactionsSubscription, fullTx: crit != nil && crit.FullTx, deadline: time.NewTimer(api.timeout), txs: make([]*types.Transaction, 0), s: pendingTxSub}
 	api.filtersMu.Unlock()
 
 	go func() {
@@ -153,8 +157,9 @@ func (api *FilterAPI) NewPendingTransactionFilter(fullTx *bool) rpc.ID {
 
 // NewPendingTransactions creates a subscription that is triggered each time a
 // transaction enters the transaction pool. If fullTx is true the full tx is
-// sent to the client, otherwise the hash is sent.
-func (api *FilterAPI) NewPendingTransactions(ctx context.Context, fullTx *bool) (*rpc.Subscription, error) {
+// sent to the client, otherwise the hash is sent. The criteria restrict the
+// notified transactions by sender, recipient, method and tip.
+func (api *FilterAPI) NewPendingTransactions(ctx context.Context, crit *PendingTxCriteria) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
@@ -164,7 +169,7 @@ func (api *FilterAPI) NewPendingTransactions(ctx context.Context, fullTx *bool)
 
 	go func() {
 		txs := make(chan []*types.Transaction, 128)
-		pendingTxSub := api.events.SubscribePendingTxs(txs)
+		pendingTxSub := api.subscribePendingTxs(crit, txs)
 		defer pendingTxSub.Unsubscribe()
 
 		chainConfig := api.sys.backend.ChainConfig()
@@ -176,7 +181,7 @@ func (api *FilterAPI) NewPendingTransactions(ctx context.Context, fullTx *bool)
 				// TODO(rjl493456442) Send a batch of tx hashes in one notification
 				latest := api.sys.backend.CurrentHeader()
 				for _, tx := range txs {
-					if fullTx != nil && *fullTx {
+					if crit != nil && crit.FullTx {
 						rpcTx := ethapi.NewRPCPendingTransaction(tx, latest, chainConfig)
 						notifier.Notify(rpcSub.ID, rpcTx)
 					} else {
@@ -228,40 +233,45 @@ func (api *FilterAPI) NewBlockFilter() rpc.ID {
 }
 
 // NewHeads send a notification each time a new (header) block is appended to the chain.
-func (api *FilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+// Every canonical block is notified, including the ones skipped by a reorg to a
+// longer chain. The optional resume point replays the headers since a given
+// block, and enables the notification of the heads removed by reorgs.
+func (api *FilterAPI) NewHeads(ctx context.Context, opts *SubscriptionOptions) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
 	}
-
+	tracker, err := api.newChainTracker(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
 	rpcSub := notifier.CreateSubscription()
 
-	go func() {
-		headers := make(chan *types.Header)
-		headersSub := api.events.SubscribeNewHeads(headers)
-		defer headersSub.Unsubscribe()
-
-		for {
-			select {
-			case h := <-headers:
-				notifier.Notify(rpcSub.ID, h)
-			case <-rpcSub.Err():
-				return
-			case <-notifier.Closed():
-				return
-			}
+	tracker.added = func(h *types.Header) error {
+		return notifier.Notify(rpcSub.ID, h)
+	}
+	tracker.removed = func(h *types.Header) error {
+		if opts == nil || !opts.Removed {
+			return nil
 		}
-	}()
+		return notifier.Notify(rpcSub.ID, removedHeader{h})
+	}
+	go tracker.follow(api.events, notifier, rpcSub)
 
 	return rpcSub, nil
 }
 
 // Logs creates a subscription that fires for all new log that match the given filter criteria.
-func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subscription, error) {
+// The optional resume point replays the logs since a given block from the
+// database before switching to live ones.
+func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria, opts *SubscriptionOptions) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
 	}
+	if opts != nil && opts.FromBlock != nil {
+		return api.resumeLogs(ctx, notifier, crit, opts)
+	}
 
 	var (
 		rpcSub      = notifier.CreateSubscription()
@@ -293,6 +303,104 @@ func (api *FilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subsc
 	return rpcSub, nil
 }
 
+// resumeLogs creates a logs subscription which follows the canonical chain from
+// the resume point, notifying the logs of the reverted blocks as removed.
+func (api *FilterAPI) resumeLogs(ctx context.Context, notifier *rpc.Notifier, crit FilterCriteria, opts *SubscriptionOptions) (*rpc.Subscription, error) {
+	if len(crit.Topics) > maxTopics {
+		return nil, errExceedMaxTopics
+	}
+	tracker, err := api.newChainTracker(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if crit.ToBlock != nil && crit.ToBlock.Sign() >= 0 {
+		if crit.ToBlock.Uint64() < tracker.next {
+			return nil, errInvalidBlockRange
+		}
+		tracker.end = crit.ToBlock.Uint64()
+	}
+	var (
+		rpcSub = notifier.CreateSubscription()
+		filter = api.sys.NewRangeFilter(0, 0, crit.Addresses, crit.Topics)
+		notify = func(h *types.Header, removed bool) error {
+			logs, err := filter.blockLogs(context.Background(), h)
+			if err != nil {
+				return err
+			}
+			for _, log := range logs {
+				log := *log // copy, the logs may be shared with the cache
+				log.Removed = removed
+				if err := notifier.Notify(rpcSub.ID, &log); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	)
+	tracker.added = func(h *types.Header) error { return notify(h, false) }
+	tracker.removed = func(h *types.Header) error { return notify(h, true) }
+	go tracker.follow(api.events, notifier, rpcSub)
+
+	return rpcSub, nil
+}
+
+// newChainTracker creates the chain tracker of a subscription, checking that the
+// replay from the resume point is within the maximum block range.
+func (api *FilterAPI) newChainTracker(ctx context.Context, opts *SubscriptionOptions) (*chainTracker, error) {
+	if opts != nil && opts.FromBlock != nil && *opts.FromBlock >= 0 {
+		if err := api.checkBlockRange(ctx, opts.FromBlock.Int64(), rpc.LatestBlockNumber.Int64()); err != nil {
+			return nil, err
+		}
+	}
+	return newChainTracker(ctx, api.sys.backend, opts)
+}
+
+// PendingTxCriteria are the parameters of the pending transaction filters and
+// subscriptions. For backwards compatibility, a boolean is accepted as fullTx.
+type PendingTxCriteria struct {
+	FullTx bool
+	TxCriteria
+}
+
+// UnmarshalJSON sets *args fields with given data.
+func (args *PendingTxCriteria) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &args.FullTx); err == nil {
+		return nil
+	}
+	var raw struct {
+		FullTx  bool             `json:"fullTx"`
+		From    []common.Address `json:"from"`
+		To      []common.Address `json:"to"`
+		Methods []hexutil.Bytes  `json:"methods"`
+		MinTip  *hexutil.Big     `json:"minTip"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if len(raw.From) > maxTxCriteria || len(raw.To) > maxTxCriteria || len(raw.Methods) > maxTxCriteria {
+		return errExceedMaxTxCriteria
+	}
+	args.FullTx = raw.FullTx
+	args.From, args.To = raw.From, raw.To
+	for i, method := range raw.Methods {
+		if len(method) != 4 {
+			return fmt.Errorf("invalid method selector at index %d: have %d bytes, want 4", i, len(method))
+		}
+		args.Methods = append(args.Methods, [4]byte(method))
+	}
+	args.MinTip = (*big.Int)(raw.MinTip)
+	return nil
+}
+
+// subscribePendingTxs subscribes to the pending transactions matching the
+// criteria of a filter or subscription.
+func (api *FilterAPI) subscribePendingTxs(crit *PendingTxCriteria, txs chan []*types.Transaction) *Subscription {
+	if crit == nil || crit.empty() {
+		return api.events.SubscribePendingTxs(txs)
+	}
+	return api.events.SubscribeFilteredPendingTxs(crit.TxCriteria, txs)
+}
+
 // FilterCriteria represents a request to create a new filter.
 // Same as ethereum.FilterQuery but with UnmarshalJSON() method.
 type FilterCriteria ethereum.FilterQuery
@@ -345,10 +453,17 @@ func (api *FilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([]*type
 	if len(crit.Topics) > maxTopics {
 		return nil, errExceedMaxTopics
 	}
-	var filter *Filter
+	var (
+		filter    *Filter
+		cacheable bool   // whether the result is immutable unless reorged
+		number    uint64 // last block the result depends on
+	)
 	if crit.BlockHash != nil {
 		// Block filter requested, construct a single-shot filter
 		filter = api.sys.NewBlockFilter(*crit.BlockHash, crit.Addresses, crit.Topics)
+		if header, _ := api.sys.backend.HeaderByHash(ctx, *crit.BlockHash); header != nil {
+			cacheable, number = true, header.Number.Uint64()
+		}
 	} else {
 		// Convert the RPC block numbers into internal representations
 		begin := rpc.LatestBlockNumber.Int64()
@@ -368,12 +483,20 @@ func (api *FilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([]*type
 		}
 		// Construct the range filter
 		filter = api.sys.NewRangeFilter(begin, end, crit.Addresses, crit.Topics)
+
+		// Ranges of explicit past blocks don't change unless reorged
+		if head := api.sys.backend.CurrentHeader(); begin >= 0 && end >= 0 && uint64(end) <= head.Number.Uint64() {
+			cacheable, number = true, uint64(end)
+		}
 	}
 	// Run the filter and return all the logs
 	logs, err := filter.Logs(ctx)
 	if err != nil {
 		return nil, err
 	}
+	if cacheable {
+		rpc.CacheResponse(ctx, number)
+	}
 	return returnLogs(logs), err
 }
 
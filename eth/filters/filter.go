@@ -23,10 +23,16 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/bloombits"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
+// logIndexWindow is the number of blocks whose log index entries are looked up
+// at once, limiting the memory used by wide range queries.
+const logIndexWindow = 4096
+
 // Filter can be used to retrieve and filter logs.
 type Filter struct {
 	sys *FilterSystem
@@ -192,27 +198,41 @@ func (f *Filter) rangeLogsAsync(ctx context.Context) (chan *types.Log, chan erro
 			close(logChan)
 		}()
 
-		// Gather all indexed logs, and finish with non indexed ones
+		// Blocks covered by the log index are searched through it, all older
+		// ones through the bloombits.
 		var (
-			end            = uint64(f.end)
-			size, sections = f.sys.backend.BloomStatus()
-			err            error
+			end     = uint64(f.end)
+			logTail = end + 1
 		)
-		if indexed := sections * size; indexed > uint64(f.begin) {
-			if indexed > end {
-				indexed = end + 1
+		if tail := rawdb.ReadLogIndexTail(f.sys.backend.ChainDb()); tail != nil && *tail < logTail {
+			logTail = *tail
+		}
+		// Gather all bloom indexed logs, and finish with non indexed ones
+		if uint64(f.begin) < logTail {
+			var (
+				size, sections = f.sys.backend.BloomStatus()
+				err            error
+			)
+			if indexed := sections * size; indexed > uint64(f.begin) {
+				if indexed > logTail {
+					indexed = logTail
+				}
+				if err = f.indexedLogs(ctx, indexed-1, logChan); err != nil {
+					errChan <- err
+					return
+				}
 			}
-			if err = f.indexedLogs(ctx, indexed-1, logChan); err != nil {
+			if err := f.unindexedLogs(ctx, logTail-1, logChan); err != nil {
 				errChan <- err
 				return
 			}
 		}
-
-		if err := f.unindexedLogs(ctx, end, logChan); err != nil {
-			errChan <- err
-			return
+		if logTail <= end {
+			if err := f.logIndexLogs(ctx, end, logChan); err != nil {
+				errChan <- err
+				return
+			}
 		}
-
 		errChan <- nil
 	}()
 
@@ -265,6 +285,114 @@ func (f *Filter) indexedLogs(ctx context.Context, end uint64, logChan chan *type
 	}
 }
 
+// logIndexLogs returns the logs matching the filter criteria based on the log
+// index, which covers the blocks from its tail up to the chain head.
+func (f *Filter) logIndexLogs(ctx context.Context, end uint64, logChan chan *types.Log) error {
+	db := f.sys.backend.ChainDb()
+	for f.begin <= int64(end) {
+		to := uint64(f.begin) + logIndexWindow - 1
+		if to > end {
+			to = end
+		}
+		numbers, filtered := f.logIndexMatches(db, uint64(f.begin), to)
+		if !filtered {
+			// Without address and topic criteria all blocks match
+			return f.unindexedLogs(ctx, end, logChan)
+		}
+		for _, number := range numbers {
+			f.begin = int64(number)
+
+			header, err := f.sys.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+			if header == nil || err != nil {
+				return err
+			}
+			found, err := f.checkMatches(ctx, header)
+			if err != nil {
+				return err
+			}
+			for _, log := range found {
+				select {
+				case logChan <- log:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		}
+		f.begin = int64(to) + 1
+	}
+	return nil
+}
+
+// logIndexMatches returns the numbers of the blocks within the range [from, to]
+// which may contain matching logs, according to the log index. False is returned
+// if the filter has no address and topic criteria to look up.
+func (f *Filter) logIndexMatches(db ethdb.Iteratee, from, to uint64) ([]uint64, bool) {
+	var (
+		matches  []uint64
+		filtered bool
+	)
+	intersect := func(numbers []uint64) {
+		if !filtered {
+			matches, filtered = numbers, true
+		} else {
+			matches = intersectNumbers(matches, numbers)
+		}
+	}
+	if len(f.addresses) > 0 {
+		var numbers []uint64
+		for _, address := range f.addresses {
+			numbers = unionNumbers(numbers, rawdb.ReadLogAddressBlocks(db, address, from, to))
+		}
+		intersect(numbers)
+	}
+	for i, topics := range f.topics {
+		if len(topics) == 0 {
+			continue // wildcard
+		}
+		if filtered && len(matches) == 0 {
+			break
+		}
+		var numbers []uint64
+		for _, topic := range topics {
+			numbers = unionNumbers(numbers, rawdb.ReadLogTopicBlocks(db, i, topic, from, to))
+		}
+		intersect(numbers)
+	}
+	return matches, filtered
+}
+
+// unionNumbers merges two ascending lists of block numbers.
+func unionNumbers(a, b []uint64) []uint64 {
+	merged := make([]uint64, 0, len(a)+len(b))
+	for len(a) > 0 || len(b) > 0 {
+		switch {
+		case len(b) == 0 || (len(a) > 0 && a[0] < b[0]):
+			merged, a = append(merged, a[0]), a[1:]
+		case len(a) == 0 || b[0] < a[0]:
+			merged, b = append(merged, b[0]), b[1:]
+		default:
+			merged, a, b = append(merged, a[0]), a[1:], b[1:]
+		}
+	}
+	return merged
+}
+
+// intersectNumbers returns the block numbers present in both ascending lists.
+func intersectNumbers(a, b []uint64) []uint64 {
+	var common []uint64
+	for len(a) > 0 && len(b) > 0 {
+		switch {
+		case a[0] < b[0]:
+			a = a[1:]
+		case b[0] < a[0]:
+			b = b[1:]
+		default:
+			common, a, b = append(common, a[0]), a[1:], b[1:]
+		}
+	}
+	return common
+}
+
 // unindexedLogs returns the logs matching the filter criteria based on raw block
 // iteration and bloom matching.
 func (f *Filter) unindexedLogs(ctx context.Context, end uint64, logChan chan *types.Log) error {
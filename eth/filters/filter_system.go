@@ -21,6 +21,8 @@ package filters
 import (
 	"context"
 	"fmt"
+	"math/big"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -28,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/core/rawdb"
@@ -182,6 +185,7 @@ type subscription struct {
 	typ       Type
 	created   time.Time
 	logsCrit  ethereum.FilterQuery
+	txsCrit   *TxCriteria // nil if all pending transactions are delivered
 	logs      chan []*types.Log
 	txs       chan []*types.Transaction
 	headers   chan *types.Header
@@ -420,6 +424,65 @@ func (es *EventSystem) SubscribePendingTxs(txs chan []*types.Transaction) *Subsc
 	return es.subscribe(sub)
 }
 
+// SubscribeFilteredPendingTxs creates a subscription that writes the transactions
+// entering the transaction pool which match the given criteria.
+func (es *EventSystem) SubscribeFilteredPendingTxs(crit TxCriteria, txs chan []*types.Transaction) *Subscription {
+	sub := &subscription{
+		id:        rpc.NewID(),
+		typ:       PendingTransactionsSubscription,
+		created:   time.Now(),
+		txsCrit:   &crit,
+		logs:      make(chan []*types.Log),
+		txs:       txs,
+		headers:   make(chan *types.Header),
+		installed: make(chan struct{}),
+		err:       make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
+// TxCriteria selects the pending transactions delivered to a subscription. A
+// transaction matches if it matches all the non-empty fields, and a field
+// matches if any of its values matches.
+type TxCriteria struct {
+	From    []common.Address // senders of the transaction
+	To      []common.Address // recipients of the transaction, never matching contract creations
+	Methods [][4]byte        // method selectors, the first four bytes of the call data
+	MinTip  *big.Int         // minimum effective tip at the base fee of the next block
+}
+
+// empty reports whether the criteria match all transactions.
+func (crit *TxCriteria) empty() bool {
+	return len(crit.From) == 0 && len(crit.To) == 0 && len(crit.Methods) == 0 && crit.MinTip == nil
+}
+
+// match reports whether a transaction matches the criteria.
+func (crit *TxCriteria) match(tx *types.Transaction, signer types.Signer, baseFee *big.Int) bool {
+	if len(crit.To) > 0 && (tx.To() == nil || !slices.Contains(crit.To, *tx.To())) {
+		return false
+	}
+	if len(crit.Methods) > 0 {
+		data := tx.Data()
+		if len(data) < 4 || !slices.Contains(crit.Methods, [4]byte(data[:4])) {
+			return false
+		}
+	}
+	if crit.MinTip != nil {
+		tip, err := tx.EffectiveGasTip(baseFee)
+		if err != nil || tip.Cmp(crit.MinTip) < 0 {
+			return false
+		}
+	}
+	// Check the sender last, as it might need to be recovered
+	if len(crit.From) > 0 {
+		from, err := types.Sender(signer, tx)
+		if err != nil || !slices.Contains(crit.From, from) {
+			return false
+		}
+	}
+	return true
+}
+
 type filterIndex map[Type]map[rpc.ID]*subscription
 
 func (es *EventSystem) handleLogs(filters filterIndex, ev []*types.Log) {
@@ -447,8 +510,33 @@ func (es *EventSystem) handlePendingLogs(filters filterIndex, ev []*types.Log) {
 }
 
 func (es *EventSystem) handleTxsEvent(filters filterIndex, ev core.NewTxsEvent) {
+	var (
+		signer  types.Signer
+		baseFee *big.Int
+	)
 	for _, f := range filters[PendingTransactionsSubscription] {
-		f.txs <- ev.Txs
+		if f.txsCrit == nil {
+			f.txs <- ev.Txs
+			continue
+		}
+		// The signer and the base fee of the next block are shared by the
+		// filtered subscriptions.
+		if signer == nil {
+			config, head := es.backend.ChainConfig(), es.backend.CurrentHeader()
+			signer = types.LatestSigner(config)
+			if config.IsLondon(new(big.Int).Add(head.Number, common.Big1)) {
+				baseFee = eip1559.CalcBaseFee(config, head)
+			}
+		}
+		var matched []*types.Transaction
+		for _, tx := range ev.Txs {
+			if f.txsCrit.match(tx, signer, baseFee) {
+				matched = append(matched, tx)
+			}
+		}
+		if len(matched) > 0 {
+			f.txs <- matched
+		}
 	}
 }
 
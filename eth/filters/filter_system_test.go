@@ -321,8 +321,7 @@ func TestPendingTxFilterFullTx(t *testing.T) {
 		txs []*ethapi.RPCTransaction
 	)
 
-	fullTx := true
-	fid0 := api.NewPendingTransactionFilter(&fullTx)
+	fid0 := api.NewPendingTransactionFilter(&PendingTxCriteria{FullTx: true})
 
 	time.Sleep(1 * time.Second)
 	backend.txFeed.Send(core.NewTxsEvent{Txs: transactions})
@@ -465,7 +464,7 @@ func TestInvalidGetRangeLogsRequest(t *testing.T) {
 	var (
 		db     = rawdb.NewMemoryDatabase()
 		_, sys = newTestFilterSystem(t, db, Config{})
-		api    = NewFilterAPI(sys, false)
+		api    = NewFilterAPI(sys, false, 0)
 	)
 
 	if _, err := api.GetLogs(context.Background(), FilterCriteria{FromBlock: big.NewInt(2), ToBlock: big.NewInt(1)}); err != errInvalidBlockRange {
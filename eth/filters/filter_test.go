@@ -388,3 +388,111 @@ func TestFilters(t *testing.T) {
 		}
 	})
 }
+
+// TestLogIndexFilters checks that range filters served by the log index match
+// the ones served by iterating the blocks.
+func TestLogIndexFilters(t *testing.T) {
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		_, sys = newTestFilterSystem(t, db, Config{})
+
+		key, _  = crypto.GenerateKey()
+		addr    = crypto.PubkeyToAddress(key.PublicKey)
+		signer  = types.NewLondonSigner(big.NewInt(1))
+		emitter = common.Address{0xfe}
+		mirror  = common.Address{0xff}
+
+		gspec = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: types.GenesisAlloc{
+				addr:    {Balance: big.NewInt(0).Mul(big.NewInt(100), big.NewInt(params.Ether))},
+				emitter: {Balance: big.NewInt(0), Code: common.FromHex("60003560203560006000a200")}, // LOG2(0, 0, calldata[0:32], calldata[32:64])
+				mirror:  {Balance: big.NewInt(0), Code: common.FromHex("60203560003560006000a200")}, // LOG2(0, 0, calldata[32:64], calldata[0:32])
+			},
+			BaseFee: big.NewInt(params.InitialBaseFee),
+		}
+		topics = []common.Hash{{0x01}, {0x02}, {0x03}}
+		nonce  uint64
+	)
+	genchain, _ := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	defer genchain.Stop()
+
+	_, err := gspec.Commit(db, triedb.NewDatabase(db, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain, _ := core.GenerateChain(gspec.Config, gspec.ToBlock(), ethash.NewFaker(), db, 64, func(i int, gen *core.BlockGen) {
+		if i%3 == 2 {
+			return
+		}
+		to := emitter
+		if i%5 == 0 {
+			to = mirror
+		}
+		data := append(topics[i%3].Bytes(), topics[(i/3)%3].Bytes()...)
+		tx, _ := types.SignTx(types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: gen.BaseFee(),
+			Gas:      30000,
+			To:       &to,
+			Data:     data,
+		}), signer, key)
+		gen.AddTxWithChain(genchain, tx)
+		nonce++
+	})
+	var l uint64
+	bc, err := core.NewBlockChain(db, &core.CacheConfig{TrieCleanLimit: 256, TrieDirtyLimit: 256, TrieTimeLimit: 5 * time.Minute, SnapshotLimit: 256, LogIndex: true}, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, &l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bc.Stop()
+
+	if _, err = bc.InsertChain(chain); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		if tail := rawdb.ReadLogIndexTail(db); tail != nil && *tail == 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	filters := []struct {
+		begin, end int64
+		addresses  []common.Address
+		topics     [][]common.Hash
+	}{
+		{0, -1, []common.Address{emitter}, nil},
+		{0, -1, []common.Address{emitter, mirror}, nil},
+		{0, -1, nil, [][]common.Hash{{topics[0]}}},
+		{0, -1, nil, [][]common.Hash{nil, {topics[1]}}},
+		{0, -1, nil, [][]common.Hash{{topics[0], topics[2]}, {topics[1]}}},
+		{0, -1, []common.Address{mirror}, [][]common.Hash{{topics[1]}, {topics[0]}}},
+		{10, 40, []common.Address{emitter}, [][]common.Hash{{topics[2]}}},
+		{0, -1, nil, [][]common.Hash{nil, nil, {topics[0]}}},
+		{0, -1, nil, nil},
+	}
+	run := func() []string {
+		var results []string
+		for i, tc := range filters {
+			logs, err := sys.NewRangeFilter(tc.begin, tc.end, tc.addresses, tc.topics).Logs(context.Background())
+			if err != nil {
+				t.Fatalf("filter %d: %v", i, err)
+			}
+			have, _ := json.Marshal(logs)
+			results = append(results, string(have))
+		}
+		return results
+	}
+	indexed := run()
+
+	// Drop the log index, all the blocks are iterated afterwards
+	rawdb.DeleteLogIndexTail(db)
+	for i, want := range run() {
+		if indexed[i] != want {
+			t.Fatalf("filter %d mismatch: have\n%s\nwant\n%s", i, indexed[i], want)
+		}
+	}
+	if indexed[0] == "null" || indexed[5] == "null" {
+		t.Fatal("expected matching logs")
+	}
+}
@@ -0,0 +1,240 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// chainTrackerBatch is the number of blocks notified by a chain tracker before
+// checking for new chain heads, bounding the latency of the event loop while a
+// subscription replays the history.
+const chainTrackerBatch = 128
+
+// SubscriptionOptions are the optional parameters of the newHeads and logs
+// subscriptions, allowing clients to resume them after a reconnect.
+type SubscriptionOptions struct {
+	// FromBlock is the first block whose notifications are replayed from the
+	// database before switching to live ones. The fromBlock of the filter
+	// criteria of logs subscriptions only filters the live logs.
+	FromBlock *rpc.BlockNumber `json:"fromBlock,omitempty"`
+
+	// ParentHash is the hash of the block preceding FromBlock, as seen by the
+	// client. If that block was reorged out in the meantime, the blocks removed
+	// from the canonical chain are notified before the replay.
+	ParentHash *common.Hash `json:"parentHash,omitempty"`
+
+	// BlockHash is the hash of FromBlock as seen by the client, for resuming in
+	// the middle of the notifications of that block. The block is notified again
+	// if it's still canonical, or as removed otherwise. It excludes ParentHash.
+	BlockHash *common.Hash `json:"blockHash,omitempty"`
+
+	// Removed enables the notification of the heads removed from the canonical
+	// chain by reorgs. Removed logs are always notified.
+	Removed bool `json:"removed,omitempty"`
+}
+
+// removedHeader is the notification of a head removed from the canonical chain.
+type removedHeader struct {
+	header *types.Header
+}
+
+// MarshalJSON marshals the header with an additional removed field.
+func (h removedHeader) MarshalJSON() ([]byte, error) {
+	enc, err := json.Marshal(h.header)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(enc, &fields); err != nil {
+		return nil, err
+	}
+	fields["removed"] = json.RawMessage("true")
+	return json.Marshal(fields)
+}
+
+// chainTracker follows the canonical chain on behalf of a subscription. Every
+// canonical block is notified exactly once in order, and previously notified
+// blocks are reverted in reverse order when a reorg removes them.
+type chainTracker struct {
+	backend Backend
+	last    *types.Header // last notified block, nil if none yet
+	next    uint64        // number of the next block to notify
+	end     uint64        // number of the last block to notify
+
+	added   func(*types.Header) error // called for new canonical blocks
+	removed func(*types.Header) error // called for reverted blocks
+}
+
+// newChainTracker creates a tracker notifying the blocks following the current
+// head, or replaying the chain from a resume point if one is given.
+func newChainTracker(ctx context.Context, backend Backend, opts *SubscriptionOptions) (*chainTracker, error) {
+	t := &chainTracker{backend: backend, end: math.MaxUint64}
+	switch {
+	case opts == nil || opts.FromBlock == nil || *opts.FromBlock == rpc.LatestBlockNumber:
+		t.last = backend.CurrentHeader()
+
+	case *opts.FromBlock < 0:
+		return nil, fmt.Errorf("invalid resume block %d", *opts.FromBlock)
+
+	case opts.BlockHash != nil:
+		if opts.ParentHash != nil {
+			return nil, errors.New("both parent and block hash given")
+		}
+		header, err := backend.HeaderByHash(ctx, *opts.BlockHash)
+		if err != nil {
+			return nil, err
+		}
+		if header == nil {
+			return nil, fmt.Errorf("unknown resume block %x", *opts.BlockHash)
+		}
+		if header.Number.Uint64() != uint64(*opts.FromBlock) {
+			return nil, fmt.Errorf("resume block %x is not block %d", *opts.BlockHash, *opts.FromBlock)
+		}
+		// The resume block is the last notified one, but it's notified again
+		t.last, t.next = header, header.Number.Uint64()
+		return t, nil
+
+	case opts.ParentHash != nil:
+		parent, err := backend.HeaderByHash(ctx, *opts.ParentHash)
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil {
+			return nil, fmt.Errorf("unknown parent block %x", *opts.ParentHash)
+		}
+		if parent.Number.Uint64()+1 != uint64(*opts.FromBlock) {
+			return nil, fmt.Errorf("parent block %x is not the parent of block %d", *opts.ParentHash, *opts.FromBlock)
+		}
+		t.last = parent
+
+	case *opts.FromBlock > 0:
+		parent, err := backend.HeaderByNumber(ctx, *opts.FromBlock-1)
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil {
+			return nil, fmt.Errorf("resume block %d is beyond the chain head", *opts.FromBlock)
+		}
+		t.last = parent
+	}
+	if t.last != nil {
+		t.next = t.last.Number.Uint64() + 1
+	}
+	return t, nil
+}
+
+// step notifies the changes of the canonical chain up to the given head,
+// notifying at most limit new blocks. It reports whether the tracker caught up.
+func (t *chainTracker) step(ctx context.Context, head uint64, limit int) (bool, error) {
+	// Revert the notified blocks which aren't canonical anymore
+	for t.last != nil {
+		canon, err := t.backend.HeaderByNumber(ctx, rpc.BlockNumber(t.last.Number.Uint64()))
+		if err != nil {
+			return false, err
+		}
+		if canon != nil && canon.Hash() == t.last.Hash() {
+			break
+		}
+		if err := t.removed(t.last); err != nil {
+			return false, err
+		}
+		parent, err := t.backend.HeaderByHash(ctx, t.last.ParentHash)
+		if err != nil {
+			return false, err
+		}
+		if parent == nil {
+			return false, errors.New("missing parent of reorged block")
+		}
+		t.last, t.next = parent, parent.Number.Uint64()+1
+	}
+	// Notify the new canonical blocks
+	if head > t.end {
+		head = t.end
+	}
+	for ; t.next <= head; t.next++ {
+		if limit == 0 {
+			return false, nil
+		}
+		header, err := t.backend.HeaderByNumber(ctx, rpc.BlockNumber(t.next))
+		if err != nil {
+			return false, err
+		}
+		if header == nil {
+			break // canonical chain shortened by a concurrent reorg
+		}
+		if err := t.added(header); err != nil {
+			return false, err
+		}
+		t.last = header
+		limit--
+	}
+	return true, nil
+}
+
+// follow runs the tracker until the subscription is terminated, advancing it on
+// every new chain head. If the tracker fails, the subscription is closed with
+// the error so that the client can resume it.
+func (t *chainTracker) follow(es *EventSystem, notifier *rpc.Notifier, rpcSub *rpc.Subscription) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The event loop is never blocked by the replay, chain heads are drained
+	// while catching up and only the latest one is retained.
+	headers := make(chan *types.Header)
+	headersSub := es.SubscribeNewHeads(headers)
+	defer headersSub.Unsubscribe()
+
+	var (
+		head   = t.backend.CurrentHeader().Number.Uint64()
+		done   bool
+		err    error
+		behind = make(chan struct{})
+	)
+	close(behind)
+	for {
+		if !done {
+			if done, err = t.step(ctx, head, chainTrackerBatch); err != nil {
+				log.Debug("Subscription failed to follow the chain", "id", rpcSub.ID, "err", err)
+				notifier.Close(rpcSub.ID, err)
+				return
+			}
+		}
+		var next <-chan struct{}
+		if !done {
+			next = behind
+		}
+		select {
+		case <-next:
+		case h := <-headers:
+			head, done = h.Number.Uint64(), false
+		case <-rpcSub.Err():
+			return
+		case <-notifier.Closed():
+			return
+		}
+	}
+}
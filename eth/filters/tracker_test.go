@@ -0,0 +1,336 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/triedb"
+)
+
+// trackedHeader is the subset of a newHeads notification checked by the tests.
+type trackedHeader struct {
+	Hash    common.Hash  `json:"hash"`
+	Number  *hexutil.Big `json:"number"`
+	Removed bool         `json:"removed"`
+}
+
+// trackerTest is a filter system serving subscriptions over an in-process RPC
+// connection, backed by a chain written directly to the database.
+type trackerTest struct {
+	db      ethdb.Database
+	backend *testBackend
+	client  *rpc.Client
+	genesis *types.Block
+}
+
+func newTrackerTest(t *testing.T) *trackerTest {
+	var (
+		db           = rawdb.NewMemoryDatabase()
+		backend, sys = newTestFilterSystem(t, db, Config{})
+		server       = rpc.NewServer()
+		gspec        = &core.Genesis{Config: params.TestChainConfig, BaseFee: big.NewInt(params.InitialBaseFee)}
+	)
+	genesis, err := gspec.Commit(db, triedb.NewDatabase(db, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.RegisterName("eth", NewFilterAPI(sys, false, 0)); err != nil {
+		t.Fatal(err)
+	}
+	client := rpc.DialInProc(server)
+	t.Cleanup(func() {
+		client.Close()
+		server.Stop()
+	})
+	return &trackerTest{db: db, backend: backend, client: client, genesis: genesis}
+}
+
+// generate creates n blocks on top of parent, each with a single log emitted by
+// the given address, and makes them canonical.
+func (tt *trackerTest) generate(parent *types.Block, n int, addr common.Address) []*types.Block {
+	var receipts []types.Receipts
+	blocks, _ := core.GenerateChain(params.TestChainConfig, parent, ethash.NewFaker(), tt.db, n, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(addr)
+		b.AddUncheckedTx(types.NewTx(&types.LegacyTx{Nonce: uint64(i), To: &addr}))
+		receipt := &types.Receipt{Logs: []*types.Log{{Address: addr}}}
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+		b.AddUncheckedReceipt(receipt)
+		receipts = append(receipts, types.Receipts{receipt})
+	})
+	for i, block := range blocks {
+		rawdb.WriteBlock(tt.db, block)
+		rawdb.WriteReceipts(tt.db, block.Hash(), block.NumberU64(), receipts[i])
+		rawdb.WriteCanonicalHash(tt.db, block.Hash(), block.NumberU64())
+	}
+	head := blocks[len(blocks)-1]
+	for number := head.NumberU64() + 1; rawdb.ReadCanonicalHash(tt.db, number) != (common.Hash{}); number++ {
+		rawdb.DeleteCanonicalHash(tt.db, number)
+	}
+	rawdb.WriteHeadBlockHash(tt.db, head.Hash())
+	return blocks
+}
+
+// announce notifies the subscriptions of a new chain head.
+func (tt *trackerTest) announce(block *types.Block) {
+	tt.backend.chainFeed.Send(core.ChainEvent{Block: block, Hash: block.Hash()})
+}
+
+func receiveHeaders(t *testing.T, sub *rpc.ClientSubscription, ch chan trackedHeader, want []trackedHeader) {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for i, exp := range want {
+		select {
+		case have := <-ch:
+			if have.Hash != exp.Hash || have.Removed != exp.Removed {
+				t.Fatalf("notification %d mismatch: have %x (number %v, removed %v), want %x (number %v, removed %v)",
+					i, have.Hash, have.Number, have.Removed, exp.Hash, exp.Number, exp.Removed)
+			}
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-timeout:
+			t.Fatalf("notification %d not received", i)
+		}
+	}
+}
+
+func headerNotifications(blocks []*types.Block, removed bool) []trackedHeader {
+	headers := make([]trackedHeader, len(blocks))
+	for i, block := range blocks {
+		headers[i] = trackedHeader{Hash: block.Hash(), Number: (*hexutil.Big)(block.Number()), Removed: removed}
+	}
+	return headers
+}
+
+func reversed(blocks []*types.Block) []*types.Block {
+	rev := make([]*types.Block, len(blocks))
+	for i, block := range blocks {
+		rev[len(blocks)-1-i] = block
+	}
+	return rev
+}
+
+// Tests that a newHeads subscription with a resume point replays the blocks
+// since then before switching to the live ones.
+func TestNewHeadsReplay(t *testing.T) {
+	t.Parallel()
+
+	var (
+		tt     = newTrackerTest(t)
+		blocks = tt.generate(tt.genesis, 10, common.Address{0x1})
+		ch     = make(chan trackedHeader)
+		from   = rpc.BlockNumber(4)
+	)
+	sub, err := tt.client.EthSubscribe(context.Background(), ch, "newHeads", SubscriptionOptions{FromBlock: &from})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	receiveHeaders(t, sub, ch, headerNotifications(blocks[3:], false))
+
+	next := tt.generate(blocks[len(blocks)-1], 2, common.Address{0x1})
+	tt.announce(next[1])
+	receiveHeaders(t, sub, ch, headerNotifications(next, false))
+}
+
+// Tests that a newHeads subscription resumed from a parent which was reorged out
+// in the meantime notifies the removed blocks before the new canonical ones.
+func TestNewHeadsReorgedParent(t *testing.T) {
+	t.Parallel()
+
+	var (
+		tt     = newTrackerTest(t)
+		blocks = tt.generate(tt.genesis, 8, common.Address{0x1})
+		fork   = tt.generate(blocks[3], 6, common.Address{0x2})
+		ch     = make(chan trackedHeader)
+		from   = rpc.BlockNumber(7)
+		parent = blocks[5].Hash()
+	)
+	opts := SubscriptionOptions{FromBlock: &from, ParentHash: &parent, Removed: true}
+	sub, err := tt.client.EthSubscribe(context.Background(), ch, "newHeads", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	// Blocks 5 and 6 of the old chain were seen by the client and are reverted
+	want := headerNotifications(reversed(blocks[4:6]), true)
+	want = append(want, headerNotifications(fork, false)...)
+	receiveHeaders(t, sub, ch, want)
+}
+
+// Tests that a resumed logs subscription notifies the logs of the blocks reorged
+// out after being notified as removed, followed by the logs of the new chain.
+func TestLogsReorgRemoved(t *testing.T) {
+	t.Parallel()
+
+	var (
+		tt     = newTrackerTest(t)
+		blocks = tt.generate(tt.genesis, 6, common.Address{0x1})
+		ch     = make(chan types.Log)
+		from   = rpc.BlockNumber(3)
+	)
+	sub, err := tt.client.EthSubscribe(context.Background(), ch, "logs", FilterCriteria{}, SubscriptionOptions{FromBlock: &from})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	type logEvent struct {
+		block   common.Hash
+		addr    common.Address
+		removed bool
+	}
+	receive := func(want []logEvent) {
+		t.Helper()
+		timeout := time.After(5 * time.Second)
+		for i, exp := range want {
+			select {
+			case have := <-ch:
+				if have.BlockHash != exp.block || have.Address != exp.addr || have.Removed != exp.removed {
+					t.Fatalf("log %d mismatch: have block %x addr %x removed %v, want block %x addr %x removed %v",
+						i, have.BlockHash, have.Address, have.Removed, exp.block, exp.addr, exp.removed)
+				}
+			case err := <-sub.Err():
+				t.Fatalf("subscription failed: %v", err)
+			case <-timeout:
+				t.Fatalf("log %d not received", i)
+			}
+		}
+	}
+	var want []logEvent
+	for _, block := range blocks[2:] {
+		want = append(want, logEvent{block.Hash(), common.Address{0x1}, false})
+	}
+	receive(want)
+
+	// Reorg the chain from block 4 and check the reverted logs are re-emitted
+	fork := tt.generate(blocks[2], 4, common.Address{0x2})
+	tt.announce(fork[len(fork)-1])
+
+	want = want[:0]
+	for _, block := range reversed(blocks[3:]) {
+		want = append(want, logEvent{block.Hash(), common.Address{0x1}, true})
+	}
+	for _, block := range fork {
+		want = append(want, logEvent{block.Hash(), common.Address{0x2}, false})
+	}
+	receive(want)
+}
+
+// Tests that a logs subscription resumed in the middle of a block notifies that
+// block again if it's still canonical, or as removed if it was reorged out.
+func TestLogsResumeBlock(t *testing.T) {
+	t.Parallel()
+
+	var (
+		tt     = newTrackerTest(t)
+		blocks = tt.generate(tt.genesis, 6, common.Address{0x1})
+		fork   = tt.generate(blocks[1], 2, common.Address{0x2})
+		from   = rpc.BlockNumber(3)
+	)
+	tests := []struct {
+		block   *types.Block
+		removed []*types.Block
+		added   []*types.Block
+	}{
+		{fork[0], nil, fork},
+		{blocks[2], blocks[2:3], fork},
+	}
+	for i, test := range tests {
+		hash := test.block.Hash()
+		ch := make(chan types.Log)
+		sub, err := tt.client.EthSubscribe(context.Background(), ch, "logs", FilterCriteria{}, SubscriptionOptions{FromBlock: &from, BlockHash: &hash})
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		var want []types.Log
+		for _, block := range test.removed {
+			want = append(want, types.Log{BlockHash: block.Hash(), Address: common.Address{0x1}, Removed: true})
+		}
+		for _, block := range test.added {
+			want = append(want, types.Log{BlockHash: block.Hash(), Address: common.Address{0x2}})
+		}
+		timeout := time.After(5 * time.Second)
+		for j, exp := range want {
+			select {
+			case have := <-ch:
+				if have.BlockHash != exp.BlockHash || have.Address != exp.Address || have.Removed != exp.Removed {
+					t.Fatalf("test %d, log %d mismatch: have block %x addr %x removed %v, want block %x addr %x removed %v",
+						i, j, have.BlockHash, have.Address, have.Removed, exp.BlockHash, exp.Address, exp.Removed)
+				}
+			case err := <-sub.Err():
+				t.Fatalf("test %d: subscription failed: %v", i, err)
+			case <-timeout:
+				t.Fatalf("test %d: log %d not received", i, j)
+			}
+		}
+		sub.Unsubscribe()
+	}
+	// The resume block must be the block the client asked for
+	hash := blocks[3].Hash()
+	if _, err := tt.client.EthSubscribe(context.Background(), make(chan types.Log), "logs", FilterCriteria{}, SubscriptionOptions{FromBlock: &from, BlockHash: &hash}); err == nil {
+		t.Fatal("resumed from a block with a different number")
+	}
+}
+
+// Tests that a subscription whose tracker fails is closed with the error.
+func TestNewHeadsTrackerFailure(t *testing.T) {
+	t.Parallel()
+
+	var (
+		tt     = newTrackerTest(t)
+		blocks = tt.generate(tt.genesis, 4, common.Address{0x1})
+		ch     = make(chan trackedHeader)
+		from   = rpc.BlockNumber(1)
+	)
+	sub, err := tt.client.EthSubscribe(context.Background(), ch, "newHeads", SubscriptionOptions{FromBlock: &from})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	receiveHeaders(t, sub, ch, headerNotifications(blocks, false))
+
+	// Reorg the last notified block with its parent missing from the database
+	fork := tt.generate(blocks[2], 1, common.Address{0x2})
+	rawdb.DeleteHeader(tt.db, blocks[2].Hash(), blocks[2].NumberU64())
+	tt.announce(fork[0])
+
+	select {
+	case err := <-sub.Err():
+		if err == nil || err.Error() != "missing parent of reorged block" {
+			t.Fatalf("wrong subscription error: %v", err)
+		}
+	case h := <-ch:
+		t.Fatalf("unexpected notification %x", h.Hash)
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not closed")
+	}
+}
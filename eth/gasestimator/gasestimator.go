@@ -55,9 +55,12 @@ func Estimate(ctx context.Context, call *core.Message, opts *Options, gasCap uin
 		lo uint64 // lowest-known gas limit where tx execution fails
 		hi uint64 // lowest-known gas limit where tx execution succeeds
 	)
+	// The intrinsic gas of plain transfers may be overridden by the gas schedule
+	txGas := opts.Config.GasScheduleAt(opts.Header.Time).IntrinsicGas(params.IntrinsicTxGas, params.TxGas)
+
 	// Determine the highest gas limit can be used during the estimation.
 	hi = opts.Header.GasLimit
-	if call.GasLimit >= params.TxGas {
+	if call.GasLimit >= txGas {
 		hi = call.GasLimit
 	}
 	// Normalize the max fee per gas the call is willing to spend.
@@ -80,6 +83,10 @@ func Estimate(ctx context.Context, call *core.Message, opts *Options, gasCap uin
 			}
 			available.Sub(available, call.Value)
 		}
+		// The gas of sponsored messages is paid by the paymaster
+		if call.Paymaster != nil {
+			available = opts.State.GetBalance(*call.Paymaster).ToBig()
+		}
 		allowance := new(big.Int).Div(available, feeCap)
 
 		// If the allowance is larger than maximum uint64, skip checking
@@ -104,9 +111,9 @@ func Estimate(ctx context.Context, call *core.Message, opts *Options, gasCap uin
 	// unused access list items). Ever so slightly wasteful, but safer overall.
 	if len(call.Data) == 0 {
 		if call.To != nil && opts.State.GetCodeSize(*call.To) == 0 {
-			failed, _, err := execute(ctx, call, opts, params.TxGas)
+			failed, _, err := execute(ctx, call, opts, txGas)
 			if !failed && err == nil {
-				return params.TxGas, nil, nil
+				return txGas, nil, nil
 			}
 		}
 	}
@@ -0,0 +1,64 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// invalidateResponseCache drops the cached RPC results depending on blocks which
+// are no longer canonical. Blocks are reorged if they are reported as side
+// blocks, or if the chain head is rewound or replaced at the same height.
+func (s *Ethereum) invalidateResponseCache() {
+	var (
+		headCh = make(chan core.ChainHeadEvent, 16)
+		sideCh = make(chan core.ChainSideEvent, 16)
+	)
+	headSub := s.blockchain.SubscribeChainHeadEvent(headCh)
+	defer headSub.Unsubscribe()
+	sideSub := s.blockchain.SubscribeChainSideEvent(sideCh)
+	defer sideSub.Unsubscribe()
+
+	var last uint64
+	if head := s.blockchain.CurrentBlock(); head != nil {
+		last = head.Number.Uint64()
+	}
+	for {
+		select {
+		case ev := <-headCh:
+			number := ev.Block.NumberU64()
+			if number <= last {
+				log.Debug("Invalidating cached RPC results", "from", number, "head", last)
+				s.responseCache.Invalidate(number)
+			}
+			last = number
+
+		case ev := <-sideCh:
+			log.Debug("Invalidating cached RPC results", "from", ev.Block.NumberU64(), "head", last)
+			s.responseCache.Invalidate(ev.Block.NumberU64())
+
+		case done := <-s.closeResponseCache:
+			close(done)
+			return
+		case <-headSub.Err():
+			return
+		case <-sideSub.Err():
+			return
+		}
+	}
+}
@@ -91,7 +91,8 @@ type Backend interface {
 
 // API is the collection of tracing APIs exposed over the private debugging endpoint.
 type API struct {
-	backend Backend
+	backend     Backend
+	storeTraces bool // Whether to persist and serve traces of canonical blocks
 }
 
 // NewAPI creates a new API definition for the tracing methods of the Ethereum service.
@@ -268,6 +269,8 @@ func (api *API) traceChain(start, end *types.Block, config *TraceConfig, closed
 					signer   = types.MakeSigner(api.backend.ChainConfig(), task.block.Number(), task.block.Time())
 					blockCtx = core.NewEVMBlockContext(task.block.Header(), api.chainContext(ctx), nil)
 				)
+				api.processParentBlockHash(blockCtx, task.block, task.statedb, api.backend.ChainConfig())
+
 				// Trace all the transactions contained within
 				for i, tx := range task.block.Transactions() {
 					msg, _ := core.TransactionToMessage(tx, signer, task.block.BaseFee())
@@ -526,6 +529,7 @@ func (api *API) IntermediateRoots(ctx context.Context, hash common.Hash, config
 		vmctx              = core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil)
 		deleteEmptyObjects = chainConfig.IsEIP158(block.Number())
 	)
+	api.processParentBlockHash(vmctx, block, statedb, chainConfig)
 	for i, tx := range block.Transactions() {
 		if err := ctx.Err(); err != nil {
 			return nil, err
@@ -564,6 +568,16 @@ func (api *API) StandardTraceBadBlockToFile(ctx context.Context, hash common.Has
 	return api.standardTraceBlockToFile(ctx, block, config)
 }
 
+// processParentBlockHash stores the parent hash of the block in the history
+// storage contract if the fork is active, as done before the transactions of
+// the block when it was processed.
+func (api *API) processParentBlockHash(vmctx vm.BlockContext, block *types.Block, statedb *state.StateDB, chainConfig *params.ChainConfig) {
+	if chainConfig.IsHistoryStorage(block.Number(), block.Time()) {
+		vmenv := vm.NewEVM(vmctx, vm.TxContext{}, statedb, chainConfig, vm.Config{})
+		core.ProcessParentBlockHash(block.ParentHash(), vmenv, statedb)
+	}
+}
+
 // traceBlock configures a new tracer according to the provided configuration, and
 // executes all the transactions contained within. The return value will be one item
 // per transaction, dependent on the requested tracer.
@@ -571,6 +585,13 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 	if block.NumberU64() == 0 {
 		return nil, errors.New("genesis is not traceable")
 	}
+	// Serve the traces from the database if they were persisted before
+	storeID, storable := api.storeIDFor(block.NumberU64(), block.Hash(), config)
+	if storable {
+		if results := api.readStoredTraces(block, storeID); results != nil {
+			return results, nil
+		}
+	}
 	// Prepare base state
 	parent, err := api.blockByNumberAndHash(ctx, rpc.BlockNumber(block.NumberU64()-1), block.ParentHash())
 	if err != nil {
@@ -586,6 +607,8 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 	}
 	defer release()
 
+	api.processParentBlockHash(core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil), block, statedb, api.backend.ChainConfig())
+
 	// JS tracers have high overhead. In this case run a parallel
 	// process that generates states in one thread and traces txes
 	// in separate worker threads.
@@ -621,6 +644,11 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 		// Only delete empty objects if EIP158/161 (a.k.a Spurious Dragon) is in effect
 		statedb.Finalise(is158)
 	}
+	if storable {
+		if err := api.writeStoredTraces(block.NumberU64(), blockHash, storeID, results); err != nil {
+			log.Warn("Failed to store block traces", "number", block.NumberU64(), "hash", blockHash, "err", err)
+		}
+	}
 	return results, nil
 }
 
@@ -756,6 +784,8 @@ func (api *API) standardTraceBlockToFile(ctx context.Context, block *types.Block
 		// Note: This copies the config, to not screw up the main config
 		chainConfig, canon = overrideConfig(chainConfig, config.Overrides)
 	}
+	api.processParentBlockHash(vmctx, block, statedb, chainConfig)
+
 	for i, tx := range block.Transactions() {
 		// Prepare the transaction for un-traced execution
 		var (
@@ -846,6 +876,12 @@ func (api *API) TraceTransaction(ctx context.Context, hash common.Hash, config *
 	if err != nil {
 		return nil, err
 	}
+	// Serve the trace from the database if the block traces were persisted before
+	if storeID, storable := api.storeIDFor(blockNumber, blockHash, config); storable {
+		if results := api.readStoredTraces(block, storeID); int(index) < len(results) {
+			return results[index].Result, nil
+		}
+	}
 	msg, vmctx, statedb, release, err := api.backend.StateAtTransaction(ctx, block, int(index), reexec)
 	if err != nil {
 		return nil, err
@@ -980,12 +1016,12 @@ func (api *API) traceTx(ctx context.Context, message *core.Message, txctx *Conte
 }
 
 // APIs return the collection of RPC services the tracer package offers.
-func APIs(backend Backend) []rpc.API {
+func APIs(backend Backend, config Config) []rpc.API {
 	// Append all the local APIs and return
 	return []rpc.API{
 		{
 			Namespace: "debug",
-			Service:   NewAPI(backend),
+			Service:   &API{backend: backend, storeTraces: config.StoreTraces},
 		},
 	}
 }
@@ -1035,6 +1071,18 @@ func overrideConfig(original *params.ChainConfig, override *params.ChainConfig)
 		copy.VerkleTime = timestamp
 		canon = false
 	}
+	if timestamp := override.P256VerifyTime; timestamp != nil {
+		copy.P256VerifyTime = timestamp
+		canon = false
+	}
+	if timestamp := override.HistoryStorageTime; timestamp != nil {
+		copy.HistoryStorageTime = timestamp
+		canon = false
+	}
+	if timestamp := override.PaymasterTime; timestamp != nil {
+		copy.PaymasterTime = timestamp
+		canon = false
+	}
 
 	return copy, canon
 }
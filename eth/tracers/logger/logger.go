@@ -117,6 +117,8 @@ type StructLogger struct {
 	gasLimit uint64
 	usedGas  uint64
 
+	systemCall bool // Whether a system call of the transaction is being traced
+
 	interrupt atomic.Bool // Atomic flag to signal execution interruption
 	reason    error       // Textual reason for the interruption
 }
@@ -220,6 +222,10 @@ func (l *StructLogger) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, s
 
 // CaptureEnd is called after the call finishes to finalize the tracing.
 func (l *StructLogger) CaptureEnd(output []byte, gasUsed uint64, err error) {
+	// The result of the transaction is the one of its own top call frame
+	if l.systemCall {
+		return
+	}
 	l.output = output
 	l.err = err
 	if l.cfg.Debug {
@@ -262,6 +268,17 @@ func (l *StructLogger) Stop(err error) {
 	l.interrupt.Store(true)
 }
 
+// CaptureSystemCallStart implements the SystemCallLogger interface, the steps
+// of system calls are logged ahead of the ones of the transaction.
+func (l *StructLogger) CaptureSystemCallStart() {
+	l.systemCall = true
+}
+
+// CaptureSystemCallEnd implements the SystemCallLogger interface.
+func (l *StructLogger) CaptureSystemCallEnd() {
+	l.systemCall = false
+}
+
 func (l *StructLogger) CaptureTxStart(gasLimit uint64) {
 	l.gasLimit = gasLimit
 }
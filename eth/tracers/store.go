@@ -0,0 +1,221 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// storableTracers is the set of tracers whose output is deterministic for a
+// given block and configuration, and thus may be persisted in the database.
+var storableTracers = map[string]bool{
+	"callTracer":     true,
+	"flatCallTracer": true,
+}
+
+var (
+	traceStoreHitMeter  = metrics.NewRegisteredMeter("debug/tracestore/hit", nil)
+	traceStoreMissMeter = metrics.NewRegisteredMeter("debug/tracestore/miss", nil)
+)
+
+// Config contains the settings of the tracing API.
+type Config struct {
+	// StoreTraces enables persisting the results of storable tracers for
+	// canonical blocks, serving subsequent requests with a matching tracer
+	// configuration straight from the database.
+	StoreTraces bool
+}
+
+// storedTxTrace is the database representation of a single transaction trace.
+type storedTxTrace struct {
+	TxHash common.Hash
+	Result []byte // JSON encoded tracer output
+}
+
+// traceStoreID returns the identifier under which the results of the given
+// tracer configuration are persisted. The boolean return value reports whether
+// the configuration is storable at all.
+func traceStoreID(config *TraceConfig) (common.Hash, bool) {
+	if config == nil || config.Tracer == nil || !storableTracers[*config.Tracer] {
+		return common.Hash{}, false
+	}
+	// Canonicalise the tracer config so that semantically equal configurations
+	// map to the same identifier regardless of formatting and field order.
+	canonical := []byte("{}")
+	if len(config.TracerConfig) > 0 {
+		var fields map[string]interface{}
+		if err := json.Unmarshal(config.TracerConfig, &fields); err != nil {
+			return common.Hash{}, false
+		}
+		if len(fields) > 0 {
+			blob, err := json.Marshal(fields)
+			if err != nil {
+				return common.Hash{}, false
+			}
+			canonical = blob
+		}
+	}
+	return crypto.Keccak256Hash([]byte(*config.Tracer), []byte{0}, canonical), true
+}
+
+// storeIDFor returns the trace store identifier of the configuration if trace
+// storage is enabled and the block is part of the canonical chain.
+func (api *API) storeIDFor(number uint64, hash common.Hash, config *TraceConfig) (common.Hash, bool) {
+	if !api.storeTraces {
+		return common.Hash{}, false
+	}
+	id, ok := traceStoreID(config)
+	if !ok {
+		return common.Hash{}, false
+	}
+	if rawdb.ReadCanonicalHash(api.backend.ChainDb(), number) != hash {
+		return common.Hash{}, false
+	}
+	return id, true
+}
+
+// readStoredTraces retrieves the persisted trace results of a block, or nil if
+// no (valid) results are stored.
+func (api *API) readStoredTraces(block *types.Block, id common.Hash) []*txTraceResult {
+	txs := block.Transactions()
+	if len(txs) == 0 {
+		traceStoreHitMeter.Mark(1)
+		return []*txTraceResult{}
+	}
+	blob := rawdb.ReadBlockTraces(api.backend.ChainDb(), block.NumberU64(), block.Hash(), id)
+	if len(blob) == 0 {
+		traceStoreMissMeter.Mark(1)
+		return nil
+	}
+	var stored []storedTxTrace
+	if err := rlp.DecodeBytes(blob, &stored); err != nil {
+		log.Error("Invalid stored block traces", "number", block.NumberU64(), "hash", block.Hash(), "err", err)
+		traceStoreMissMeter.Mark(1)
+		return nil
+	}
+	if len(stored) != len(txs) {
+		log.Error("Stored block traces mismatch", "number", block.NumberU64(), "hash", block.Hash(), "have", len(stored), "want", len(txs))
+		traceStoreMissMeter.Mark(1)
+		return nil
+	}
+	results := make([]*txTraceResult, len(stored))
+	for i, trace := range stored {
+		results[i] = &txTraceResult{TxHash: trace.TxHash, Result: json.RawMessage(trace.Result)}
+	}
+	traceStoreHitMeter.Mark(1)
+	return results
+}
+
+// writeStoredTraces persists the trace results of a block. Incomplete results,
+// where some transaction failed to be traced, are silently dropped.
+func (api *API) writeStoredTraces(number uint64, hash common.Hash, id common.Hash, results []*txTraceResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+	stored := make([]storedTxTrace, len(results))
+	for i, res := range results {
+		if res == nil || res.Error != "" {
+			return nil
+		}
+		blob, err := json.Marshal(res.Result)
+		if err != nil {
+			return err
+		}
+		stored[i] = storedTxTrace{TxHash: res.TxHash, Result: blob}
+	}
+	blob, err := rlp.EncodeToBytes(stored)
+	if err != nil {
+		return err
+	}
+	rawdb.WriteBlockTraces(api.backend.ChainDb(), number, hash, id, blob)
+	return nil
+}
+
+// BackfillTraces traces the canonical blocks in the range [start, end] with the
+// given storable tracer configuration and persists the results in the database.
+// The number of blocks whose traces were written is returned.
+func BackfillTraces(ctx context.Context, backend Backend, start, end uint64, config *TraceConfig) (int, error) {
+	id, ok := traceStoreID(config)
+	if !ok {
+		return 0, errors.New("tracer configuration is not storable")
+	}
+	if start == 0 {
+		start = 1 // genesis is not traceable
+	}
+	if start > end {
+		return 0, fmt.Errorf("end block (#%d) needs to come after start block (#%d)", end, start)
+	}
+	api := &API{backend: backend, storeTraces: true}
+	from, err := api.blockByNumber(ctx, rpc.BlockNumber(start-1))
+	if err != nil {
+		return 0, err
+	}
+	to, err := api.blockByNumber(ctx, rpc.BlockNumber(end))
+	if err != nil {
+		return 0, err
+	}
+	var (
+		closed  = make(chan interface{})
+		results = api.traceChain(from, to, config, closed)
+		stored  int
+		logged  time.Time
+	)
+	defer func() {
+		// Abort the chain tracer and drain any in-flight results
+		close(closed)
+		go func() {
+			for range results {
+			}
+		}()
+	}()
+	for result := range results {
+		if err := ctx.Err(); err != nil {
+			return stored, err
+		}
+		if len(result.Traces) == 0 {
+			continue
+		}
+		for _, trace := range result.Traces {
+			if trace == nil || trace.Error != "" {
+				return stored, fmt.Errorf("failed to trace block #%d", result.Block)
+			}
+		}
+		if err := api.writeStoredTraces(uint64(result.Block), result.Hash, id, result.Traces); err != nil {
+			return stored, err
+		}
+		stored++
+
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Backfilling block traces", "block", uint64(result.Block), "end", end, "stored", stored)
+			logged = time.Now()
+		}
+	}
+	return stored, nil
+}
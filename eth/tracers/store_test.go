@@ -0,0 +1,139 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestTraceStoreID(t *testing.T) {
+	t.Parallel()
+
+	var (
+		call    = "callTracer"
+		flat    = "flatCallTracer"
+		prestat = "prestateTracer"
+	)
+	tests := []struct {
+		a, b     *TraceConfig
+		storable bool
+		equal    bool
+	}{
+		{&TraceConfig{Tracer: &call}, &TraceConfig{Tracer: &call, TracerConfig: json.RawMessage(`{}`)}, true, true},
+		{&TraceConfig{Tracer: &call, TracerConfig: json.RawMessage(`{"withLog":true,"onlyTopCall":false}`)},
+			&TraceConfig{Tracer: &call, TracerConfig: json.RawMessage(`{ "onlyTopCall": false, "withLog": true }`)}, true, true},
+		{&TraceConfig{Tracer: &call}, &TraceConfig{Tracer: &flat}, true, false},
+		{&TraceConfig{Tracer: &call}, &TraceConfig{Tracer: &call, TracerConfig: json.RawMessage(`{"withLog":true}`)}, true, false},
+		{&TraceConfig{Tracer: &prestat}, &TraceConfig{Tracer: &prestat}, false, false},
+		{nil, &TraceConfig{}, false, false},
+	}
+	for i, tt := range tests {
+		a, aok := traceStoreID(tt.a)
+		b, bok := traceStoreID(tt.b)
+		if aok != tt.storable || bok != tt.storable {
+			t.Fatalf("test %d: storable mismatch: have %v/%v, want %v", i, aok, bok, tt.storable)
+		}
+		if tt.storable && (a == b) != tt.equal {
+			t.Fatalf("test %d: id equality mismatch: have %v, want %v", i, a == b, tt.equal)
+		}
+	}
+}
+
+func TestTraceBlockFromStore(t *testing.T) {
+	t.Parallel()
+
+	accounts := newAccounts(2)
+	genesis := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: types.GenesisAlloc{
+			accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+		},
+	}
+	// Blocks are generated against a throwaway chain, which is needed by the
+	// generator to resolve the block context of the transactions.
+	genchain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, genesis, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create generator chain: %v", err)
+	}
+	defer genchain.Stop()
+
+	signer := types.HomesteadSigner{}
+	backend := newTestBackend(t, 2, genesis, func(i int, b *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTx(&types.LegacyTx{
+			Nonce:    uint64(i),
+			To:       &accounts[1].addr,
+			Value:    big.NewInt(1000),
+			Gas:      params.TxGas,
+			GasPrice: b.BaseFee(),
+		}), signer, accounts[0].key)
+		b.AddTxWithChain(genchain, tx)
+	})
+	defer backend.chain.Stop()
+
+	var (
+		tracer = "callTracer"
+		config = &TraceConfig{Tracer: &tracer}
+		block  = backend.chain.GetBlockByNumber(2)
+		txHash = block.Transactions()[0].Hash()
+		api    = &API{backend: backend, storeTraces: true}
+	)
+	// The call tracer is not registered in this package, so the traces are
+	// stored manually and must be served without executing the tracer.
+	id, ok := api.storeIDFor(2, block.Hash(), config)
+	if !ok {
+		t.Fatal("canonical block with call tracer should be storable")
+	}
+	stored := []*txTraceResult{{TxHash: txHash, Result: json.RawMessage(`{"type":"CALL"}`)}}
+	if err := api.writeStoredTraces(2, block.Hash(), id, stored); err != nil {
+		t.Fatalf("failed to store traces: %v", err)
+	}
+	results, err := api.TraceBlockByNumber(context.Background(), 2, config)
+	if err != nil {
+		t.Fatalf("failed to trace block: %v", err)
+	}
+	have, _ := json.Marshal(results)
+	want, _ := json.Marshal(stored)
+	if string(have) != string(want) {
+		t.Fatalf("stored block trace mismatch: have %s, want %s", have, want)
+	}
+	result, err := api.TraceTransaction(context.Background(), txHash, config)
+	if err != nil {
+		t.Fatalf("failed to trace transaction: %v", err)
+	}
+	if blob, _ := json.Marshal(result); string(blob) != `{"type":"CALL"}` {
+		t.Fatalf("stored transaction trace mismatch: have %s", blob)
+	}
+	// Stored traces must not be served if storage is disabled
+	if _, ok := NewAPI(backend).storeIDFor(2, block.Hash(), config); ok {
+		t.Fatal("traces should not be storable with trace storage disabled")
+	}
+	// Stored traces are ignored if the block is not canonical
+	if id, ok := api.storeIDFor(2, common.Hash{1}, config); ok {
+		t.Fatalf("non-canonical block should not be storable, id %x", id)
+	}
+}
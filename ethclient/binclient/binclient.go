@@ -0,0 +1,289 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package binclient provides a client for the binary RPC transport.
+package binclient
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/binrpc"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Client provides the core chain queries of ethclient.Client over the binary RPC
+// transport.
+type Client struct {
+	c *binrpc.Client
+}
+
+// Dial connects a client to the given URL.
+func Dial(rawurl string) (*Client, error) {
+	c, err := binrpc.Dial(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(c), nil
+}
+
+// NewClient creates a client that uses the given binary RPC client.
+func NewClient(c *binrpc.Client) *Client {
+	return &Client{c}
+}
+
+// Close closes the underlying binary RPC connection.
+func (ec *Client) Close() {
+	ec.c.Close()
+}
+
+// Client gets the underlying binary RPC client.
+func (ec *Client) Client() *binrpc.Client {
+	return ec.c
+}
+
+// Blockchain Access
+
+// ChainID retrieves the current chain ID for transaction replay protection.
+func (ec *Client) ChainID(ctx context.Context) (*big.Int, error) {
+	id := new(big.Int)
+	if err := ec.c.Call(ctx, id, binrpc.MethodChainID, nil); err != nil {
+		return nil, err
+	}
+	return id, nil
+}
+
+// BlockNumber returns the most recent block number.
+func (ec *Client) BlockNumber(ctx context.Context) (uint64, error) {
+	var number uint64
+	err := ec.c.Call(ctx, &number, binrpc.MethodBlockNumber, nil)
+	return number, err
+}
+
+// BlockByHash returns the given full block.
+func (ec *Client) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	return ec.getBlock(ctx, binrpc.BlockByHash(hash))
+}
+
+// BlockByNumber returns a block from the current canonical chain. If number is
+// nil, the latest known block is returned.
+func (ec *Client) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	return ec.getBlock(ctx, toBlockID(number))
+}
+
+func (ec *Client) getBlock(ctx context.Context, id binrpc.BlockID) (*types.Block, error) {
+	block := new(types.Block)
+	if err := ec.c.Call(ctx, block, binrpc.MethodBlock, id); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// HeaderByHash returns the block header with the given hash.
+func (ec *Client) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	return ec.getHeader(ctx, binrpc.BlockByHash(hash))
+}
+
+// HeaderByNumber returns a block header from the current canonical chain. If
+// number is nil, the latest known header is returned.
+func (ec *Client) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return ec.getHeader(ctx, toBlockID(number))
+}
+
+func (ec *Client) getHeader(ctx context.Context, id binrpc.BlockID) (*types.Header, error) {
+	header := new(types.Header)
+	if err := ec.c.Call(ctx, header, binrpc.MethodHeader, id); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// BlockReceipts returns the receipts of a given block number or hash.
+func (ec *Client) BlockReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]*types.Receipt, error) {
+	id := binrpc.BlockID{}
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		id = binrpc.BlockByHash(hash)
+	} else if number, ok := blockNrOrHash.Number(); ok {
+		id = binrpc.BlockByNumber(number)
+	}
+	var enc []*binrpc.Receipt
+	if err := ec.c.Call(ctx, &enc, binrpc.MethodReceipts, id); err != nil {
+		return nil, err
+	}
+	receipts := make([]*types.Receipt, len(enc))
+	for i, receipt := range enc {
+		receipts[i] = receipt.Receipt()
+	}
+	return receipts, nil
+}
+
+// TransactionByHash returns the transaction with the given hash.
+func (ec *Client) TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error) {
+	var enc binrpc.Transaction
+	if err := ec.c.Call(ctx, &enc, binrpc.MethodTransaction, hash); err != nil {
+		return nil, false, err
+	}
+	return enc.Tx, enc.Pending, nil
+}
+
+// TransactionReceipt returns the receipt of a transaction by transaction hash.
+// Note that the receipt is not available for pending transactions.
+func (ec *Client) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	var enc binrpc.Receipt
+	if err := ec.c.Call(ctx, &enc, binrpc.MethodReceipt, txHash); err != nil {
+		return nil, err
+	}
+	return enc.Receipt(), nil
+}
+
+// SubscribeNewHead subscribes to notifications about the current blockchain head
+// on the given channel.
+func (ec *Client) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	sub, err := ec.c.Subscribe(ctx, binrpc.SubscriptionNewHeads, nil)
+	if err != nil {
+		return nil, err
+	}
+	return forward(sub, func() (*types.Header, error) {
+		header := new(types.Header)
+		return header, sub.Next(header)
+	}, ch), nil
+}
+
+// State Access
+
+// BalanceAt returns the wei balance of the given account.
+// The block number can be nil, in which case the balance is taken from the latest known block.
+func (ec *Client) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	balance := new(big.Int)
+	if err := ec.c.Call(ctx, balance, binrpc.MethodBalance, &binrpc.AccountQuery{Address: account, Block: toBlockID(blockNumber)}); err != nil {
+		return nil, err
+	}
+	return balance, nil
+}
+
+// StorageAt returns the value of key in the contract storage of the given account.
+// The block number can be nil, in which case the value is taken from the latest known block.
+func (ec *Client) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	var value common.Hash
+	if err := ec.c.Call(ctx, &value, binrpc.MethodStorage, &binrpc.StorageQuery{Address: account, Key: key, Block: toBlockID(blockNumber)}); err != nil {
+		return nil, err
+	}
+	return value[:], nil
+}
+
+// CodeAt returns the contract code of the given account.
+// The block number can be nil, in which case the code is taken from the latest known block.
+func (ec *Client) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	var code []byte
+	err := ec.c.Call(ctx, &code, binrpc.MethodCode, &binrpc.AccountQuery{Address: account, Block: toBlockID(blockNumber)})
+	return code, err
+}
+
+// NonceAt returns the account nonce of the given account.
+// The block number can be nil, in which case the nonce is taken from the latest known block.
+func (ec *Client) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	var nonce uint64
+	err := ec.c.Call(ctx, &nonce, binrpc.MethodNonce, &binrpc.AccountQuery{Address: account, Block: toBlockID(blockNumber)})
+	return nonce, err
+}
+
+// Filters
+
+// FilterLogs executes a filter query.
+func (ec *Client) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	var enc []*binrpc.Log
+	if err := ec.c.Call(ctx, &enc, binrpc.MethodLogs, toFilterQuery(q)); err != nil {
+		return nil, err
+	}
+	logs := make([]types.Log, len(enc))
+	for i, log := range enc {
+		logs[i] = *log.Log()
+	}
+	return logs, nil
+}
+
+// SubscribeFilterLogs subscribes to the results of a streaming filter query.
+func (ec *Client) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	sub, err := ec.c.Subscribe(ctx, binrpc.SubscriptionLogs, toFilterQuery(q))
+	if err != nil {
+		return nil, err
+	}
+	return forward(sub, func() (types.Log, error) {
+		var log binrpc.Log
+		if err := sub.Next(&log); err != nil {
+			return types.Log{}, err
+		}
+		return *log.Log(), nil
+	}, ch), nil
+}
+
+// forward delivers the events of a binary RPC subscription on the given channel.
+func forward[T any](sub *binrpc.Subscription, next func() (T, error), ch chan<- T) ethereum.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		// Reading the next event blocks, interrupt it by closing the stream
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			select {
+			case <-quit:
+			case <-done:
+			}
+			sub.Close()
+		}()
+		for {
+			ev, err := next()
+			if err != nil {
+				select {
+				case <-quit:
+					return nil
+				default:
+					return err
+				}
+			}
+			select {
+			case ch <- ev:
+			case <-quit:
+				return nil
+			}
+		}
+	})
+}
+
+func toBlockID(number *big.Int) binrpc.BlockID {
+	if number == nil {
+		return binrpc.BlockByNumber(rpc.LatestBlockNumber)
+	}
+	return binrpc.BlockByNumber(rpc.BlockNumber(number.Int64()))
+}
+
+func toFilterQuery(q ethereum.FilterQuery) *binrpc.FilterQuery {
+	query := &binrpc.FilterQuery{
+		Addresses: q.Addresses,
+		Topics:    q.Topics,
+	}
+	if q.BlockHash != nil {
+		query.BlockHash = *q.BlockHash
+		return query
+	}
+	// Unset bounds default to the latest block, like in JSON-RPC
+	query.FromBlock = toBlockID(q.FromBlock).Number
+	query.ToBlock = toBlockID(q.ToBlock).Number
+	return query
+}
@@ -0,0 +1,232 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package binclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/binrpc/service"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	testKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr    = crypto.PubkeyToAddress(testKey.PublicKey)
+	testBalance = big.NewInt(2e18)
+
+	// logAddr is a contract emitting an empty log when called.
+	logAddr = common.HexToAddress("0x1000")
+	logCode = common.FromHex("0x60006000a000") // LOG0(0, 0), STOP
+)
+
+func newTestBackend(t *testing.T) (*node.Node, *eth.Ethereum, []*types.Block) {
+	stack, err := node.New(&node.Config{BinaryHost: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("can't create node: %v", err)
+	}
+	genesis := &core.Genesis{
+		Config: params.AllEthashProtocolChanges,
+		Alloc: types.GenesisAlloc{
+			testAddr: {Balance: testBalance},
+			logAddr:  {Code: logCode, Storage: map[common.Hash]common.Hash{{1}: {2}}},
+		},
+		BaseFee: big.NewInt(params.InitialBaseFee),
+	}
+	backend, err := eth.New(stack, &ethconfig.Config{Genesis: genesis})
+	if err != nil {
+		t.Fatalf("can't create eth service: %v", err)
+	}
+	filterSystem := filters.NewFilterSystem(backend.APIBackend, filters.Config{})
+	service.New(stack, backend.APIBackend, filterSystem, 0)
+
+	signer := types.LatestSigner(genesis.Config)
+	blocks, _ := core.GenerateChain(genesis.Config, backend.BlockChain().Genesis(), ethash.NewFaker(), backend.ChainDb(), 2, func(i int, gen *core.BlockGen) {
+		tx := types.MustSignNewTx(testKey, signer, &types.LegacyTx{
+			Nonce:    uint64(i),
+			To:       &logAddr,
+			Gas:      50000,
+			GasPrice: gen.BaseFee(),
+		})
+		gen.AddTxWithChain(backend.BlockChain(), tx)
+	})
+	if _, err := backend.BlockChain().InsertChain(blocks); err != nil {
+		t.Fatalf("can't import test blocks: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("can't start test node: %v", err)
+	}
+	return stack, backend, blocks
+}
+
+func TestBinaryClient(t *testing.T) {
+	stack, _, blocks := newTestBackend(t)
+	defer stack.Close()
+
+	client, err := Dial(stack.BinaryEndpoint())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	if id, err := client.ChainID(ctx); err != nil || id.Cmp(params.AllEthashProtocolChanges.ChainID) != 0 {
+		t.Errorf("chain id mismatch: have %v (%v), want %v", id, err, params.AllEthashProtocolChanges.ChainID)
+	}
+	if number, err := client.BlockNumber(ctx); err != nil || number != 2 {
+		t.Errorf("block number mismatch: have %d (%v), want 2", number, err)
+	}
+	// Blocks and headers
+	block, err := client.BlockByNumber(ctx, big.NewInt(1))
+	if err != nil {
+		t.Fatalf("can't retrieve block: %v", err)
+	}
+	if block.Hash() != blocks[0].Hash() || len(block.Transactions()) != 1 {
+		t.Errorf("block mismatch: have %x with %d txs, want %x", block.Hash(), len(block.Transactions()), blocks[0].Hash())
+	}
+	header, err := client.HeaderByHash(ctx, blocks[1].Hash())
+	if err != nil || header.Hash() != blocks[1].Hash() {
+		t.Errorf("header mismatch: have %v (%v), want %x", header, err, blocks[1].Hash())
+	}
+	if header, err := client.HeaderByNumber(ctx, nil); err != nil || header.Hash() != blocks[1].Hash() {
+		t.Errorf("latest header mismatch: have %v (%v), want %x", header, err, blocks[1].Hash())
+	}
+	if _, err := client.HeaderByNumber(ctx, big.NewInt(100)); !errors.Is(err, ethereum.NotFound) {
+		t.Errorf("missing header: have error %v, want %v", err, ethereum.NotFound)
+	}
+	// Transactions and receipts
+	txHash := blocks[1].Transactions()[0].Hash()
+	tx, pending, err := client.TransactionByHash(ctx, txHash)
+	if err != nil || pending || tx.Hash() != txHash {
+		t.Errorf("transaction mismatch: have %v pending %v (%v), want %x", tx, pending, err, txHash)
+	}
+	receipt, err := client.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		t.Fatalf("can't retrieve receipt: %v", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful || receipt.BlockHash != blocks[1].Hash() || len(receipt.Logs) != 1 {
+		t.Errorf("receipt mismatch: %+v", receipt)
+	}
+	receipts, err := client.BlockReceipts(ctx, rpc.BlockNumberOrHashWithHash(blocks[0].Hash(), false))
+	if err != nil || len(receipts) != 1 || receipts[0].TxHash != blocks[0].Transactions()[0].Hash() {
+		t.Errorf("block receipts mismatch: have %v (%v)", receipts, err)
+	}
+	// Logs
+	logs, err := client.FilterLogs(ctx, ethereum.FilterQuery{FromBlock: big.NewInt(0), Addresses: []common.Address{logAddr}})
+	if err != nil || len(logs) != 2 {
+		t.Fatalf("log count mismatch: have %d (%v), want 2", len(logs), err)
+	}
+	if logs[1].TxHash != txHash || logs[1].BlockNumber != 2 {
+		t.Errorf("log mismatch: %+v", logs[1])
+	}
+	// State
+	if nonce, err := client.NonceAt(ctx, testAddr, nil); err != nil || nonce != 2 {
+		t.Errorf("nonce mismatch: have %d (%v), want 2", nonce, err)
+	}
+	if balance, err := client.BalanceAt(ctx, testAddr, big.NewInt(0)); err != nil || balance.Cmp(testBalance) != 0 {
+		t.Errorf("balance mismatch: have %v (%v), want %v", balance, err, testBalance)
+	}
+	if code, err := client.CodeAt(ctx, logAddr, nil); err != nil || !bytes.Equal(code, logCode) {
+		t.Errorf("code mismatch: have %x (%v), want %x", code, err, logCode)
+	}
+	if value, err := client.StorageAt(ctx, logAddr, common.Hash{1}, nil); err != nil || common.BytesToHash(value) != (common.Hash{2}) {
+		t.Errorf("storage mismatch: have %x (%v)", value, err)
+	}
+}
+
+func TestBinaryClientSubscription(t *testing.T) {
+	stack, backend, blocks := newTestBackend(t)
+	defer stack.Close()
+
+	client, err := Dial(stack.BinaryEndpoint())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	var (
+		heads   = make(chan *types.Header)
+		logs    = make(chan types.Log)
+		ctx     = context.Background()
+		timeout = time.After(5 * time.Second)
+	)
+	headSub, err := client.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		t.Fatalf("can't subscribe to heads: %v", err)
+	}
+	defer headSub.Unsubscribe()
+	logSub, err := client.SubscribeFilterLogs(ctx, ethereum.FilterQuery{Addresses: []common.Address{logAddr}}, logs)
+	if err != nil {
+		t.Fatalf("can't subscribe to logs: %v", err)
+	}
+	defer logSub.Unsubscribe()
+
+	// Extend the chain and check that it's streamed to the client
+	signer := types.LatestSigner(backend.BlockChain().Config())
+	next, _ := core.GenerateChain(backend.BlockChain().Config(), blocks[1], ethash.NewFaker(), backend.ChainDb(), 1, func(i int, gen *core.BlockGen) {
+		tx := types.MustSignNewTx(testKey, signer, &types.LegacyTx{
+			Nonce:    2,
+			To:       &logAddr,
+			Gas:      50000,
+			GasPrice: gen.BaseFee(),
+		})
+		gen.AddTxWithChain(backend.BlockChain(), tx)
+	})
+	if _, err := backend.BlockChain().InsertChain(next); err != nil {
+		t.Fatalf("can't import block: %v", err)
+	}
+	select {
+	case head := <-heads:
+		if head.Hash() != next[0].Hash() {
+			t.Errorf("head mismatch: have %x, want %x", head.Hash(), next[0].Hash())
+		}
+	case err := <-headSub.Err():
+		t.Fatalf("head subscription failed: %v", err)
+	case <-timeout:
+		t.Fatal("head not delivered")
+	}
+	select {
+	case log := <-logs:
+		if log.BlockHash != next[0].Hash() || log.TxHash != next[0].Transactions()[0].Hash() {
+			t.Errorf("log mismatch: %+v", log)
+		}
+	case err := <-logSub.Err():
+		t.Fatalf("log subscription failed: %v", err)
+	case <-timeout:
+		t.Fatal("log not delivered")
+	}
+	// Unsubscribing terminates the stream without an error
+	headSub.Unsubscribe()
+	if err, ok := <-headSub.Err(); ok {
+		t.Errorf("unexpected subscription error: %v", err)
+	}
+}
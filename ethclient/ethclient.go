@@ -335,7 +335,12 @@ func (ec *Client) SyncProgress(ctx context.Context) (*ethereum.SyncProgress, err
 // SubscribeNewHead subscribes to notifications about the current blockchain head
 // on the given channel.
 func (ec *Client) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
-	sub, err := ec.c.EthSubscribe(ctx, ch, "newHeads")
+	// The head at subscription time is the resume point until a head is received
+	start, err := ec.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	sub, err := ec.c.SubscribeResumable(ctx, "eth", ch, resumeNewHeads(start), "newHeads")
 	if err != nil {
 		// Defensively prefer returning nil interface explicitly on error-path, instead
 		// of letting default golang behavior wrap it with non-nil interface that stores
@@ -439,7 +444,12 @@ func (ec *Client) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuer
 	if err != nil {
 		return nil, err
 	}
-	sub, err := ec.c.EthSubscribe(ctx, ch, "logs", arg)
+	// The head at subscription time is the resume point until a log is received
+	start, err := ec.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	sub, err := ec.c.SubscribeResumable(ctx, "eth", ch, resumeLogs(start), "logs", arg)
 	if err != nil {
 		// Defensively prefer returning nil interface explicitly on error-path, instead
 		// of letting default golang behavior wrap it with non-nil interface that stores
@@ -449,6 +459,90 @@ func (ec *Client) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuer
 	return sub, nil
 }
 
+// resumeNewHeads resumes a newHeads subscription after the last received head,
+// or after the given head if none was received.
+func resumeNewHeads(start *types.Header) rpc.ResumeFunc {
+	return func(args []interface{}, last json.RawMessage) ([]interface{}, rpc.SkipFunc, error) {
+		if last == nil {
+			return []interface{}{args[0], resumeAfter(start)}, nil, nil
+		}
+		var head struct {
+			Number *hexutil.Big `json:"number"`
+			Hash   common.Hash  `json:"hash"`
+		}
+		if err := json.Unmarshal(last, &head); err != nil {
+			return nil, nil, err
+		}
+		if head.Number == nil {
+			return nil, nil, errors.New("head without number")
+		}
+		return []interface{}{args[0], map[string]interface{}{
+			"fromBlock":  hexutil.EncodeBig(new(big.Int).Add(head.Number.ToInt(), common.Big1)),
+			"parentHash": head.Hash,
+		}}, nil, nil
+	}
+}
+
+// resumeLogs resumes a logs subscription from the block of the last received
+// log, or after the given head if none was received. The block of the last log
+// is notified again, as removed if it was reorged out in the meantime, and its
+// logs already delivered are skipped.
+func resumeLogs(start *types.Header) rpc.ResumeFunc {
+	return func(args []interface{}, last json.RawMessage) ([]interface{}, rpc.SkipFunc, error) {
+		if last == nil {
+			return []interface{}{args[0], args[1], resumeAfter(start)}, nil, nil
+		}
+		var log types.Log
+		if err := json.Unmarshal(last, &log); err != nil {
+			return nil, nil, err
+		}
+		opts := map[string]interface{}{
+			"fromBlock": hexutil.EncodeUint64(log.BlockNumber),
+			"blockHash": log.BlockHash,
+		}
+		return []interface{}{args[0], args[1], opts}, skipLogs(&log), nil
+	}
+}
+
+// resumeAfter returns the options resuming a subscription after the given head.
+func resumeAfter(head *types.Header) map[string]interface{} {
+	return map[string]interface{}{
+		"fromBlock":  hexutil.EncodeBig(new(big.Int).Add(head.Number, common.Big1)),
+		"parentHash": head.Hash(),
+	}
+}
+
+// skipLogs returns the function dropping the notifications which don't change
+// the logs of the block of the last received log, as seen by the client. The
+// resumed subscription notifies that block again in a single pass, the logs up
+// to the last one were notified like it, the logs after it weren't notified
+// since the block was added or removed. Later notifications aren't skipped.
+func skipLogs(last *types.Log) rpc.SkipFunc {
+	var (
+		done bool
+		prev = -1
+	)
+	return func(raw json.RawMessage) bool {
+		if done {
+			return false
+		}
+		var log struct {
+			BlockHash common.Hash  `json:"blockHash"`
+			Index     hexutil.Uint `json:"logIndex"`
+			Removed   bool         `json:"removed"`
+		}
+		if err := json.Unmarshal(raw, &log); err != nil || log.BlockHash != last.BlockHash || int(log.Index) <= prev {
+			done = true
+			return false
+		}
+		prev = int(log.Index)
+
+		// Skip the log if the client already sees it as notified
+		added := (uint(log.Index) <= last.Index) != last.Removed
+		return added != log.Removed
+	}
+}
+
 func toFilterArg(q ethereum.FilterQuery) (interface{}, error) {
 	arg := map[string]interface{}{
 		"address": q.Addresses,
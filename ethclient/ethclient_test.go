@@ -19,6 +19,7 @@ package ethclient
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"math/big"
 	"reflect"
@@ -179,6 +180,84 @@ func TestToFilterArg(t *testing.T) {
 	}
 }
 
+// Tests that a logs subscription interrupted in the middle of a block is resumed
+// from that block, and only the logs changing the client's view are delivered.
+func TestResumeLogs(t *testing.T) {
+	var (
+		start = &types.Header{Number: big.NewInt(7)}
+		block = common.Hash{0x1}
+	)
+	args, skip, err := resumeLogs(start)([]interface{}{"logs", "crit"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []interface{}{"logs", "crit", map[string]interface{}{"fromBlock": "0x8", "parentHash": start.Hash()}}; !reflect.DeepEqual(args, want) || skip != nil {
+		t.Fatalf("wrong resume args before the first log: have %v, want %v", args, want)
+	}
+	type notification struct {
+		index   uint
+		block   common.Hash
+		removed bool
+	}
+	tests := []struct {
+		last      types.Log
+		notified  []notification
+		delivered []int // indexes in notified
+	}{
+		// The block is still canonical, the logs after the last one are new
+		{
+			last:      types.Log{BlockNumber: 9, BlockHash: block, Index: 1},
+			notified:  []notification{{0, block, false}, {1, block, false}, {2, block, false}, {0, common.Hash{0x2}, false}},
+			delivered: []int{2, 3},
+		},
+		// The block was reorged out, only the logs delivered are removed
+		{
+			last:      types.Log{BlockNumber: 9, BlockHash: block, Index: 1},
+			notified:  []notification{{0, block, true}, {1, block, true}, {2, block, true}, {0, common.Hash{0x2}, false}},
+			delivered: []int{0, 1, 3},
+		},
+		// The block was being removed, and is still reorged out
+		{
+			last:      types.Log{BlockNumber: 9, BlockHash: block, Index: 0, Removed: true},
+			notified:  []notification{{0, block, true}, {1, block, true}, {0, common.Hash{0x2}, false}},
+			delivered: []int{1, 2},
+		},
+		// The block was being removed, and is canonical again
+		{
+			last:      types.Log{BlockNumber: 9, BlockHash: block, Index: 0, Removed: true},
+			notified:  []notification{{0, block, false}, {1, block, false}},
+			delivered: []int{0},
+		},
+		// Later notifications of the block aren't skipped
+		{
+			last:      types.Log{BlockNumber: 9, BlockHash: block, Index: 1},
+			notified:  []notification{{0, block, false}, {1, block, false}, {0, block, true}, {1, block, true}, {0, block, false}},
+			delivered: []int{2, 3, 4},
+		},
+	}
+	for i, test := range tests {
+		test.last.Topics = []common.Hash{}
+		last, _ := json.Marshal(&test.last)
+		args, skip, err := resumeLogs(start)([]interface{}{"logs", "crit"}, last)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		if want := []interface{}{"logs", "crit", map[string]interface{}{"fromBlock": "0x9", "blockHash": block}}; !reflect.DeepEqual(args, want) {
+			t.Fatalf("test %d: wrong resume args: have %v, want %v", i, args, want)
+		}
+		var delivered []int
+		for j, n := range test.notified {
+			raw, _ := json.Marshal(&types.Log{BlockNumber: 9, BlockHash: n.block, Index: n.index, Removed: n.removed})
+			if !skip(raw) {
+				delivered = append(delivered, j)
+			}
+		}
+		if !reflect.DeepEqual(delivered, test.delivered) {
+			t.Errorf("test %d: wrong logs delivered: have %v, want %v", i, delivered, test.delivered)
+		}
+	}
+}
+
 var (
 	testKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
 	testAddr    = crypto.PubkeyToAddress(testKey.PublicKey)
@@ -204,6 +204,39 @@ func (ec *Client) SubscribePendingTransactions(ctx context.Context, ch chan<- co
 	return ec.c.EthSubscribe(ctx, ch, "newPendingTransactions")
 }
 
+// PendingTxFilter restricts the pending transactions delivered by a subscription.
+// A transaction matches if it matches all the non-empty fields, and a field
+// matches if any of its values matches.
+type PendingTxFilter struct {
+	From    []common.Address // senders of the transaction
+	To      []common.Address // recipients of the transaction
+	Methods [][4]byte        // method selectors, the first four bytes of the call data
+	MinTip  *big.Int         // minimum effective tip at the base fee of the next block
+}
+
+// SubscribeFilteredPendingTransactions subscribes to the new pending transactions
+// matching the given filter. The filter is applied by the server.
+func (ec *Client) SubscribeFilteredPendingTransactions(ctx context.Context, filter PendingTxFilter, ch chan<- *types.Transaction) (*rpc.ClientSubscription, error) {
+	arg := map[string]interface{}{"fullTx": true}
+	if len(filter.From) > 0 {
+		arg["from"] = filter.From
+	}
+	if len(filter.To) > 0 {
+		arg["to"] = filter.To
+	}
+	if len(filter.Methods) > 0 {
+		methods := make([]hexutil.Bytes, len(filter.Methods))
+		for i, method := range filter.Methods {
+			methods[i] = method[:]
+		}
+		arg["methods"] = methods
+	}
+	if filter.MinTip != nil {
+		arg["minTip"] = (*hexutil.Big)(filter.MinTip)
+	}
+	return ec.c.EthSubscribe(ctx, ch, "newPendingTransactions", arg)
+}
+
 func toBlockNumArg(number *big.Int) string {
 	if number == nil {
 		return "latest"
@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
@@ -155,6 +156,9 @@ func TestGethClient(t *testing.T) {
 		}, {
 			"TestSetHead",
 			func(t *testing.T) { testSetHead(t, client) },
+		}, {
+			"TestSubscribeFilteredPendingTxs",
+			func(t *testing.T) { testSubscribeFilteredPendingTransactions(t, client) },
 		},
 	}
 	for _, tt := range tests {
@@ -423,6 +427,60 @@ func testSubscribeFullPendingTransactions(t *testing.T, client *rpc.Client) {
 	}
 }
 
+func testSubscribeFilteredPendingTransactions(t *testing.T, client *rpc.Client) {
+	ec := New(client)
+	ethcl := ethclient.NewClient(client)
+
+	transfer := [4]byte{0xa9, 0x05, 0x9c, 0xbb}
+	ch := make(chan *types.Transaction)
+	sub, err := ec.SubscribeFilteredPendingTransactions(context.Background(), PendingTxFilter{
+		From:    []common.Address{testAddr},
+		To:      []common.Address{{2}},
+		Methods: [][4]byte{transfer},
+		MinTip:  big.NewInt(params.GWei),
+	}, ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	chainID, err := ethcl.ChainID(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce, err := ethcl.PendingNonceAt(context.Background(), testAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Send transactions failing each criterion, followed by a matching one
+	signer := types.LatestSignerForChainID(chainID)
+	txs := []*types.LegacyTx{
+		{To: &common.Address{1}, GasPrice: big.NewInt(3 * params.GWei), Data: transfer[:]},
+		{To: &common.Address{2}, GasPrice: big.NewInt(3 * params.GWei), Data: []byte{1, 2, 3, 4}},
+		{To: &common.Address{2}, GasPrice: big.NewInt(params.GWei), Data: transfer[:]},
+		{To: &common.Address{2}, GasPrice: big.NewInt(3 * params.GWei), Data: transfer[:]},
+	}
+	var want common.Hash
+	for i, tx := range txs {
+		tx.Nonce, tx.Gas = nonce+uint64(i), 30000
+		signed := types.MustSignNewTx(testKey, signer, tx)
+		if err := ethcl.SendTransaction(context.Background(), signed); err != nil {
+			t.Fatal(err)
+		}
+		want = signed.Hash()
+	}
+	select {
+	case tx := <-ch:
+		if tx.Hash() != want {
+			t.Fatalf("Invalid tx hash received, got %v, want %v", tx.Hash(), want)
+		}
+	case err := <-sub.Err():
+		t.Fatalf("subscription failed: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("matching transaction not received")
+	}
+}
+
 func testCallContract(t *testing.T, client *rpc.Client) {
 	ec := New(client)
 	msg := ethereum.CallMsg{
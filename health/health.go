@@ -0,0 +1,270 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package health implements the health and readiness endpoints polled by load
+// balancers.
+//
+// The /health endpoint fails if the node is broken: it has too few peers, its
+// chain stopped progressing although it isn't syncing, or it is required to
+// seal blocks but doesn't. The /ready endpoint additionally fails while the node
+// is syncing or its head is stale, i.e. whenever it shouldn't serve requests.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// headAgePeriods is the default maximum age of the chain head, in block
+	// periods of the Congress engine.
+	headAgePeriods = 10
+
+	// defaultMaxHeadAge is the default maximum age of the chain head on chains
+	// without a fixed block period.
+	defaultMaxHeadAge = time.Minute
+
+	// sealingWindow is the number of recent blocks searched for a block sealed
+	// by the local validator.
+	sealingWindow = 64
+)
+
+// Config contains the thresholds of the health checks.
+type Config struct {
+	// MaxHeadAge is the maximum age of the chain head. If zero, it defaults to
+	// 10 block periods on Congress chains and a minute on other chains.
+	MaxHeadAge time.Duration `toml:",omitempty"`
+
+	// MaxBlocksBehind is the number of blocks the node may lag behind the best
+	// known chain before it is considered syncing.
+	MaxBlocksBehind uint64
+
+	// MinPeers is the minimum number of connected peers.
+	MinPeers int
+
+	// RequireSealing makes the checks fail if the node isn't sealing blocks.
+	RequireSealing bool
+}
+
+// DefaultConfig contains the default thresholds of the health checks.
+var DefaultConfig = Config{
+	MaxBlocksBehind: 2,
+	MinPeers:        1,
+}
+
+// Backend is the node backend queried by the health checks.
+type Backend interface {
+	ChainConfig() *params.ChainConfig
+	CurrentHeader() *types.Header
+	HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error)
+	SyncProgress() ethereum.SyncProgress
+	PeerCount() int
+
+	// Sealing returns the address of the local validator and whether it is
+	// sealing blocks. The address is zero if the node isn't a validator.
+	Sealing() (common.Address, bool)
+}
+
+// Report is the response of the health endpoints.
+type Report struct {
+	OK     bool     `json:"ok"`
+	Errors []string `json:"errors,omitempty"`
+
+	Syncing      bool   `json:"syncing"`
+	CurrentBlock uint64 `json:"currentBlock"`
+	HighestBlock uint64 `json:"highestBlock"`
+
+	HeadAge    float64 `json:"headAge"`    // seconds
+	MaxHeadAge float64 `json:"maxHeadAge"` // seconds
+
+	Peers    int `json:"peers"`
+	MinPeers int `json:"minPeers"`
+
+	Sealing *SealingReport `json:"sealing,omitempty"`
+}
+
+// SealingReport is the sealing status of a validator node.
+type SealingReport struct {
+	Validator  common.Address `json:"validator"`
+	Mining     bool           `json:"mining"`
+	LastSealed *uint64        `json:"lastSealed"` // last recent block sealed by the validator
+}
+
+// Service serves the health endpoints of a node.
+type Service struct {
+	backend Backend
+	config  Config
+}
+
+// New creates the health service of an Ethereum node and registers its handlers
+// on the HTTP server of the node.
+func New(stack *node.Node, backend *eth.Ethereum, config Config) *Service {
+	s := NewService(&ethBackend{backend.APIBackend, backend, stack.Server()}, config)
+	stack.RegisterPublicHandler("Health check", "/health", http.HandlerFunc(s.serveHealth))
+	stack.RegisterPublicHandler("Readiness check", "/ready", http.HandlerFunc(s.serveReady))
+	return s
+}
+
+// NewService creates a health service on top of the given backend.
+func NewService(backend Backend, config Config) *Service {
+	return &Service{backend: backend, config: config}
+}
+
+// Health checks whether the node is healthy.
+func (s *Service) Health(ctx context.Context) *Report {
+	return s.check(ctx, false)
+}
+
+// Ready checks whether the node is ready to serve requests.
+func (s *Service) Ready(ctx context.Context) *Report {
+	return s.check(ctx, true)
+}
+
+// maxHeadAge returns the maximum age of the chain head.
+func (s *Service) maxHeadAge() time.Duration {
+	if s.config.MaxHeadAge != 0 {
+		return s.config.MaxHeadAge
+	}
+	if congress := s.backend.ChainConfig().Congress; congress != nil && congress.Period > 0 {
+		return time.Duration(congress.Period*headAgePeriods) * time.Second
+	}
+	return defaultMaxHeadAge
+}
+
+func (s *Service) check(ctx context.Context, ready bool) *Report {
+	var (
+		head     = s.backend.CurrentHeader()
+		progress = s.backend.SyncProgress()
+		maxAge   = s.maxHeadAge()
+		age      = time.Since(time.Unix(int64(head.Time), 0))
+	)
+	if age < 0 {
+		age = 0
+	}
+	r := &Report{
+		CurrentBlock: head.Number.Uint64(),
+		HighestBlock: max(progress.HighestBlock, head.Number.Uint64()),
+		HeadAge:      age.Seconds(),
+		MaxHeadAge:   maxAge.Seconds(),
+		Peers:        s.backend.PeerCount(),
+		MinPeers:     s.config.MinPeers,
+	}
+	r.Syncing = r.HighestBlock-r.CurrentBlock > s.config.MaxBlocksBehind
+
+	// Check the conditions of a healthy node
+	if r.Peers < s.config.MinPeers {
+		r.Errors = append(r.Errors, "too few peers")
+	}
+	// A stale head is expected while syncing, so it only makes the node
+	// unhealthy if it stopped syncing. It is never ready to serve requests though.
+	if age > maxAge && (ready || !r.Syncing) {
+		r.Errors = append(r.Errors, "chain head is stale")
+	}
+	if validator, mining := s.backend.Sealing(); validator != (common.Address{}) {
+		r.Sealing = &SealingReport{
+			Validator:  validator,
+			Mining:     mining,
+			LastSealed: s.lastSealed(ctx, head, validator),
+		}
+		if s.config.RequireSealing && (!mining || r.Sealing.LastSealed == nil) {
+			r.Errors = append(r.Errors, "validator is not sealing")
+		}
+	} else if s.config.RequireSealing {
+		r.Errors = append(r.Errors, "validator is not sealing")
+	}
+	// Check the additional conditions of a node ready to serve requests
+	if ready && r.Syncing {
+		r.Errors = append(r.Errors, "node is syncing")
+	}
+	r.OK = len(r.Errors) == 0
+	return r
+}
+
+// lastSealed returns the number of the last recent block sealed by the given
+// validator, or nil if none was sealed within the sealing window.
+func (s *Service) lastSealed(ctx context.Context, head *types.Header, validator common.Address) *uint64 {
+	for header, n := head, 0; header != nil && n < sealingWindow; n++ {
+		if header.Coinbase == validator {
+			number := header.Number.Uint64()
+			return &number
+		}
+		if header.Number.Sign() == 0 {
+			break
+		}
+		var err error
+		if header, err = s.backend.HeaderByNumber(ctx, rpc.BlockNumber(header.Number.Int64()-1)); err != nil {
+			break
+		}
+	}
+	return nil
+}
+
+func (s *Service) serveHealth(w http.ResponseWriter, r *http.Request) {
+	s.serve(w, r, s.Health)
+}
+
+func (s *Service) serveReady(w http.ResponseWriter, r *http.Request) {
+	s.serve(w, r, s.Ready)
+}
+
+func (s *Service) serve(w http.ResponseWriter, r *http.Request, check func(context.Context) *Report) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	report := check(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.OK {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if r.Method == http.MethodGet {
+		json.NewEncoder(w).Encode(report)
+	}
+}
+
+// ethBackend implements Backend for a full Ethereum node.
+type ethBackend struct {
+	*eth.EthAPIBackend
+	eth    *eth.Ethereum
+	server *p2p.Server
+}
+
+func (b *ethBackend) PeerCount() int {
+	return b.server.PeerCount()
+}
+
+func (b *ethBackend) Sealing() (common.Address, bool) {
+	etherbase, err := b.eth.Etherbase()
+	if err != nil {
+		return common.Address{}, false
+	}
+	return etherbase, b.eth.IsMining()
+}
@@ -0,0 +1,266 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var testValidator = common.HexToAddress("0x1000000000000000000000000000000000000001")
+
+type testBackend struct {
+	headers   []*types.Header
+	highest   uint64
+	peers     int
+	validator common.Address
+	mining    bool
+}
+
+// newTestBackend creates a backend with a chain of the given length, whose head
+// was sealed age ago and every sealer'th block of which was sealed by the test
+// validator.
+func newTestBackend(length int, age time.Duration, sealer int) *testBackend {
+	b := &testBackend{peers: 1}
+	now := uint64(time.Now().Add(-age).Unix())
+	for i := 0; i < length; i++ {
+		header := &types.Header{Number: big.NewInt(int64(i)), Time: now - uint64(3*(length-1-i))}
+		if sealer > 0 && i%sealer == 0 {
+			header.Coinbase = testValidator
+		}
+		b.headers = append(b.headers, header)
+	}
+	return b
+}
+
+func (b *testBackend) ChainConfig() *params.ChainConfig {
+	return &params.ChainConfig{Congress: &params.CongressConfig{Period: 3, Epoch: 200}}
+}
+
+func (b *testBackend) CurrentHeader() *types.Header {
+	return b.headers[len(b.headers)-1]
+}
+
+func (b *testBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	if number < 0 || int(number) >= len(b.headers) {
+		return nil, errors.New("header not found")
+	}
+	return b.headers[number], nil
+}
+
+func (b *testBackend) SyncProgress() ethereum.SyncProgress {
+	return ethereum.SyncProgress{HighestBlock: b.highest}
+}
+
+func (b *testBackend) PeerCount() int { return b.peers }
+
+func (b *testBackend) Sealing() (common.Address, bool) { return b.validator, b.mining }
+
+func TestHealth(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		backend   func() *testBackend
+		config    Config
+		health    []string
+		ready     []string
+		maxAge    float64
+		lastBlock *uint64
+	}{
+		{
+			name:    "ok",
+			backend: func() *testBackend { return newTestBackend(10, 0, 0) },
+			config:  DefaultConfig,
+			maxAge:  30,
+		},
+		{
+			name: "no peers",
+			backend: func() *testBackend {
+				b := newTestBackend(10, 0, 0)
+				b.peers = 0
+				return b
+			},
+			config: DefaultConfig,
+			health: []string{"too few peers"},
+			ready:  []string{"too few peers"},
+			maxAge: 30,
+		},
+		{
+			name:    "stale head",
+			backend: func() *testBackend { return newTestBackend(10, time.Minute, 0) },
+			config:  DefaultConfig,
+			health:  []string{"chain head is stale"},
+			ready:   []string{"chain head is stale"},
+			maxAge:  30,
+		},
+		{
+			name:    "configured head age",
+			backend: func() *testBackend { return newTestBackend(10, time.Minute, 0) },
+			config:  Config{MaxHeadAge: 2 * time.Minute},
+			maxAge:  120,
+		},
+		{
+			name: "syncing",
+			backend: func() *testBackend {
+				b := newTestBackend(10, time.Minute, 0)
+				b.highest = 100
+				return b
+			},
+			config: DefaultConfig,
+			ready:  []string{"chain head is stale", "node is syncing"},
+			maxAge: 30,
+		},
+		{
+			name: "within sync tolerance",
+			backend: func() *testBackend {
+				b := newTestBackend(10, 0, 0)
+				b.highest = 11
+				return b
+			},
+			config: DefaultConfig,
+			maxAge: 30,
+		},
+		{
+			name:    "sealing required but not validator",
+			backend: func() *testBackend { return newTestBackend(10, 0, 0) },
+			config:  Config{RequireSealing: true},
+			health:  []string{"validator is not sealing"},
+			ready:   []string{"validator is not sealing"},
+			maxAge:  30,
+		},
+		{
+			name: "sealing",
+			backend: func() *testBackend {
+				b := newTestBackend(10, 0, 4)
+				b.validator, b.mining = testValidator, true
+				return b
+			},
+			config:    Config{RequireSealing: true},
+			maxAge:    30,
+			lastBlock: newUint64(8),
+		},
+		{
+			name: "sealing stopped",
+			backend: func() *testBackend {
+				b := newTestBackend(10, 0, 4)
+				b.validator = testValidator
+				return b
+			},
+			config:    Config{RequireSealing: true},
+			health:    []string{"validator is not sealing"},
+			ready:     []string{"validator is not sealing"},
+			maxAge:    30,
+			lastBlock: newUint64(8),
+		},
+		{
+			name: "no recent seal",
+			backend: func() *testBackend {
+				b := newTestBackend(100, 0, 0)
+				b.headers[0].Coinbase = testValidator
+				b.validator, b.mining = testValidator, true
+				return b
+			},
+			config: Config{RequireSealing: true},
+			health: []string{"validator is not sealing"},
+			ready:  []string{"validator is not sealing"},
+			maxAge: 30,
+		},
+	}
+	for _, test := range tests {
+		b := test.backend()
+		s := NewService(b, test.config)
+
+		health, ready := s.Health(context.Background()), s.Ready(context.Background())
+		if !reflect.DeepEqual(health.Errors, test.health) {
+			t.Errorf("test %q: wrong health errors %q, want %q", test.name, health.Errors, test.health)
+		}
+		if !reflect.DeepEqual(ready.Errors, test.ready) {
+			t.Errorf("test %q: wrong readiness errors %q, want %q", test.name, ready.Errors, test.ready)
+		}
+		if health.OK != (len(test.health) == 0) || ready.OK != (len(test.ready) == 0) {
+			t.Errorf("test %q: wrong status: health %t, ready %t", test.name, health.OK, ready.OK)
+		}
+		if health.MaxHeadAge != test.maxAge {
+			t.Errorf("test %q: wrong max head age %v, want %v", test.name, health.MaxHeadAge, test.maxAge)
+		}
+		if b.validator == (common.Address{}) {
+			if health.Sealing != nil {
+				t.Errorf("test %q: sealing status reported for non-validator", test.name)
+			}
+		} else if !reflect.DeepEqual(health.Sealing.LastSealed, test.lastBlock) {
+			t.Errorf("test %q: wrong last sealed block %v, want %v", test.name, health.Sealing.LastSealed, test.lastBlock)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	b := newTestBackend(10, 0, 0)
+	b.highest = 100
+	s := NewService(b, DefaultConfig)
+
+	for _, test := range []struct {
+		handler http.HandlerFunc
+		method  string
+		status  int
+	}{
+		{s.serveHealth, http.MethodGet, http.StatusOK},
+		{s.serveReady, http.MethodGet, http.StatusServiceUnavailable},
+		{s.serveReady, http.MethodHead, http.StatusServiceUnavailable},
+		{s.serveHealth, http.MethodPost, http.StatusMethodNotAllowed},
+	} {
+		rec := httptest.NewRecorder()
+		test.handler(rec, httptest.NewRequest(test.method, "/", nil))
+		if rec.Code != test.status {
+			t.Errorf("%s: wrong status %d, want %d", test.method, rec.Code, test.status)
+			continue
+		}
+		if test.method != http.MethodGet {
+			continue
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("wrong content type %q", ct)
+		}
+		var report Report
+		if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+			t.Fatalf("invalid report: %v", err)
+		}
+		if report.CurrentBlock != 9 || report.HighestBlock != 100 || report.Syncing != true {
+			t.Errorf("wrong sync status in report: %+v", report)
+		}
+		if report.OK != (test.status == http.StatusOK) {
+			t.Errorf("report status %t doesn't match HTTP status %d", report.OK, test.status)
+		}
+	}
+}
+
+func newUint64(n uint64) *uint64 { return &n }
@@ -69,6 +69,16 @@ import (
 //
 // Due to the accumulator size limit of 8192, the maximum number of blocks in
 // an Era1 batch is also 8192.
+//
+// Archives of Congress chains, created with NewCongressBuilder, differ in two
+// ways: the accumulator commits to the sealer of every block instead of its
+// total difficulty (see ComputeCongressAccumulator), and a checkpoint index is
+// written just before the accumulator entry:
+//
+//	CheckpointIndex    = { type: [0x08, 0x00], data: rlp(checkpoint-index) }
+//
+// The checkpoint index lists the validator sets of all checkpoints needed to
+// verify the seals of the archived blocks without any other chain data.
 type Builder struct {
 	w        *e2store.Writer
 	startNum *uint64
@@ -78,6 +88,8 @@ type Builder struct {
 	tds      []*big.Int
 	written  int
 
+	congress *congressBuilder // Congress specific state, nil for other chains
+
 	buf    *bytes.Buffer
 	snappy *snappy.Writer
 }
@@ -128,6 +140,11 @@ func (b *Builder) AddRLP(header, body, receipts []byte, number uint64, hash comm
 		return fmt.Errorf("exceeds maximum batch size of %d", MaxEra1Size)
 	}
 
+	if b.congress != nil {
+		if err := b.congress.add(header, number); err != nil {
+			return err
+		}
+	}
 	b.indexes = append(b.indexes, uint64(b.written))
 	b.hashes = append(b.hashes, hash)
 	b.tds = append(b.tds, td)
@@ -161,7 +178,24 @@ func (b *Builder) Finalize() (common.Hash, error) {
 		return common.Hash{}, fmt.Errorf("finalize called on empty builder")
 	}
 	// Compute accumulator root and write entry.
-	root, err := ComputeAccumulator(b.hashes, b.tds)
+	var (
+		root common.Hash
+		err  error
+	)
+	if b.congress != nil {
+		index, err := rlp.EncodeToBytes(&b.congress.index)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("error encoding checkpoint index: %w", err)
+		}
+		n, err := b.w.Write(TypeCheckpointIndex, index)
+		b.written += n
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("error writing checkpoint index: %w", err)
+		}
+		root, err = ComputeCongressAccumulator(b.hashes, b.congress.signers)
+	} else {
+		root, err = ComputeAccumulator(b.hashes, b.tds)
+	}
 	if err != nil {
 		return common.Hash{}, fmt.Errorf("error calculating accumulator root: %w", err)
 	}
@@ -0,0 +1,324 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package era
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/congress"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	ssz "github.com/ferranbt/fastssz"
+)
+
+// Checkpoint is a validator set change recorded in a Congress checkpoint header.
+type Checkpoint struct {
+	Number     uint64           // Number of the checkpoint block
+	Validators []common.Address // Validators authorized to seal the blocks after the checkpoint
+}
+
+// CheckpointIndex lists the validator checkpoints needed to verify the seals of
+// all blocks in a Congress Era1 archive. Unless the archive starts at genesis,
+// the first checkpoint is the one governing the first block of the archive,
+// which is located in a preceding archive.
+type CheckpointIndex struct {
+	Epoch       uint64       // Number of blocks between checkpoints
+	Checkpoints []Checkpoint // Checkpoints in ascending order
+}
+
+// governingCheckpoint returns the number of the checkpoint whose validator set
+// is authorized to seal the given (non-genesis) block.
+func governingCheckpoint(number, epoch uint64) uint64 {
+	return (number - 1) - (number-1)%epoch
+}
+
+// congressBuilder tracks the Congress specific data of an Era1 archive which
+// is being built.
+type congressBuilder struct {
+	initial []common.Address // Validator set governing the first block
+	signers []common.Address // Sealer of each block in the archive
+	index   CheckpointIndex
+}
+
+// NewCongressBuilder returns a Builder producing Era1 archives of a Congress
+// chain. Such archives additionally carry a validator checkpoint index and
+// commit to the block sealers instead of the total difficulty in their
+// accumulator, see ComputeCongressAccumulator.
+//
+// The validators are the ones authorized to seal the first block added to the
+// builder, as recorded by the preceding checkpoint. They are ignored if the
+// archive starts at genesis.
+func NewCongressBuilder(w io.Writer, epoch uint64, validators []common.Address) *Builder {
+	b := NewBuilder(w)
+	b.congress = &congressBuilder{
+		initial: validators,
+		index:   CheckpointIndex{Epoch: epoch},
+	}
+	return b
+}
+
+// add records the sealer and the potential checkpoint of an added header.
+func (c *congressBuilder) add(blob []byte, number uint64) error {
+	var header types.Header
+	if err := rlp.DecodeBytes(blob, &header); err != nil {
+		return fmt.Errorf("error decoding header %d: %w", number, err)
+	}
+	if len(c.signers) == 0 && number > 0 {
+		c.index.Checkpoints = append(c.index.Checkpoints, Checkpoint{
+			Number:     governingCheckpoint(number, c.index.Epoch),
+			Validators: c.initial,
+		})
+	}
+	var signer common.Address
+	if number > 0 {
+		var err error
+		if signer, err = congress.Ecrecover(&header); err != nil {
+			return fmt.Errorf("error recovering signer of block %d: %w", number, err)
+		}
+	}
+	c.signers = append(c.signers, signer)
+
+	if number%c.index.Epoch == 0 {
+		validators, err := congress.CheckpointValidators(&header)
+		if err != nil {
+			return fmt.Errorf("error reading checkpoint %d: %w", number, err)
+		}
+		c.index.Checkpoints = append(c.index.Checkpoints, Checkpoint{Number: number, Validators: validators})
+	}
+	return nil
+}
+
+// ComputeCongressAccumulator calculates the SSZ hash tree root of the Era1
+// accumulator of Congress header records. The total difficulty carries no
+// security weight under proof-of-stake-authority, so instead every record
+// commits to the validator which sealed the block.
+//
+//	congress-record := { block-hash: Bytes32, signer: Bytes20 }
+//	accumulator     := hash_tree_root([]congress-record, 8192)
+func ComputeCongressAccumulator(hashes []common.Hash, signers []common.Address) (common.Hash, error) {
+	if len(hashes) != len(signers) {
+		return common.Hash{}, fmt.Errorf("must have equal number hashes as signers")
+	}
+	if len(hashes) > MaxEra1Size {
+		return common.Hash{}, fmt.Errorf("too many records: have %d, max %d", len(hashes), MaxEra1Size)
+	}
+	hh := ssz.NewHasher()
+	for i := range hashes {
+		rec := congressRecord{hashes[i], signers[i]}
+		root, err := rec.HashTreeRoot()
+		if err != nil {
+			return common.Hash{}, err
+		}
+		hh.Append(root[:])
+	}
+	hh.MerkleizeWithMixin(0, uint64(len(hashes)), uint64(MaxEra1Size))
+	return hh.HashRoot()
+}
+
+// congressRecord is an individual record for a historical Congress header.
+type congressRecord struct {
+	Hash   common.Hash
+	Signer common.Address
+}
+
+// GetTree completes the ssz.HashRoot interface, but is unused.
+func (r *congressRecord) GetTree() (*ssz.Node, error) {
+	return nil, nil
+}
+
+// HashTreeRoot ssz hashes the congressRecord object.
+func (r *congressRecord) HashTreeRoot() ([32]byte, error) {
+	return ssz.HashWithDefaultHasher(r)
+}
+
+// HashTreeRootWith ssz hashes the congressRecord object with a hasher.
+func (r *congressRecord) HashTreeRootWith(hh ssz.HashWalker) (err error) {
+	hh.PutBytes(r.Hash[:])
+	hh.PutBytes(r.Signer[:])
+	hh.Merkleize(0)
+	return
+}
+
+// CheckpointIndex reads the validator checkpoint index of a Congress Era1
+// archive. Nil is returned if the archive was not built for a Congress chain.
+func (e *Era) CheckpointIndex() (*CheckpointIndex, error) {
+	entry, err := e.s.Find(TypeCheckpointIndex)
+	if err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	index := new(CheckpointIndex)
+	if err := rlp.DecodeBytes(entry.Value, index); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint index: %w", err)
+	}
+	if index.Epoch == 0 {
+		return nil, errors.New("invalid checkpoint index: zero epoch")
+	}
+	return index, nil
+}
+
+// VerifyCongress fully verifies a Congress Era1 archive. The transaction,
+// receipt and withdrawal roots of every block are recomputed, every seal is
+// checked against the validator set of the governing checkpoint, the
+// checkpoint index is matched against the checkpoint headers and finally the
+// accumulator is recomputed.
+//
+// The validators, if non-nil, are the ones expected to govern the first block
+// of the archive, typically the last set returned when verifying the previous
+// archive. The validator set in effect after the last block is returned.
+func (e *Era) VerifyCongress(validators []common.Address) ([]common.Address, error) {
+	index, err := e.CheckpointIndex()
+	if err != nil {
+		return nil, err
+	}
+	if index == nil {
+		return nil, errors.New("not a congress archive")
+	}
+	want, err := e.Accumulator()
+	if err != nil {
+		return nil, fmt.Errorf("error reading accumulator: %w", err)
+	}
+	var (
+		checkpoints = index.Checkpoints
+		current     []common.Address
+		hashes      []common.Hash
+		signers     []common.Address
+	)
+	if e.Start() > 0 {
+		if len(checkpoints) == 0 || checkpoints[0].Number != governingCheckpoint(e.Start(), index.Epoch) {
+			return nil, fmt.Errorf("missing governing checkpoint of block %d", e.Start())
+		}
+		if validators != nil && !sameValidators(validators, checkpoints[0].Validators) {
+			return nil, fmt.Errorf("governing checkpoint %d mismatch", checkpoints[0].Number)
+		}
+		current, checkpoints = checkpoints[0].Validators, checkpoints[1:]
+	}
+	it, err := NewIterator(e)
+	if err != nil {
+		return nil, fmt.Errorf("error making era iterator: %w", err)
+	}
+	for it.Next() {
+		if it.Error() != nil {
+			return nil, fmt.Errorf("error reading block %d: %w", it.Number(), it.Error())
+		}
+		block, receipts, err := it.BlockAndReceipts()
+		if err != nil {
+			return nil, fmt.Errorf("error reading block %d: %w", it.Number(), err)
+		}
+		if err := verifyBlockRoots(block, receipts); err != nil {
+			return nil, err
+		}
+		var (
+			header = block.Header()
+			number = block.NumberU64()
+			signer common.Address
+		)
+		if number > 0 {
+			if signer, err = congress.Ecrecover(header); err != nil {
+				return nil, fmt.Errorf("error recovering signer of block %d: %w", number, err)
+			}
+			if signer != header.Coinbase {
+				return nil, fmt.Errorf("block %d sealed by %s, coinbase %s", number, signer, header.Coinbase)
+			}
+			if want := sealDifficulty(current, number, signer); want == nil {
+				return nil, fmt.Errorf("block %d sealed by unauthorized validator %s", number, signer)
+			} else if want.Cmp(header.Difficulty) != 0 {
+				return nil, fmt.Errorf("block %d difficulty mismatch: have %d, want %d", number, header.Difficulty, want)
+			}
+		}
+		if number%index.Epoch == 0 {
+			have, err := congress.CheckpointValidators(header)
+			if err != nil {
+				return nil, fmt.Errorf("error reading checkpoint %d: %w", number, err)
+			}
+			if len(checkpoints) == 0 || checkpoints[0].Number != number || !sameValidators(have, checkpoints[0].Validators) {
+				return nil, fmt.Errorf("checkpoint %d missing from or mismatching index", number)
+			}
+			current, checkpoints = have, checkpoints[1:]
+		}
+		hashes = append(hashes, block.Hash())
+		signers = append(signers, signer)
+	}
+	if len(checkpoints) != 0 {
+		return nil, fmt.Errorf("dangling checkpoint %d in index", checkpoints[0].Number)
+	}
+	got, err := ComputeCongressAccumulator(hashes, signers)
+	if err != nil {
+		return nil, fmt.Errorf("error computing accumulator: %w", err)
+	}
+	if got != want {
+		return nil, fmt.Errorf("expected accumulator root does not match calculated: got %s, want %s", got, want)
+	}
+	return current, nil
+}
+
+// verifyBlockRoots checks that the body and receipts of a block match the
+// commitments in its header.
+func verifyBlockRoots(block *types.Block, receipts types.Receipts) error {
+	if tr := types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)); tr != block.TxHash() {
+		return fmt.Errorf("tx root in block %d mismatch: want %s, got %s", block.NumberU64(), block.TxHash(), tr)
+	}
+	if rr := types.DeriveSha(receipts, trie.NewStackTrie(nil)); rr != block.ReceiptHash() {
+		return fmt.Errorf("receipt root in block %d mismatch: want %s, got %s", block.NumberU64(), block.ReceiptHash(), rr)
+	}
+	if hash := block.Header().WithdrawalsHash; hash != nil {
+		if wr := types.DeriveSha(block.Withdrawals(), trie.NewStackTrie(nil)); wr != *hash {
+			return fmt.Errorf("withdrawals root in block %d mismatch: want %s, got %s", block.NumberU64(), *hash, wr)
+		}
+	}
+	return nil
+}
+
+// sealDifficulty returns the difficulty a block sealed by the signer must have,
+// or nil if the signer is not an authorized validator.
+func sealDifficulty(validators []common.Address, number uint64, signer common.Address) *big.Int {
+	sorted := make([]common.Address, len(validators))
+	copy(sorted, validators)
+	sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(sorted[i][:], sorted[j][:]) < 0 })
+
+	for offset, validator := range sorted {
+		if validator != signer {
+			continue
+		}
+		if number%uint64(len(sorted)) == uint64(offset) {
+			return big.NewInt(2)
+		}
+		return big.NewInt(1)
+	}
+	return nil
+}
+
+// sameValidators reports whether two validator lists are equal.
+func sameValidators(a, b []common.Address) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
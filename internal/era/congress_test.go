@@ -0,0 +1,182 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package era
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"math/big"
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/congress"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// congressChain generates a chain of sealed Congress blocks. The validator set
+// is rotated at every checkpoint, dropping the first validator.
+func congressChain(t *testing.T, n int, epoch uint64, unauthorized uint64) []*types.Block {
+	keys := make(map[common.Address]*ecdsa.PrivateKey)
+	var validators []common.Address
+	for i := 0; i < 6; i++ {
+		key, _ := crypto.GenerateKey()
+		addr := crypto.PubkeyToAddress(key.PublicKey)
+		keys[addr] = key
+		validators = append(validators, addr)
+	}
+	sort.Slice(validators, func(i, j int) bool { return bytes.Compare(validators[i][:], validators[j][:]) < 0 })
+
+	var (
+		blocks  []*types.Block
+		current = validators[:3]
+		parent  common.Hash
+	)
+	for i := 0; i < n; i++ {
+		number := uint64(i)
+		header := &types.Header{
+			ParentHash:  parent,
+			UncleHash:   types.EmptyUncleHash,
+			Root:        types.EmptyRootHash,
+			TxHash:      types.EmptyTxsHash,
+			ReceiptHash: types.EmptyReceiptsHash,
+			Number:      new(big.Int).SetUint64(number),
+			GasLimit:    8_000_000,
+			Time:        number * 3,
+			Difficulty:  big.NewInt(2),
+			Extra:       make([]byte, 32),
+		}
+		next := current
+		if number%epoch == 0 {
+			if number > 0 {
+				next = validators[number/epoch : number/epoch+3]
+			}
+			for _, validator := range next {
+				header.Extra = append(header.Extra, validator[:]...)
+			}
+		}
+		header.Extra = append(header.Extra, make([]byte, 65)...)
+		if number > 0 {
+			signer := current[number%uint64(len(current))]
+			if number == unauthorized {
+				signer = validators[len(validators)-1]
+			}
+			header.Coinbase = signer
+			sig, err := crypto.Sign(congress.SealHash(header).Bytes(), keys[signer])
+			if err != nil {
+				t.Fatalf("failed to seal block %d: %v", number, err)
+			}
+			copy(header.Extra[len(header.Extra)-65:], sig)
+		}
+		block := types.NewBlockWithHeader(header)
+		blocks = append(blocks, block)
+		parent, current = block.Hash(), next
+	}
+	return blocks
+}
+
+// buildCongressEra writes the blocks into a Congress Era1 archive.
+func buildCongressEra(t *testing.T, blocks []*types.Block, epoch uint64, validators []common.Address) *Era {
+	f, err := os.CreateTemp(t.TempDir(), "era1-congress")
+	if err != nil {
+		t.Fatalf("error creating temp file: %v", err)
+	}
+	defer f.Close()
+
+	builder := NewCongressBuilder(f, epoch, validators)
+	for _, block := range blocks {
+		if err := builder.Add(block, types.Receipts{}, big.NewInt(0)); err != nil {
+			t.Fatalf("error adding block %d: %v", block.NumberU64(), err)
+		}
+	}
+	root, err := builder.Finalize()
+	if err != nil {
+		t.Fatalf("error finalizing era1: %v", err)
+	}
+	e, err := Open(f.Name())
+	if err != nil {
+		t.Fatalf("failed to open era: %v", err)
+	}
+	t.Cleanup(func() { e.Close() })
+
+	if have, err := e.Accumulator(); err != nil || have != root {
+		t.Fatalf("accumulator mismatch: have %x, want %x, err %v", have, root, err)
+	}
+	return e
+}
+
+func TestCongressEra(t *testing.T) {
+	const epoch = 4
+
+	var (
+		blocks  = congressChain(t, 14, epoch, 0)
+		first   = buildCongressEra(t, blocks[:6], epoch, nil)
+		initial []common.Address
+	)
+	// The second archive is governed by checkpoint 4 from the first archive
+	initial, _ = congress.CheckpointValidators(blocks[4].Header())
+	second := buildCongressEra(t, blocks[6:], epoch, initial)
+
+	index, err := second.CheckpointIndex()
+	if err != nil || index == nil {
+		t.Fatalf("failed to read checkpoint index: %v", err)
+	}
+	if index.Epoch != epoch || len(index.Checkpoints) != 3 {
+		t.Fatalf("checkpoint index mismatch: epoch %d, checkpoints %d", index.Epoch, len(index.Checkpoints))
+	}
+	for i, want := range []uint64{4, 8, 12} {
+		if have := index.Checkpoints[i].Number; have != want {
+			t.Fatalf("checkpoint %d number mismatch: have %d, want %d", i, have, want)
+		}
+	}
+	// Verify the archives, chaining the validator set across them
+	validators, err := first.VerifyCongress(nil)
+	if err != nil {
+		t.Fatalf("failed to verify first archive: %v", err)
+	}
+	last, err := second.VerifyCongress(validators)
+	if err != nil {
+		t.Fatalf("failed to verify second archive: %v", err)
+	}
+	if want, _ := congress.CheckpointValidators(blocks[12].Header()); !sameValidators(last, want) {
+		t.Fatalf("final validator set mismatch: have %v, want %v", last, want)
+	}
+	// A mismatching validator set from the previous archive must be rejected
+	if _, err := second.VerifyCongress(validators[1:]); err == nil {
+		t.Fatal("expected governing checkpoint mismatch")
+	}
+	// Blocks must retain their full body
+	block, err := second.GetBlockByNumber(9)
+	if err != nil {
+		t.Fatalf("failed to read block: %v", err)
+	}
+	if block.Hash() != blocks[9].Hash() {
+		t.Fatalf("block hash mismatch: have %x, want %x", block.Hash(), blocks[9].Hash())
+	}
+}
+
+func TestCongressEraUnauthorized(t *testing.T) {
+	const epoch = 4
+
+	blocks := congressChain(t, 8, epoch, 5)
+	e := buildCongressEra(t, blocks, epoch, nil)
+	if _, err := e.VerifyCongress(nil); err == nil {
+		t.Fatal("expected failure on block sealed by unauthorized validator")
+	}
+}
@@ -18,6 +18,7 @@ package ethapi
 
 import (
 	"context"
+	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -827,6 +828,9 @@ func (s *BlockChainAPI) GetBlockByNumber(ctx context.Context, number rpc.BlockNu
 				response[field] = nil
 			}
 		}
+		if err == nil && number >= 0 {
+			rpc.CacheResponse(ctx, block.NumberU64())
+		}
 		return response, err
 	}
 	return nil, err
@@ -837,6 +841,7 @@ func (s *BlockChainAPI) GetBlockByNumber(ctx context.Context, number rpc.BlockNu
 func (s *BlockChainAPI) GetBlockByHash(ctx context.Context, hash common.Hash, fullTx bool) (map[string]interface{}, error) {
 	block, err := s.b.BlockByHash(ctx, hash)
 	if block != nil {
+		rpc.CacheResponse(ctx, block.NumberU64())
 		return s.rpcMarshalBlock(ctx, block, true, fullTx)
 	}
 	return nil, err
@@ -1303,28 +1308,30 @@ func (s *BlockChainAPI) rpcMarshalBlock(ctx context.Context, b *types.Block, inc
 
 // RPCTransaction represents a transaction that will serialize to the RPC representation of a transaction
 type RPCTransaction struct {
-	BlockHash           *common.Hash      `json:"blockHash"`
-	BlockNumber         *hexutil.Big      `json:"blockNumber"`
-	From                common.Address    `json:"from"`
-	Gas                 hexutil.Uint64    `json:"gas"`
-	GasPrice            *hexutil.Big      `json:"gasPrice"`
-	GasFeeCap           *hexutil.Big      `json:"maxFeePerGas,omitempty"`
-	GasTipCap           *hexutil.Big      `json:"maxPriorityFeePerGas,omitempty"`
-	MaxFeePerBlobGas    *hexutil.Big      `json:"maxFeePerBlobGas,omitempty"`
-	Hash                common.Hash       `json:"hash"`
-	Input               hexutil.Bytes     `json:"input"`
-	Nonce               hexutil.Uint64    `json:"nonce"`
-	To                  *common.Address   `json:"to"`
-	TransactionIndex    *hexutil.Uint64   `json:"transactionIndex"`
-	Value               *hexutil.Big      `json:"value"`
-	Type                hexutil.Uint64    `json:"type"`
-	Accesses            *types.AccessList `json:"accessList,omitempty"`
-	ChainID             *hexutil.Big      `json:"chainId,omitempty"`
-	BlobVersionedHashes []common.Hash     `json:"blobVersionedHashes,omitempty"`
-	V                   *hexutil.Big      `json:"v"`
-	R                   *hexutil.Big      `json:"r"`
-	S                   *hexutil.Big      `json:"s"`
-	YParity             *hexutil.Uint64   `json:"yParity,omitempty"`
+	BlockHash           *common.Hash                 `json:"blockHash"`
+	BlockNumber         *hexutil.Big                 `json:"blockNumber"`
+	From                common.Address               `json:"from"`
+	Gas                 hexutil.Uint64               `json:"gas"`
+	GasPrice            *hexutil.Big                 `json:"gasPrice"`
+	GasFeeCap           *hexutil.Big                 `json:"maxFeePerGas,omitempty"`
+	GasTipCap           *hexutil.Big                 `json:"maxPriorityFeePerGas,omitempty"`
+	MaxFeePerBlobGas    *hexutil.Big                 `json:"maxFeePerBlobGas,omitempty"`
+	Hash                common.Hash                  `json:"hash"`
+	Input               hexutil.Bytes                `json:"input"`
+	Nonce               hexutil.Uint64               `json:"nonce"`
+	To                  *common.Address              `json:"to"`
+	TransactionIndex    *hexutil.Uint64              `json:"transactionIndex"`
+	Value               *hexutil.Big                 `json:"value"`
+	Type                hexutil.Uint64               `json:"type"`
+	Accesses            *types.AccessList            `json:"accessList,omitempty"`
+	ChainID             *hexutil.Big                 `json:"chainId,omitempty"`
+	BlobVersionedHashes []common.Hash                `json:"blobVersionedHashes,omitempty"`
+	AuthorizationList   []types.SetCodeAuthorization `json:"authorizationList,omitempty"`
+	Paymaster           *common.Address              `json:"paymaster,omitempty"`
+	V                   *hexutil.Big                 `json:"v"`
+	R                   *hexutil.Big                 `json:"r"`
+	S                   *hexutil.Big                 `json:"s"`
+	YParity             *hexutil.Uint64              `json:"yParity,omitempty"`
 }
 
 // newRPCTransaction returns a transaction that will serialize to the RPC
@@ -1399,6 +1406,38 @@ func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber
 		}
 		result.MaxFeePerBlobGas = (*hexutil.Big)(tx.BlobGasFeeCap())
 		result.BlobVersionedHashes = tx.BlobHashes()
+
+	case types.SetCodeTxType:
+		al := tx.AccessList()
+		yparity := hexutil.Uint64(v.Sign())
+		result.Accesses = &al
+		result.ChainID = (*hexutil.Big)(tx.ChainId())
+		result.YParity = &yparity
+		result.GasFeeCap = (*hexutil.Big)(tx.GasFeeCap())
+		result.GasTipCap = (*hexutil.Big)(tx.GasTipCap())
+		// if the transaction has been mined, compute the effective gas price
+		if baseFee != nil && blockHash != (common.Hash{}) {
+			result.GasPrice = (*hexutil.Big)(effectiveGasPrice(tx, baseFee))
+		} else {
+			result.GasPrice = (*hexutil.Big)(tx.GasFeeCap())
+		}
+		result.AuthorizationList = tx.SetCodeAuthorizations()
+
+	case types.SponsoredTxType:
+		al := tx.AccessList()
+		yparity := hexutil.Uint64(v.Sign())
+		result.Accesses = &al
+		result.ChainID = (*hexutil.Big)(tx.ChainId())
+		result.YParity = &yparity
+		result.GasFeeCap = (*hexutil.Big)(tx.GasFeeCap())
+		result.GasTipCap = (*hexutil.Big)(tx.GasTipCap())
+		// if the transaction has been mined, compute the effective gas price
+		if baseFee != nil && blockHash != (common.Hash{}) {
+			result.GasPrice = (*hexutil.Big)(effectiveGasPrice(tx, baseFee))
+		} else {
+			result.GasPrice = (*hexutil.Big)(tx.GasFeeCap())
+		}
+		result.Paymaster = tx.Paymaster()
 	}
 	return result
 }
@@ -1655,6 +1694,105 @@ func (s *TransactionAPI) GetRawTransactionByHash(ctx context.Context, hash commo
 	return tx.MarshalBinary()
 }
 
+const (
+	// defaultAddressTxLimit is the number of transactions returned by
+	// eth_getTransactionsByAddress if no limit is requested.
+	defaultAddressTxLimit = 100
+
+	// maxAddressTxLimit is the maximum number of transactions returned by a
+	// single eth_getTransactionsByAddress call.
+	maxAddressTxLimit = 1000
+)
+
+// AddressTransactionsArgs represents the arguments to filter and paginate the
+// transactions of an address.
+type AddressTransactionsArgs struct {
+	FromBlock *hexutil.Uint64 `json:"fromBlock"`
+	ToBlock   *hexutil.Uint64 `json:"toBlock"`
+	Limit     *hexutil.Uint   `json:"limit"`
+	Cursor    *hexutil.Bytes  `json:"cursor"`
+}
+
+// AddressTransactionsResult is a page of transactions of an address. The cursor
+// is nil if there are no more transactions in the requested range.
+type AddressTransactionsResult struct {
+	Transactions []*RPCTransaction `json:"transactions"`
+	Cursor       *hexutil.Bytes    `json:"cursor"`
+}
+
+// GetTransactionsByAddress returns the canonical transactions sent by or to the
+// given address in chain order. A contract creation is attributed to the
+// address of the created contract. Addresses only reached through internal
+// calls are not indexed.
+//
+// The results are paginated, the cursor of a page can be passed to retrieve the
+// next one. A page may contain fewer transactions than the limit while further
+// pages are still available.
+func (s *TransactionAPI) GetTransactionsByAddress(ctx context.Context, address common.Address, args *AddressTransactionsArgs) (*AddressTransactionsResult, error) {
+	if args == nil {
+		args = new(AddressTransactionsArgs)
+	}
+	var (
+		from  uint64
+		index uint32
+		to    = s.b.CurrentHeader().Number.Uint64()
+		limit = defaultAddressTxLimit
+	)
+	if args.FromBlock != nil {
+		from = uint64(*args.FromBlock)
+	}
+	if args.ToBlock != nil && uint64(*args.ToBlock) < to {
+		to = uint64(*args.ToBlock)
+	}
+	if args.Limit != nil {
+		if *args.Limit == 0 || *args.Limit > maxAddressTxLimit {
+			return nil, fmt.Errorf("invalid limit %d, must be between 1 and %d", *args.Limit, maxAddressTxLimit)
+		}
+		limit = int(*args.Limit)
+	}
+	if args.Cursor != nil {
+		cursor := *args.Cursor
+		if len(cursor) != 12 {
+			return nil, errors.New("invalid cursor")
+		}
+		from, index = binary.BigEndian.Uint64(cursor), binary.BigEndian.Uint32(cursor[8:])
+	}
+	result := &AddressTransactionsResult{Transactions: []*RPCTransaction{}}
+	if from > to {
+		return result, nil
+	}
+	entries, err := s.b.GetAddressTransactions(ctx, address, from, index, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	var block *types.Block
+	for _, entry := range entries {
+		if block == nil || block.NumberU64() != entry.BlockNumber {
+			if block, err = s.b.BlockByNumber(ctx, rpc.BlockNumber(entry.BlockNumber)); err != nil {
+				return nil, err
+			}
+		}
+		// Skip the entries which are not consistent with the canonical chain,
+		// they were overwritten by a reorg that happened meanwhile.
+		if block == nil {
+			continue
+		}
+		txs := block.Transactions()
+		if int(entry.Index) >= len(txs) || txs[entry.Index].Hash() != entry.Hash {
+			continue
+		}
+		result.Transactions = append(result.Transactions, newRPCTransactionFromBlockIndex(block, uint64(entry.Index), s.b.ChainConfig()))
+	}
+	if len(entries) == limit {
+		last := entries[len(entries)-1]
+		cursor := make(hexutil.Bytes, 12)
+		binary.BigEndian.PutUint64(cursor, last.BlockNumber)
+		binary.BigEndian.PutUint32(cursor[8:], last.Index+1)
+		result.Cursor = &cursor
+	}
+	return result, nil
+}
+
 // GetTransactionReceipt returns the transaction receipt for the given transaction hash.
 func (s *TransactionAPI) GetTransactionReceipt(ctx context.Context, hash common.Hash) (map[string]interface{}, error) {
 	found, tx, blockHash, blockNumber, index, err := s.b.GetTransaction(ctx, hash)
@@ -1679,6 +1817,7 @@ func (s *TransactionAPI) GetTransactionReceipt(ctx context.Context, hash common.
 
 	// Derive the sender.
 	signer := types.MakeSigner(s.b.ChainConfig(), header.Number, header.Time)
+	rpc.CacheResponse(ctx, blockNumber)
 	return marshalReceipt(receipt, blockHash, blockNumber, signer, tx, int(index)), nil
 }
 
@@ -1748,6 +1887,11 @@ func SubmitTransaction(ctx context.Context, b Backend, tx *types.Transaction) (c
 		// Ensure only eip155 signed transactions are submitted if EIP155Required is set.
 		return common.Hash{}, errors.New("only replay-protected (EIP-155) transactions allowed over RPC")
 	}
+	if tx.Type() == types.BlobTxType {
+		if head := b.CurrentHeader(); b.ChainConfig().IsBlobDisabled(head.Number, head.Time) {
+			return common.Hash{}, core.ErrBlobTxsDisabled
+		}
+	}
 	if err := b.SendTx(ctx, tx); err != nil {
 		return common.Hash{}, err
 	}
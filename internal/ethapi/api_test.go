@@ -589,6 +589,9 @@ func (b testBackend) GetTransaction(ctx context.Context, txHash common.Hash) (bo
 	tx, blockHash, blockNumber, index := rawdb.ReadTransaction(b.db, txHash)
 	return true, tx, blockHash, blockNumber, index, nil
 }
+func (b testBackend) GetAddressTransactions(ctx context.Context, address common.Address, from uint64, index uint32, to uint64, limit int) ([]rawdb.AddressTxEntry, error) {
+	return rawdb.ReadAddressTxEntries(b.db, address, from, index, to, limit), nil
+}
 func (b testBackend) GetPoolTransactions() (types.Transactions, error)         { panic("implement me") }
 func (b testBackend) GetPoolTransaction(txHash common.Hash) *types.Transaction { panic("implement me") }
 func (b testBackend) GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error) {
@@ -780,6 +783,40 @@ func TestEstimateGas(t *testing.T) {
 	}
 }
 
+// Tests that plain transfers are estimated at the intrinsic gas of the active
+// gas schedule.
+func TestEstimateGasSchedule(t *testing.T) {
+	t.Parallel()
+
+	config := *params.MergedTestChainConfig
+	config.GasSchedules = []*params.GasSchedule{{Intrinsic: map[string]uint64{params.IntrinsicTxGas: 30000}}}
+	var (
+		accounts = newAccounts(2)
+		genesis  = &core.Genesis{
+			Config: &config,
+			Alloc: types.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+			},
+		}
+	)
+	api := NewBlockChainAPI(newTestBackend(t, 1, genesis, beacon.New(ethash.NewFaker()), func(i int, b *core.BlockGen) {
+		b.SetPoS()
+	}))
+	latest := rpc.LatestBlockNumber
+	call := TransactionArgs{
+		From:  &accounts[0].addr,
+		To:    &accounts[1].addr,
+		Value: (*hexutil.Big)(big.NewInt(1000)),
+	}
+	result, err := api.EstimateGas(context.Background(), call, &rpc.BlockNumberOrHash{BlockNumber: &latest}, nil)
+	if err != nil {
+		t.Fatalf("failed to estimate gas: %v", err)
+	}
+	if result != 30000 {
+		t.Fatalf("wrong estimate: have %d, want %d", result, 30000)
+	}
+}
+
 func TestCall(t *testing.T) {
 	t.Parallel()
 	// Initialize test accounts
@@ -76,3 +76,20 @@ func (e *TxIndexingError) ErrorCode() int {
 
 // ErrorData returns the hex encoded revert reason.
 func (e *TxIndexingError) ErrorData() interface{} { return "transaction indexing is in progress" }
+
+// PrunedHistoryError is an API error that indicates the requested block bodies,
+// receipts or logs were pruned from the database of the node.
+type PrunedHistoryError struct{}
+
+// NewPrunedHistoryError creates a PrunedHistoryError instance.
+func NewPrunedHistoryError() *PrunedHistoryError { return &PrunedHistoryError{} }
+
+// Error implement error interface, returning the error message.
+func (e *PrunedHistoryError) Error() string {
+	return "pruned history unavailable"
+}
+
+// ErrorCode returns the JSON error code for pruned history.
+func (e *PrunedHistoryError) ErrorCode() int {
+	return 4444
+}
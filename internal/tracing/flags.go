@@ -0,0 +1,67 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracing
+
+import (
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	tracingFlag = &cli.BoolFlag{
+		Name:     "tracing",
+		Usage:    "Enable OpenTelemetry tracing of RPC calls, block import and mining",
+		Category: flags.MetricsCategory,
+	}
+	tracingEndpointFlag = &cli.StringFlag{
+		Name:     "tracing.endpoint",
+		Usage:    "OTLP/HTTP endpoint of the trace collector",
+		Value:    "http://localhost:4318/v1/traces",
+		Category: flags.MetricsCategory,
+	}
+	tracingSampleFlag = &cli.Float64Flag{
+		Name:     "tracing.sample",
+		Usage:    "Fraction of the traces which are sampled (0-1)",
+		Value:    1,
+		Category: flags.MetricsCategory,
+	}
+)
+
+// Flags holds all command-line flags required for tracing.
+var Flags = []cli.Flag{
+	tracingFlag,
+	tracingEndpointFlag,
+	tracingSampleFlag,
+}
+
+// Setup enables tracing if requested by the command-line flags.
+func Setup(ctx *cli.Context) error {
+	if !ctx.Bool(tracingFlag.Name) {
+		return nil
+	}
+	return Enable(Config{
+		Endpoint:    ctx.String(tracingEndpointFlag.Name),
+		SampleRatio: ctx.Float64(tracingSampleFlag.Name),
+		ServiceName: ctx.App.Name,
+	})
+}
+
+// Exit flushes the pending spans. It should be called as late as possible
+// before the program exits.
+func Exit() {
+	Disable()
+}
@@ -0,0 +1,168 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package tracing implements OpenTelemetry tracing of RPC calls, block import and
+// block production.
+//
+// Spans are created through the global OpenTelemetry tracer provider, which is a
+// no-op until tracing is enabled, so instrumented code paths don't pay for the
+// tracing of disabled nodes.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// shutdownTimeout is the time allowed for flushing the pending spans on exit.
+const shutdownTimeout = 5 * time.Second
+
+// Config contains the settings of the span exporter.
+type Config struct {
+	// Endpoint is the URL of the OTLP/HTTP traces endpoint of the collector,
+	// e.g. http://localhost:4318/v1/traces.
+	Endpoint string
+
+	// SampleRatio is the fraction of the root spans which are sampled. Child
+	// spans follow the sampling decision of their parent.
+	SampleRatio float64
+
+	// ServiceName is the service name reported to the collector.
+	ServiceName string
+}
+
+var (
+	providerLock sync.Mutex
+	provider     *sdktrace.TracerProvider
+)
+
+// Enable installs a tracer provider exporting the spans to an OTLP collector.
+func Enable(config Config) error {
+	u, err := url.Parse(config.Endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid tracing endpoint: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid tracing endpoint %q: scheme must be http or https", config.Endpoint)
+	}
+	if config.SampleRatio < 0 || config.SampleRatio > 1 {
+		return fmt.Errorf("invalid tracing sample ratio %v", config.SampleRatio)
+	}
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(u.Host)}
+	if u.Path != "" && u.Path != "/" {
+		opts = append(opts, otlptracehttp.WithURLPath(u.Path))
+	}
+	if u.Scheme == "http" {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(context.Background(), opts...)
+	if err != nil {
+		return err
+	}
+	res := resource.NewSchemaless(
+		semconv.ServiceName(config.ServiceName),
+		semconv.ServiceVersion(params.VersionWithMeta),
+	)
+	install(sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	))
+	log.Info("Enabled OpenTelemetry tracing", "endpoint", config.Endpoint, "sample", config.SampleRatio)
+	return nil
+}
+
+// install sets the global tracer provider, shutting down the previous one.
+func install(tp *sdktrace.TracerProvider) {
+	providerLock.Lock()
+	defer providerLock.Unlock()
+
+	if provider != nil {
+		shutdown(provider)
+	}
+	provider = tp
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+}
+
+// Disable flushes the pending spans and stops exporting new ones.
+func Disable() {
+	providerLock.Lock()
+	defer providerLock.Unlock()
+
+	if provider != nil {
+		shutdown(provider)
+		provider = nil
+		otel.SetTracerProvider(noop.NewTracerProvider())
+	}
+}
+
+func shutdown(tp *sdktrace.TracerProvider) {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := tp.Shutdown(ctx); err != nil {
+		log.Warn("Failed to flush trace spans", "err", err)
+	}
+}
+
+// Tracer returns the named tracer of the global tracer provider. Unlike the
+// tracers of otel.Tracer, the returned tracer follows every later change of the
+// provider, so it can be stored in package variables.
+func Tracer(name string) trace.Tracer {
+	return &globalTracer{name: name}
+}
+
+// globalTracer is a tracer creating the spans with the current global provider.
+type globalTracer struct {
+	embedded.Tracer
+	name string
+}
+
+// Start implements trace.Tracer.
+func (t *globalTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.GetTracerProvider().Tracer(t.name).Start(ctx, name, opts...)
+}
+
+// Start creates a span and a context containing it.
+func Start(ctx context.Context, tracer trace.Tracer, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End ends the span, recording the error if the traced operation failed.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
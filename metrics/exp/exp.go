@@ -6,6 +6,7 @@ import (
 	"expvar"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 
 	"github.com/ethereum/go-ethereum/log"
@@ -206,6 +207,14 @@ func (exp *exp) syncToExpvar() {
 			exp.publishTimer(name, i)
 		case metrics.ResettingTimer:
 			exp.publishResettingTimer(name, i)
+		case *metrics.CounterVec:
+			i.Each(func(values []string, c metrics.Counter) {
+				exp.publishCounter(name+"/"+strings.Join(values, "/"), c.Snapshot())
+			})
+		case *metrics.TimerVec:
+			i.Each(func(values []string, t metrics.Timer) {
+				exp.publishTimer(name+"/"+strings.Join(values, "/"), t)
+			})
 		default:
 			panic(fmt.Sprintf("unsupported type for '%s': %T", name, i))
 		}
@@ -69,6 +69,10 @@ func (c *collector) Add(name string, i any) error {
 		c.addTimer(name, m.Snapshot())
 	case metrics.ResettingTimer:
 		c.addResettingTimer(name, m.Snapshot())
+	case *metrics.CounterVec:
+		c.addCounterVec(name, m)
+	case *metrics.TimerVec:
+		c.addTimerVec(name, m)
 	default:
 		return fmt.Errorf("unknown prometheus metric type %T", i)
 	}
@@ -134,6 +138,35 @@ func (c *collector) addResettingTimer(name string, m metrics.ResettingTimerSnaps
 	c.buff.WriteRune('\n')
 }
 
+func (c *collector) addCounterVec(name string, m *metrics.CounterVec) {
+	name = mutateKey(name)
+	c.buff.WriteString(fmt.Sprintf(typeCounterTpl, name))
+	m.Each(func(values []string, counter metrics.Counter) {
+		c.buff.WriteString(fmt.Sprintf("%s%s %v\n", name, formatLabels(m.Labels(), values), counter.Snapshot().Count()))
+	})
+	c.buff.WriteRune('\n')
+}
+
+func (c *collector) addTimerVec(name string, m *metrics.TimerVec) {
+	pv := []float64{0.5, 0.75, 0.95, 0.99, 0.999, 0.9999}
+	name = mutateKey(name)
+	c.buff.WriteString(fmt.Sprintf(typeSummaryTpl, name))
+	m.Each(func(values []string, timer metrics.Timer) {
+		var (
+			labels = m.Labels()
+			t      = timer.Snapshot()
+			ps     = t.Percentiles(pv)
+		)
+		for i := range pv {
+			quantile := formatLabels(labels, values, "quantile", strconv.FormatFloat(pv[i], 'f', -1, 64))
+			c.buff.WriteString(fmt.Sprintf("%s%s %v\n", name, quantile, ps[i]))
+		}
+		c.buff.WriteString(fmt.Sprintf("%s_sum%s %v\n", name, formatLabels(labels, values), t.Sum()))
+		c.buff.WriteString(fmt.Sprintf("%s_count%s %v\n", name, formatLabels(labels, values), t.Count()))
+	})
+	c.buff.WriteRune('\n')
+}
+
 func (c *collector) writeGaugeInfo(name string, value metrics.GaugeInfoValue) {
 	name = mutateKey(name)
 	c.buff.WriteString(fmt.Sprintf(typeGaugeTpl, name))
@@ -164,6 +197,22 @@ func (c *collector) writeSummaryPercentile(name, p string, value interface{}) {
 	c.buff.WriteString(fmt.Sprintf(keyQuantileTagValueTpl, name, p, value))
 }
 
+// labelValueEscaper escapes label values as required by the exposition format.
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatLabels formats a set of label pairs as {name="value",...}. The extra
+// label pairs are given as alternating names and values.
+func formatLabels(names, values []string, extra ...string) string {
+	var pairs []string
+	for i := range names {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, names[i], labelValueEscaper.Replace(values[i])))
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, extra[i], labelValueEscaper.Replace(extra[i+1])))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
 func mutateKey(key string) string {
 	return strings.ReplaceAll(key, "/", "_")
 }
@@ -63,3 +63,39 @@ func findFirstDiffPos(a, b string) string {
 	}
 	return ""
 }
+
+func TestCollectorVec(t *testing.T) {
+	var (
+		c       = newCollector()
+		counter = metrics.NewCounterVec("validator", "turn")
+		timer   = metrics.NewTimerVec("method")
+	)
+	counter.With("0x02", "in").Inc(3)
+	counter.With("0x01", "out").Inc(1)
+	counter.With("0x01", "in").Inc(2)
+	timer.With(`a"b`).Update(5)
+
+	c.Add("congress/sealed", counter)
+	c.Add("rpc/latency", timer)
+
+	want := `# TYPE congress_sealed counter
+congress_sealed{validator="0x01",turn="in"} 2
+congress_sealed{validator="0x01",turn="out"} 1
+congress_sealed{validator="0x02",turn="in"} 3
+
+# TYPE rpc_latency summary
+rpc_latency{method="a\"b",quantile="0.5"} 5
+rpc_latency{method="a\"b",quantile="0.75"} 5
+rpc_latency{method="a\"b",quantile="0.95"} 5
+rpc_latency{method="a\"b",quantile="0.99"} 5
+rpc_latency{method="a\"b",quantile="0.999"} 5
+rpc_latency{method="a\"b",quantile="0.9999"} 5
+rpc_latency_sum{method="a\"b"} 5
+rpc_latency_count{method="a\"b"} 1
+
+`
+	if have := c.buff.String(); have != want {
+		t.Logf("have vs want:\n%v", findFirstDiffPos(have, want))
+		t.Fatalf("unexpected collector output:\n%s", have)
+	}
+}
@@ -200,6 +200,14 @@ func (r *StandardRegistry) GetAll() map[string]map[string]interface{} {
 			values["5m.rate"] = t.Rate5()
 			values["15m.rate"] = t.Rate15()
 			values["mean.rate"] = t.RateMean()
+		case *CounterVec:
+			metric.Each(func(labels []string, c Counter) {
+				values[strings.Join(labels, ",")] = c.Snapshot().Count()
+			})
+		case *TimerVec:
+			metric.Each(func(labels []string, t Timer) {
+				values[strings.Join(labels, ",")] = t.Snapshot().Count()
+			})
 		}
 		data[name] = values
 	})
@@ -214,7 +222,7 @@ func (r *StandardRegistry) Unregister(name string) {
 
 func (r *StandardRegistry) loadOrRegister(name string, i interface{}) (interface{}, bool, bool) {
 	switch i.(type) {
-	case Counter, CounterFloat64, Gauge, GaugeFloat64, GaugeInfo, Healthcheck, Histogram, Meter, Timer, ResettingTimer:
+	case Counter, CounterFloat64, Gauge, GaugeFloat64, GaugeInfo, Healthcheck, Histogram, Meter, Timer, ResettingTimer, *CounterVec, *TimerVec:
 	default:
 		return nil, false, false
 	}
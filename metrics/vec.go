@@ -0,0 +1,155 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// vec is a family of metrics partitioned by the values of a set of labels. The
+// members of the family are created on first use.
+type vec[T any] struct {
+	labels   []string
+	create   func() T
+	disabled T // returned for every label set if metrics are disabled
+
+	lock    sync.RWMutex
+	members map[string]vecMember[T]
+}
+
+type vecMember[T any] struct {
+	values []string
+	metric T
+}
+
+func newVec[T any](labels []string, create func() T, disabled T) *vec[T] {
+	v := &vec[T]{labels: labels, disabled: disabled}
+	if Enabled {
+		v.create = create
+		v.members = make(map[string]vecMember[T])
+	}
+	return v
+}
+
+// Labels returns the label names of the family.
+func (v *vec[T]) Labels() []string {
+	return v.labels
+}
+
+// with returns the member of the family with the given label values, creating
+// it if necessary. It panics if the number of values doesn't match the labels.
+func (v *vec[T]) with(values []string) T {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %d label values given for labels %v", len(values), v.labels))
+	}
+	if v.create == nil {
+		return v.disabled
+	}
+	key := strings.Join(values, "\x00")
+
+	v.lock.RLock()
+	m, ok := v.members[key]
+	v.lock.RUnlock()
+	if ok {
+		return m.metric
+	}
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	if m, ok := v.members[key]; ok {
+		return m.metric
+	}
+	m = vecMember[T]{values: append([]string(nil), values...), metric: v.create()}
+	v.members[key] = m
+	return m.metric
+}
+
+// each calls fn for every member of the family, sorted by label values.
+func (v *vec[T]) each(fn func(values []string, metric T)) {
+	v.lock.RLock()
+	members := make([]vecMember[T], 0, len(v.members))
+	for _, m := range v.members {
+		members = append(members, m)
+	}
+	v.lock.RUnlock()
+
+	sort.Slice(members, func(i, j int) bool {
+		a, b := members[i].values, members[j].values
+		for k := range a {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return false
+	})
+	for _, m := range members {
+		fn(m.values, m.metric)
+	}
+}
+
+// CounterVec is a family of counters partitioned by label values.
+type CounterVec struct {
+	*vec[Counter]
+}
+
+// NewCounterVec constructs a new CounterVec with the given label names.
+func NewCounterVec(labels ...string) *CounterVec {
+	return &CounterVec{newVec[Counter](labels, NewCounter, NilCounter{})}
+}
+
+// NewRegisteredCounterVec constructs and registers a new CounterVec.
+func NewRegisteredCounterVec(name string, r Registry, labels ...string) *CounterVec {
+	c := NewCounterVec(labels...)
+	if nil == r {
+		r = DefaultRegistry
+	}
+	r.Register(name, c)
+	return c
+}
+
+// With returns the counter of the given label values, which must be given in
+// the order of the label names.
+func (c *CounterVec) With(values ...string) Counter {
+	return c.with(values)
+}
+
+// Each calls fn for every counter of the family, sorted by label values.
+func (c *CounterVec) Each(fn func(values []string, counter Counter)) {
+	c.each(fn)
+}
+
+// TimerVec is a family of timers partitioned by label values.
+type TimerVec struct {
+	*vec[Timer]
+}
+
+// NewTimerVec constructs a new TimerVec with the given label names.
+func NewTimerVec(labels ...string) *TimerVec {
+	return &TimerVec{newVec[Timer](labels, NewTimer, NilTimer{})}
+}
+
+// NewRegisteredTimerVec constructs and registers a new TimerVec.
+func NewRegisteredTimerVec(name string, r Registry, labels ...string) *TimerVec {
+	t := NewTimerVec(labels...)
+	if nil == r {
+		r = DefaultRegistry
+	}
+	r.Register(name, t)
+	return t
+}
+
+// With returns the timer of the given label values, which must be given in the
+// order of the label names.
+func (t *TimerVec) With(values ...string) Timer {
+	return t.with(values)
+}
+
+// Each calls fn for every timer of the family, sorted by label values.
+func (t *TimerVec) Each(fn func(values []string, timer Timer)) {
+	t.each(fn)
+}
+
+// Stop stops all timers of the family.
+func (t *TimerVec) Stop() {
+	t.each(func(_ []string, timer Timer) { timer.Stop() })
+}
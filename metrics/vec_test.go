@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCounterVec(t *testing.T) {
+	v := NewCounterVec("reason")
+	v.With("underpriced").Inc(1)
+	v.With("nonce too low").Inc(2)
+	v.With("underpriced").Inc(1)
+
+	var (
+		values [][]string
+		counts []int64
+	)
+	v.Each(func(labels []string, c Counter) {
+		values = append(values, labels)
+		counts = append(counts, c.Snapshot().Count())
+	})
+	if want := [][]string{{"nonce too low"}, {"underpriced"}}; !reflect.DeepEqual(values, want) {
+		t.Errorf("label values mismatch: have %v, want %v", values, want)
+	}
+	if want := []int64{2, 2}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("counts mismatch: have %v, want %v", counts, want)
+	}
+}
+
+func TestCounterVecLabelCount(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("mismatching label values accepted")
+		}
+	}()
+	NewCounterVec("validator", "turn").With("0x01")
+}
+
+func TestRegisterVec(t *testing.T) {
+	r := NewRegistry()
+	NewRegisteredCounterVec("counter", r, "a")
+	NewRegisteredTimerVec("timer", r, "b")
+	if _, ok := r.Get("counter").(*CounterVec); !ok {
+		t.Errorf("counter vec not registered: %T", r.Get("counter"))
+	}
+	if _, ok := r.Get("timer").(*TimerVec); !ok {
+		t.Errorf("timer vec not registered: %T", r.Get("timer"))
+	}
+}
@@ -53,6 +53,8 @@ type Config struct {
 	Recommit  time.Duration  // The time interval for miner to re-create mining work.
 
 	NewPayloadTimeout time.Duration // The maximum time allowance for creating a new payload
+
+	Speculative bool // Keep updating the pending block until the in-turn slot instead of recommitting
 }
 
 // DefaultConfig contains default settings for miner.
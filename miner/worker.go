@@ -17,6 +17,7 @@
 package miner
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/big"
@@ -34,10 +35,12 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/internal/tracing"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/trie"
 	"github.com/holiman/uint256"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
@@ -72,14 +75,29 @@ const (
 
 	// staleThreshold is the maximum depth of the acceptable stale block.
 	staleThreshold = 7
+
+	// speculationCutoff is the time before the slot of an in-turn sealing block
+	// after which it is no longer updated in place, so that the sealer is never
+	// handed a new block once the previous one may have been sealed.
+	speculationCutoff = 200 * time.Millisecond
 )
 
 var (
 	errBlockInterruptedByNewHead  = errors.New("new head arrived while building block")
 	errBlockInterruptedByRecommit = errors.New("recommit interrupt while building block")
 	errBlockInterruptedByTimeout  = errors.New("timeout while building block")
+
+	spanTracer = tracing.Tracer("github.com/ethereum/go-ethereum/miner")
 )
 
+// turnPredictor is implemented by the consensus engines in which validators take
+// turns to seal blocks, allowing the worker to prepare ahead of the local slot.
+type turnPredictor interface {
+	// InTurn reports whether the local validator is in-turn to sign the block
+	// with the given number on top of parent.
+	InTurn(chain consensus.ChainHeaderReader, parent *types.Header, number uint64) (bool, error)
+}
+
 // environment is the worker's current environment and holds all
 // information of the sealing block generation.
 type environment struct {
@@ -94,6 +112,8 @@ type environment struct {
 	receipts []*types.Receipt
 	sidecars []*types.BlobTxSidecar
 	blobs    int
+
+	prefetch *atomic.Bool // Interrupt for prefetching ahead of the next in-turn slot, nil if not prefetching
 }
 
 // copy creates a deep copy of environment.
@@ -123,6 +143,9 @@ func (env *environment) copy() *environment {
 // always be called for all created environment instances otherwise
 // the go-routine leak can happen.
 func (env *environment) discard() {
+	if env.prefetch != nil {
+		env.prefetch.Store(true)
+	}
 	if env.state == nil {
 		return
 	}
@@ -221,6 +244,9 @@ type worker struct {
 	newTxs  atomic.Int32 // New arrival transaction count since last sealing work submitting.
 	syncing atomic.Bool  // The indicator whether the node is still syncing.
 
+	speculating atomic.Bool // The indicator whether the current sealing block is updated in place until its slot.
+	prefetching atomic.Bool // The indicator whether transactions are prefetched ahead of the next in-turn slot.
+
 	// newpayloadTimeout is the maximum timeout allowance for creating payload.
 	// The default value is 2 seconds but node operator can set it to arbitrary
 	// large value. A large timeout allowance may cause Geth to fail creating
@@ -472,8 +498,9 @@ func (w *worker) newWorkLoop(recommit time.Duration) {
 			// If sealing is running resubmit a new work cycle periodically to pull in
 			// higher priced transactions. Disable this overhead for pending blocks.
 			if w.isRunning() && (w.chainConfig.Clique == nil || w.chainConfig.Clique.Period > 0) {
-				// Short circuit if no new transaction arrives.
-				if w.newTxs.Load() == 0 {
+				// Short circuit if no new transaction arrives, or if the new ones
+				// are added to the sealing block in place.
+				if w.newTxs.Load() == 0 || w.speculating.Load() {
 					timer.Reset(recommit)
 					continue
 				}
@@ -538,14 +565,16 @@ func (w *worker) mainLoop() {
 			req.result <- w.generateWork(req.params)
 
 		case ev := <-w.txsCh:
-			// Apply transactions to the pending state if we're not sealing
+			// Apply transactions to the pending state if we're not sealing, or if
+			// the block being sealed is updated in place until its in-turn slot.
 			//
 			// Note all transactions received may not be continuous with transactions
 			// already included in the current sealing block. These transactions will
 			// be automatically eliminated.
-			if !w.isRunning() && w.current != nil {
+			speculate := w.isRunning() && w.speculating.Load() && w.updatable(w.current)
+			if (!w.isRunning() || speculate) && w.current != nil {
 				// If block is already full, abort
-				if gp := w.current.gasPool; gp != nil && gp.Gas() < params.TxGas {
+				if gp := w.current.gasPool; gp != nil && gp.Gas() < w.txGas(w.current) {
 					continue
 				}
 				txs := make(map[common.Address][]*txpool.LazyTransaction, len(ev.Txs))
@@ -565,15 +594,40 @@ func (w *worker) mainLoop() {
 				plainTxs := newTransactionsByPriceAndNonce(w.current.signer, txs, w.current.header.BaseFee) // Mixed bag of everrything, yolo
 				blobTxs := newTransactionsByPriceAndNonce(w.current.signer, nil, w.current.header.BaseFee)  // Empty bag, don't bother optimising
 
+				// Stop applying transactions to the block being sealed at the
+				// cutoff before its slot, it may be sealed afterwards.
+				var (
+					interrupt *atomic.Int32
+					timer     *time.Timer
+				)
+				if speculate {
+					interrupt = new(atomic.Int32)
+					timer = time.AfterFunc(w.untilCutoff(w.current), func() {
+						interrupt.Store(commitInterruptTimeout)
+					})
+				}
 				tcount := w.current.tcount
-				w.commitTransactions(w.current, plainTxs, blobTxs, nil)
-
+				w.commitTransactions(w.current, plainTxs, blobTxs, interrupt)
+				if timer != nil {
+					timer.Stop()
+				}
 				// Only update the snapshot if any new transactions were added
 				// to the pending block
 				if tcount != w.current.tcount {
 					w.updateSnapshot(w.current)
+
+					// Replace the block being sealed with the updated one,
+					// unless the cutoff passed while applying the transactions
+					if speculate && w.updatable(w.current) {
+						w.commit(context.Background(), w.current.copy(), nil, false, time.Now())
+					}
 				}
 			} else {
+				// Warm up the state caches with the new transactions if the
+				// local validator is in-turn for the next block.
+				if w.current != nil && w.current.prefetch != nil {
+					w.prefetch(w.current, ev.Txs)
+				}
 				// Special case, if the consensus engine is 0 period clique(dev mode),
 				// submit sealing work here since all empty submission will be rejected
 				// by clique. Of course the advance sealing(empty submission) is disabled.
@@ -601,6 +655,11 @@ func (w *worker) taskLoop() {
 	var (
 		stopCh chan struct{}
 		prev   common.Hash
+		sealed *types.Header // Header of the last speculatively sealed block handed to the result loop
+
+		// Sealing results are relayed through the task loop, so that no task
+		// competing with a handed over block is sealed afterwards
+		results = make(chan *types.Block, resultQueueSize)
 	)
 
 	// interrupt aborts the in-flight sealing task.
@@ -621,6 +680,16 @@ func (w *worker) taskLoop() {
 			if sealHash == prev {
 				continue
 			}
+			// Reject speculative updates of an already sealed block, signing a
+			// second block on the same parent would equivocate. Work on any other
+			// parent means the chain moved on (or was rewound), so stop guarding.
+			if sealed != nil {
+				if task.block.ParentHash() == sealed.ParentHash {
+					log.Debug("Refusing to reseal sealed block", "number", task.block.NumberU64(), "sealed", sealed.Hash(), "sealhash", sealHash)
+					continue
+				}
+				sealed = nil
+			}
 			// Interrupt previous sealing operation
 			interrupt()
 			stopCh, prev = make(chan struct{}), sealHash
@@ -632,12 +701,27 @@ func (w *worker) taskLoop() {
 			w.pendingTasks[sealHash] = task
 			w.pendingMu.Unlock()
 
-			if err := w.engine.Seal(w.chain, task.block, w.resultCh, stopCh); err != nil {
+			if err := w.engine.Seal(w.chain, task.block, results, stopCh); err != nil {
 				log.Warn("Block sealing failed", "err", err)
 				w.pendingMu.Lock()
 				delete(w.pendingTasks, sealHash)
 				w.pendingMu.Unlock()
 			}
+		case block := <-results:
+			// Drop the results of interrupted sealing tasks, they have been
+			// superseded by the task being sealed
+			if block == nil || w.engine.SealHash(block.Header()) != prev {
+				continue
+			}
+			if w.config.Speculative {
+				sealed = block.Header()
+			}
+			select {
+			case w.resultCh <- block:
+			case <-w.exitCh:
+				interrupt()
+				return
+			}
 		case <-w.exitCh:
 			interrupt()
 			return
@@ -819,8 +903,8 @@ func (w *worker) commitTransactions(env *environment, plainTxs, blobTxs *transac
 			}
 		}
 		// If we don't have enough gas for any further transactions then we're done.
-		if env.gasPool.Gas() < params.TxGas {
-			log.Trace("Not enough gas for further transactions", "have", env.gasPool, "want", params.TxGas)
+		if txGas := w.txGas(env); env.gasPool.Gas() < txGas {
+			log.Trace("Not enough gas for further transactions", "have", env.gasPool, "want", txGas)
 			break
 		}
 		// If we don't have enough blob space for any further blob transactions,
@@ -1015,6 +1099,11 @@ func (w *worker) prepareWork(genParams *generateParams) (*environment, error) {
 		vmenv := vm.NewEVM(context, vm.TxContext{}, env.state, w.chainConfig, vm.Config{})
 		core.ProcessBeaconBlockRoot(*header.ParentBeaconRoot, vmenv, env.state)
 	}
+	if w.chainConfig.IsHistoryStorage(header.Number, header.Time) {
+		context := core.NewEVMBlockContext(header, w.chain, nil)
+		vmenv := vm.NewEVM(context, vm.TxContext{}, env.state, w.chainConfig, vm.Config{})
+		core.ProcessParentBlockHash(header.ParentHash, vmenv, env.state)
+	}
 	return env, nil
 }
 
@@ -1116,6 +1205,9 @@ func (w *worker) commitWork(interrupt *atomic.Int32, timestamp int64) {
 	}
 	start := time.Now()
 
+	ctx, span := tracing.Start(context.Background(), spanTracer, "miner.commitWork", attribute.Int64("timestamp", timestamp))
+	defer span.End()
+
 	// Set the coinbase if the worker is running or it's required
 	var coinbase common.Address
 	if w.isRunning() {
@@ -1130,10 +1222,18 @@ func (w *worker) commitWork(interrupt *atomic.Int32, timestamp int64) {
 		coinbase:  coinbase,
 	})
 	if err != nil {
+		span.RecordError(err)
 		return
 	}
 	// Fill pending transactions from the txpool into the block.
 	err = w.fillTransactions(interrupt, work)
+	span.SetAttributes(
+		attribute.Int64("block.number", work.header.Number.Int64()),
+		attribute.Int("block.txs", work.tcount),
+	)
+	if err != nil {
+		span.SetAttributes(attribute.String("interrupt", err.Error()))
+	}
 	switch {
 	case err == nil:
 		// The entire block is filled, decrease resubmit interval in case
@@ -1161,8 +1261,16 @@ func (w *worker) commitWork(interrupt *atomic.Int32, timestamp int64) {
 		work.discard()
 		return
 	}
+	// Decide how to spend the time until the slot of the block: keep the block
+	// updated in place if it is sealed in-turn, or prepare for the next block if
+	// that one is.
+	inturn, next := w.predictTurn(work.header)
+	w.speculating.Store(inturn)
+	if next {
+		work.prefetch = new(atomic.Bool)
+	}
 	// Submit the generated block for consensus sealing.
-	w.commit(work.copy(), w.fullTaskHook, true, start)
+	w.commit(ctx, work.copy(), w.fullTaskHook, true, start)
 
 	// Swap out the old work with the new one, terminating any leftover
 	// prefetcher processes in the mean time and starting a new one.
@@ -1172,11 +1280,17 @@ func (w *worker) commitWork(interrupt *atomic.Int32, timestamp int64) {
 	w.current = work
 }
 
+// txGas returns the intrinsic gas of the cheapest transaction of the block being
+// built, taking the gas schedule overrides into account.
+func (w *worker) txGas(env *environment) uint64 {
+	return w.chainConfig.GasScheduleAt(env.header.Time).IntrinsicGas(params.IntrinsicTxGas, params.TxGas)
+}
+
 // commit runs any post-transaction state modifications, assembles the final block
 // and commits new work if consensus engine is running.
 // Note the assumption is held that the mutation is allowed to the passed env, do
-// the deep copy first.
-func (w *worker) commit(env *environment, interval func(), update bool, start time.Time) error {
+// the deep copy first. The context carries the trace of the caller.
+func (w *worker) commit(ctx context.Context, env *environment, interval func(), update bool, start time.Time) error {
 	if w.isRunning() {
 		if interval != nil {
 			interval()
@@ -1185,7 +1299,7 @@ func (w *worker) commit(env *environment, interval func(), update bool, start ti
 		// https://github.com/ethereum/go-ethereum/issues/24299
 		env := env.copy()
 		// Withdrawals are set to nil here, because this is only called in PoW.
-		block, err := w.engine.FinalizeAndAssemble(w.chain, env.header, env.state, env.txs, nil, env.receipts, nil)
+		block, err := w.engine.FinalizeAndAssemble(consensus.WithTrace(w.chain, ctx), env.header, env.state, env.txs, nil, env.receipts, nil)
 		if err != nil {
 			return err
 		}
@@ -1210,6 +1324,65 @@ func (w *worker) commit(env *environment, interval func(), update bool, start ti
 	return nil
 }
 
+// predictTurn reports whether the local validator is in-turn for the given
+// sealing block and for the block after it. Both are false if speculative
+// sealing is disabled or not supported by the consensus engine.
+func (w *worker) predictTurn(header *types.Header) (bool, bool) {
+	engine, ok := w.engine.(turnPredictor)
+	if !ok || !w.config.Speculative || !w.isRunning() {
+		return false, false
+	}
+	number := header.Number.Uint64()
+	parent := w.chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return false, false
+	}
+	inturn, err := engine.InTurn(w.chain, parent, number)
+	if err != nil {
+		log.Debug("Failed to predict sealing turn", "number", number, "err", err)
+		return false, false
+	}
+	next, err := engine.InTurn(w.chain, parent, number+1)
+	if err != nil {
+		log.Debug("Failed to predict sealing turn", "number", number+1, "err", err)
+		return inturn, false
+	}
+	return inturn, next
+}
+
+// updatable reports whether the given sealing block may still be updated in
+// place with new transactions before its slot.
+func (w *worker) updatable(env *environment) bool {
+	if env == nil || env.header.ParentHash != w.chain.CurrentBlock().Hash() {
+		return false
+	}
+	return w.untilCutoff(env) > 0
+}
+
+// untilCutoff returns the time left until the given sealing block may no longer
+// be updated in place.
+func (w *worker) untilCutoff(env *environment) time.Duration {
+	return time.Until(time.Unix(int64(env.header.Time), 0)) - speculationCutoff
+}
+
+// prefetch executes the given transactions on a copy of the pending state in the
+// background, warming up the state caches for the next in-turn slot. Batches of
+// transactions arriving while a previous one is being prefetched are skipped.
+func (w *worker) prefetch(env *environment, txs []*types.Transaction) {
+	if !w.prefetching.CompareAndSwap(false, true) {
+		return
+	}
+	var (
+		block     = types.NewBlockWithHeader(env.header).WithBody(txs, nil)
+		statedb   = env.state.Copy()
+		interrupt = env.prefetch
+	)
+	go func() {
+		defer w.prefetching.Store(false)
+		w.chain.Prefetcher().Prefetch(block, statedb, vm.Config{}, interrupt)
+	}()
+}
+
 // getSealingBlock generates the sealing block based on the given parameters.
 // The generation result will be passed back via the given channel no matter
 // the generation itself succeeds or not.
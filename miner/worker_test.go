@@ -119,6 +119,9 @@ func newTestWorkerBackend(t *testing.T, chainConfig *params.ChainConfig, engine
 		Config: chainConfig,
 		Alloc:  types.GenesisAlloc{testBankAddress: {Balance: testBankFunds}},
 	}
+	if e, ok := engine.(*testTurnEngine); ok {
+		engine = e.Clique
+	}
 	switch e := engine.(type) {
 	case *clique.Clique:
 		gspec.ExtraData = make([]byte, 32+common.AddressLength+crypto.SignatureLength)
@@ -159,6 +162,25 @@ func (b *testWorkerBackend) newRandomTx(creation bool) *types.Transaction {
 	return tx
 }
 
+// testTurnEngine is a clique engine with predictable turns of the local signer,
+// sealing blocks a few seconds in the future.
+type testTurnEngine struct {
+	*clique.Clique
+	inturn func(number uint64) bool
+}
+
+func (e *testTurnEngine) InTurn(chain consensus.ChainHeaderReader, parent *types.Header, number uint64) (bool, error) {
+	return e.inturn(number), nil
+}
+
+func (e *testTurnEngine) Prepare(chain consensus.ChainHeaderReader, header *types.Header) error {
+	if err := e.Clique.Prepare(chain, header); err != nil {
+		return err
+	}
+	header.Time = uint64(time.Now().Add(5 * time.Second).Unix())
+	return nil
+}
+
 func newTestWorker(t *testing.T, chainConfig *params.ChainConfig, engine consensus.Engine, db ethdb.Database, blocks int) (*worker, *testWorkerBackend) {
 	backend := newTestWorkerBackend(t, chainConfig, engine, db, blocks)
 	backend.txPool.Add(pendingTxs, true, false)
@@ -508,3 +530,117 @@ func testGetSealingWork(t *testing.T, chainConfig *params.ChainConfig, engine co
 		}
 	}
 }
+
+func TestSpeculativeSealingInTurn(t *testing.T) {
+	t.Parallel()
+	testSpeculativeSealing(t, func(number uint64) bool { return true }, true)
+}
+
+func TestSpeculativeSealingNextInTurn(t *testing.T) {
+	t.Parallel()
+	testSpeculativeSealing(t, func(number uint64) bool { return number%2 == 0 }, false)
+}
+
+func testSpeculativeSealing(t *testing.T, inturn func(number uint64) bool, update bool) {
+	var (
+		db     = rawdb.NewMemoryDatabase()
+		engine = &testTurnEngine{Clique: clique.New(cliqueChainConfig.Clique, db), inturn: inturn}
+		config = *testConfig
+	)
+	defer engine.Close()
+
+	// Recommit rarely, new transactions may only be picked up by speculation
+	config.Recommit, config.Speculative = time.Minute, true
+
+	// Wait for the pending transaction to be promoted, so that the first task
+	// includes it
+	b := newTestWorkerBackend(t, cliqueChainConfig, engine, db, 0)
+	b.txPool.Add(pendingTxs, true, true)
+	w := newWorker(&config, cliqueChainConfig, engine, b, new(event.TypeMux), nil, false)
+	w.setEtherbase(testBankAddress)
+	defer w.close()
+
+	tasks := make(chan *task, 10)
+	w.newTaskHook = func(task *task) {
+		tasks <- task
+	}
+	w.skipSealHook = func(task *task) bool {
+		return true
+	}
+	w.start()
+
+	// waitTask waits for a task with the given number of receipts, skipping the
+	// ones submitted before the transactions were picked up.
+	waitTask := func(receipts int, timeout time.Duration) *task {
+		deadline := time.After(timeout)
+		for {
+			select {
+			case task := <-tasks:
+				if len(task.receipts) == receipts {
+					return task
+				}
+			case <-deadline:
+				return nil
+			}
+		}
+	}
+	if waitTask(1, 3*time.Second) == nil {
+		t.Fatal("new task timeout")
+	}
+	b.txPool.Add(newTxs, true, false)
+
+	task := waitTask(2, time.Second)
+	if update && task == nil {
+		t.Fatal("task update timeout")
+	}
+	if !update && task != nil {
+		t.Fatalf("unexpected task update with %d receipts", len(task.receipts))
+	}
+}
+
+// Tests that the worker seals a new block at the height of an already sealed
+// one after the chain is rewound, even when speculating.
+func TestSealAfterRewind(t *testing.T) {
+	t.Parallel()
+
+	var (
+		db          = rawdb.NewMemoryDatabase()
+		chainConfig = *cliqueChainConfig
+		config      = *testConfig
+	)
+	chainConfig.Clique = &params.CliqueConfig{Period: 1, Epoch: 30000}
+	config.Speculative = true
+
+	engine := clique.New(chainConfig.Clique, db)
+	defer engine.Close()
+
+	b := newTestWorkerBackend(t, &chainConfig, engine, db, 0)
+	w := newWorker(&config, &chainConfig, engine, b, new(event.TypeMux), nil, false)
+	w.setEtherbase(testBankAddress)
+	defer w.close()
+
+	sub := w.mux.Subscribe(core.NewMinedBlockEvent{})
+	defer sub.Unsubscribe()
+
+	// waitBlock waits for a mined block of the given number.
+	waitBlock := func(number uint64) {
+		deadline := time.After(3 * time.Second)
+		for {
+			select {
+			case ev := <-sub.Chan():
+				if ev.Data.(core.NewMinedBlockEvent).Block.NumberU64() == number {
+					return
+				}
+			case <-deadline:
+				t.Fatalf("timeout waiting for block %d", number)
+			}
+		}
+	}
+	w.start()
+	waitBlock(2)
+
+	if err := b.chain.SetHead(0); err != nil {
+		t.Fatalf("failed to rewind chain: %v", err)
+	}
+	waitBlock(1)
+}
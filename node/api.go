@@ -176,9 +176,13 @@ func (api *adminAPI) StartHTTP(host *string, port *int, cors *string, apis *stri
 		CorsAllowedOrigins: api.node.config.HTTPCors,
 		Vhosts:             api.node.config.HTTPVirtualHosts,
 		Modules:            api.node.config.HTTPModules,
+		Methods:            api.node.config.HTTPMethods,
 		rpcEndpointConfig: rpcEndpointConfig{
 			batchItemLimit:         api.node.config.BatchRequestLimit,
 			batchResponseSizeLimit: api.node.config.BatchResponseMaxSize,
+			rateLimiter:            api.node.rateLimiter,
+			responseCache:          api.node.responseCache,
+			jwtKeys:                api.node.jwtKeys,
 		},
 	}
 	if cors != nil {
@@ -252,11 +256,15 @@ func (api *adminAPI) StartWS(host *string, port *int, allowedOrigins *string, ap
 	// Determine config.
 	config := wsConfig{
 		Modules: api.node.config.WSModules,
+		Methods: api.node.config.WSMethods,
 		Origins: api.node.config.WSOrigins,
 		// ExposeAll: api.node.config.WSExposeAll,
 		rpcEndpointConfig: rpcEndpointConfig{
 			batchItemLimit:         api.node.config.BatchRequestLimit,
 			batchResponseSizeLimit: api.node.config.BatchResponseMaxSize,
+			rateLimiter:            api.node.rateLimiter,
+			responseCache:          api.node.responseCache,
+			jwtKeys:                api.node.jwtKeys,
 		},
 	}
 	if apis != nil {
@@ -130,6 +130,10 @@ type Config struct {
 	// exposed.
 	HTTPModules []string
 
+	// HTTPMethods restricts the methods callable via the HTTP RPC interface on top
+	// of the exposed modules.
+	HTTPMethods *rpc.MethodPolicy `toml:",omitempty"`
+
 	// HTTPTimeouts allows for customization of the timeout values used by the HTTP RPC
 	// interface.
 	HTTPTimeouts rpc.HTTPTimeouts
@@ -169,6 +173,10 @@ type Config struct {
 	// exposed.
 	WSModules []string
 
+	// WSMethods restricts the methods callable via the websocket RPC interface on
+	// top of the exposed modules.
+	WSMethods *rpc.MethodPolicy `toml:",omitempty"`
+
 	// WSExposeAll exposes all API modules via the WebSocket RPC interface rather
 	// than just the public ones.
 	//
@@ -176,6 +184,15 @@ type Config struct {
 	// private APIs to untrusted users is a major security risk.
 	WSExposeAll bool `toml:",omitempty"`
 
+	// BinaryHost is the host interface on which to start the binary RPC server. If
+	// this field is empty, no binary RPC endpoint will be started. The endpoint
+	// can't be combined with RPCRateLimit, method policies or JWTKeysDir, as it
+	// doesn't enforce them.
+	BinaryHost string `toml:",omitempty"`
+
+	// BinaryPort is the TCP port number on which to start the binary RPC server.
+	BinaryPort int `toml:",omitempty"`
+
 	// GraphQLCors is the Cross-Origin Resource Sharing header to send to requesting
 	// clients. Please be aware that CORS is a browser enforced security, it's fully
 	// useless for custom HTTP clients.
@@ -204,9 +221,27 @@ type Config struct {
 	// BatchResponseMaxSize is the maximum number of bytes returned from a batched rpc call.
 	BatchResponseMaxSize int `toml:",omitempty"`
 
+	// RPCRateLimit configures the per-client rate limits of the HTTP and WebSocket
+	// RPC endpoints. The authenticated engine API endpoints are never limited.
+	RPCRateLimit *rpc.RateLimitConfig `toml:",omitempty"`
+
+	// RPCCacheSize is the maximum number of megabytes of immutable results cached
+	// by the HTTP and WebSocket RPC endpoints, zero disables the cache.
+	RPCCacheSize int `toml:",omitempty"`
+
 	// JWTSecret is the path to the hex-encoded jwt secret.
 	JWTSecret string `toml:",omitempty"`
 
+	// JWTKeysDir is the directory of the keys verifying the JWT tokens required
+	// by the HTTP and WebSocket RPC endpoints. The tokens scope the namespaces
+	// and the rate limit tier of the clients. If empty, these endpoints don't
+	// require authentication.
+	//
+	// The other handlers served on the HTTP port, such as GraphQL, require tokens
+	// granting the namespace named after the first element of their path, e.g.
+	// "graphql". The health checks are public.
+	JWTKeysDir string `toml:",omitempty"`
+
 	// EnablePersonal enables the deprecated personal namespace.
 	EnablePersonal bool `toml:"-"`
 
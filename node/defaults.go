@@ -34,6 +34,9 @@ const (
 	DefaultWSPort   = 8546        // Default TCP port for the websocket RPC server
 	DefaultAuthHost = "localhost" // Default host interface for the authenticated apis
 	DefaultAuthPort = 8551        // Default port for the authenticated apis
+
+	DefaultBinaryHost = "localhost" // Default host interface for the binary RPC server
+	DefaultBinaryPort = 8548        // Default TCP port for the binary RPC server
 )
 
 const (
@@ -63,6 +66,7 @@ var DefaultConfig = Config{
 	HTTPVirtualHosts:     []string{"localhost"},
 	HTTPTimeouts:         rpc.DefaultHTTPTimeouts,
 	WSPort:               DefaultWSPort,
+	BinaryPort:           DefaultBinaryPort,
 	WSModules:            []string{"net", "web3"},
 	BatchRequestLimit:    1000,
 	BatchResponseMaxSize: 25 * 1000 * 1000,
@@ -21,6 +21,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/golang-jwt/jwt/v4"
 )
 
@@ -78,3 +79,96 @@ func (handler *jwtHandler) ServeHTTP(out http.ResponseWriter, r *http.Request) {
 		handler.next.ServeHTTP(out, r)
 	}
 }
+
+// jwtScopeClaims are the claims of the tokens authenticating the clients of the
+// HTTP and WebSocket endpoints.
+type jwtScopeClaims struct {
+	jwt.RegisteredClaims
+
+	// Namespaces are the methods the client may call, see rpc.AuthInfo.
+	Namespaces []string `json:"namespaces"`
+
+	// Tier is the rate limit tier of the client.
+	Tier string `json:"tier,omitempty"`
+}
+
+// allows reports whether the claims grant access to the given namespace, either
+// by name or by a prefix ending with an asterisk.
+func (c *jwtScopeClaims) allows(namespace string) bool {
+	for _, rule := range c.Namespaces {
+		if rule == namespace {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(rule, "*"); ok && strings.HasPrefix(namespace, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// jwtScopeHandler authenticates the clients of the HTTP and WebSocket endpoints
+// with tokens verified by the keys of a key store, and passes the scope granted
+// by their claims on to the RPC server.
+type jwtScopeHandler struct {
+	keys      *jwtKeyStore
+	namespace string // namespace the tokens must grant, empty for RPC servers
+	next      http.Handler
+}
+
+// newJWTScopeHandler creates a http.Handler authenticating the clients with
+// tokens verified by the given keys.
+func newJWTScopeHandler(keys *jwtKeyStore, next http.Handler) http.Handler {
+	return &jwtScopeHandler{keys: keys, next: next}
+}
+
+// newJWTHandlerScope creates a http.Handler authenticating the clients of a
+// handler which isn't an RPC server, with tokens granting the given namespace.
+func newJWTHandlerScope(keys *jwtKeyStore, namespace string, next http.Handler) http.Handler {
+	return &jwtScopeHandler{keys: keys, namespace: namespace, next: next}
+}
+
+// ServeHTTP implements http.Handler
+func (handler *jwtScopeHandler) ServeHTTP(out http.ResponseWriter, r *http.Request) {
+	var (
+		strToken string
+		claims   jwtScopeClaims
+		now      = time.Now()
+	)
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		strToken = strings.TrimPrefix(auth, "Bearer ")
+	}
+	if len(strToken) == 0 {
+		http.Error(out, "missing token", http.StatusUnauthorized)
+		return
+	}
+	// The time-based claims are checked below, because unlike the engine API
+	// tokens, these tokens must expire.
+	token, err := jwt.ParseWithClaims(strToken, &claims, handler.keys.keyFunc,
+		jwt.WithValidMethods(jwtKeyMethods),
+		jwt.WithoutClaimsValidation())
+
+	switch {
+	case err != nil:
+		http.Error(out, err.Error(), http.StatusUnauthorized)
+	case !token.Valid:
+		http.Error(out, "invalid token", http.StatusUnauthorized)
+	case claims.Subject == "":
+		http.Error(out, "missing subject", http.StatusUnauthorized)
+	case claims.ExpiresAt == nil:
+		http.Error(out, "missing expiry", http.StatusUnauthorized)
+	case !claims.VerifyExpiresAt(now, true):
+		http.Error(out, "token is expired", http.StatusUnauthorized)
+	case !claims.VerifyNotBefore(now, false):
+		http.Error(out, "token is not valid yet", http.StatusUnauthorized)
+	case handler.namespace != "" && !claims.allows(handler.namespace):
+		http.Error(out, "token doesn't grant "+handler.namespace, http.StatusForbidden)
+	default:
+		ctx := rpc.WithAuth(r.Context(), &rpc.AuthInfo{
+			Subject:    claims.Subject,
+			Namespaces: claims.Namespaces,
+			Tier:       claims.Tier,
+			Expiry:     claims.ExpiresAt.Time,
+		})
+		handler.next.ServeHTTP(out, r.WithContext(ctx))
+	}
+}
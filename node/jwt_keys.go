@@ -0,0 +1,195 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// jwtKeysReloadInterval is the minimum interval between two scans of the key
+// directory for changed keys.
+const jwtKeysReloadInterval = 2 * time.Second
+
+// jwtKeyStore holds the keys verifying the tokens of the clients of the HTTP
+// and WebSocket endpoints. The keys are loaded from a directory, one key per
+// file, which is rescanned for changes while tokens are verified. Added,
+// modified and removed keys take effect within jwtKeysReloadInterval.
+//
+// The file name of a key without extension is the key ID, which tokens carry
+// in their "kid" header to select the key verifying them. Files with extension
+// ".hex" contain a hex-encoded secret of at least 32 bytes verifying HS256
+// tokens. Files with extension ".pem" contain a PEM-encoded public key: RSA
+// keys verify RS256, RS384 and RS512 tokens, ECDSA keys ES256, ES384 and
+// ES512 tokens and Ed25519 keys EdDSA tokens. Other files are ignored.
+type jwtKeyStore struct {
+	dir   string
+	clock mclock.Clock
+
+	lock    sync.Mutex
+	files   map[string]jwtKeyFile // key files by key ID
+	scanned mclock.AbsTime
+}
+
+// jwtKeyFile is a key loaded from a file of the key directory.
+type jwtKeyFile struct {
+	modTime time.Time
+	size    int64
+	key     *jwtKey // nil if the file is invalid
+}
+
+// jwtKey is a key verifying tokens signed with the given methods.
+type jwtKey struct {
+	key     interface{}
+	methods []string
+}
+
+// jwtKeyMethods are the signing methods of all supported keys.
+var jwtKeyMethods = []string{"HS256", "RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// newJWTKeyStore creates a key store loading the keys of the given directory.
+func newJWTKeyStore(dir string) (*jwtKeyStore, error) {
+	ks := &jwtKeyStore{
+		dir:   dir,
+		clock: mclock.System{},
+		files: make(map[string]jwtKeyFile),
+	}
+	if err := ks.scan(); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// key returns the key with the given ID, rescanning the key directory first if
+// it wasn't scanned recently.
+func (ks *jwtKeyStore) key(id string) (*jwtKey, bool) {
+	ks.lock.Lock()
+	defer ks.lock.Unlock()
+
+	if time.Duration(ks.clock.Now()-ks.scanned) >= jwtKeysReloadInterval {
+		if err := ks.scan(); err != nil {
+			log.Warn("Failed to reload JWT keys", "dir", ks.dir, "err", err)
+		}
+	}
+	file, ok := ks.files[id]
+	if !ok || file.key == nil {
+		return nil, false
+	}
+	return file.key, true
+}
+
+// scan reloads the changed files of the key directory. The caller must hold the
+// lock, unless the store isn't shared yet.
+func (ks *jwtKeyStore) scan() error {
+	ks.scanned = ks.clock.Now()
+
+	entries, err := os.ReadDir(ks.dir)
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".hex" && ext != ".pem") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue // removed since listing the directory
+		}
+		id := strings.TrimSuffix(entry.Name(), ext)
+		if seen[id] {
+			log.Warn("Ignoring duplicate JWT key", "id", id, "file", entry.Name())
+			continue
+		}
+		seen[id] = true
+
+		old, ok := ks.files[id]
+		if ok && old.modTime.Equal(info.ModTime()) && old.size == info.Size() {
+			continue
+		}
+		file := jwtKeyFile{modTime: info.ModTime(), size: info.Size()}
+		path := filepath.Join(ks.dir, entry.Name())
+		if file.key, err = loadJWTKey(path); err != nil {
+			log.Warn("Ignoring invalid JWT key", "path", path, "err", err)
+		} else if ok {
+			log.Info("Reloaded JWT key", "id", id)
+		} else {
+			log.Info("Loaded JWT key", "id", id)
+		}
+		ks.files[id] = file
+	}
+	for id := range ks.files {
+		if !seen[id] {
+			log.Info("Removed JWT key", "id", id)
+			delete(ks.files, id)
+		}
+	}
+	return nil
+}
+
+// loadJWTKey loads the key in the given file.
+func loadJWTKey(path string) (*jwtKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if filepath.Ext(path) == ".hex" {
+		secret := common.FromHex(strings.TrimSpace(string(data)))
+		if len(secret) < 32 {
+			return nil, fmt.Errorf("secret too short: %d bytes, want at least 32", len(secret))
+		}
+		return &jwtKey{key: secret, methods: []string{"HS256"}}, nil
+	}
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+		return &jwtKey{key: key, methods: []string{"RS256", "RS384", "RS512"}}, nil
+	}
+	if key, err := jwt.ParseECPublicKeyFromPEM(data); err == nil {
+		return &jwtKey{key: key, methods: []string{"ES256", "ES384", "ES512"}}, nil
+	}
+	if key, err := jwt.ParseEdPublicKeyFromPEM(data); err == nil {
+		return &jwtKey{key: key, methods: []string{"EdDSA"}}, nil
+	}
+	return nil, errors.New("no RSA, ECDSA or Ed25519 public key")
+}
+
+// keyFunc returns the key verifying the given token.
+func (ks *jwtKeyStore) keyFunc(token *jwt.Token) (interface{}, error) {
+	id, _ := token.Header["kid"].(string)
+	if id == "" {
+		return nil, errors.New("missing key ID")
+	}
+	key, ok := ks.key(id)
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", id)
+	}
+	if !slices.Contains(key.methods, token.Method.Alg()) {
+		return nil, fmt.Errorf("signing method %s not supported by key %q", token.Method.Alg(), id)
+	}
+	return key.key, nil
+}
@@ -55,14 +55,18 @@ type Node struct {
 	state         int           // Tracks state of node lifecycle
 
 	lock          sync.Mutex
-	lifecycles    []Lifecycle // All registered backends, services, and auxiliary services that have a lifecycle
-	rpcAPIs       []rpc.API   // List of APIs currently provided by the node
-	http          *httpServer //
-	ws            *httpServer //
-	httpAuth      *httpServer //
-	wsAuth        *httpServer //
-	ipc           *ipcServer  // Stores information about the ipc http server
-	inprocHandler *rpc.Server // In-process RPC request handler to process the API requests
+	lifecycles    []Lifecycle        // All registered backends, services, and auxiliary services that have a lifecycle
+	rpcAPIs       []rpc.API          // List of APIs currently provided by the node
+	http          *httpServer        //
+	ws            *httpServer        //
+	httpAuth      *httpServer        //
+	wsAuth        *httpServer        //
+	ipc           *ipcServer         // Stores information about the ipc http server
+	binary        *binaryServer      // Serves the binary RPC transports
+	inprocHandler *rpc.Server        // In-process RPC request handler to process the API requests
+	rateLimiter   *rpc.RateLimiter   // Rate limiter shared by the public HTTP and WS endpoints
+	responseCache *rpc.ResponseCache // Response cache shared by the public HTTP and WS endpoints
+	jwtKeys       *jwtKeyStore       // Keys of the client tokens required by the public HTTP and WS endpoints
 
 	databases map[*closeTrackingDB]struct{} // All open databases
 }
@@ -101,11 +105,39 @@ func New(conf *Config) (*Node, error) {
 	if strings.HasSuffix(conf.Name, ".ipc") {
 		return nil, errors.New(`Config.Name cannot end in ".ipc"`)
 	}
+	// The binary RPC transport has none of the access controls of the HTTP and
+	// WebSocket endpoints, refuse to expose it if any of them are configured.
+	if conf.BinaryHost != "" {
+		switch {
+		case conf.RPCRateLimit != nil:
+			return nil, errors.New("binary RPC is not supported with RPC rate limits")
+		case conf.HTTPMethods != nil || conf.WSMethods != nil:
+			return nil, errors.New("binary RPC is not supported with RPC method policies")
+		case conf.JWTKeysDir != "":
+			return nil, errors.New("binary RPC is not supported with JWT client keys")
+		}
+	}
+	var limiter *rpc.RateLimiter
+	if conf.RPCRateLimit != nil {
+		var err error
+		if limiter, err = rpc.NewRateLimiter(*conf.RPCRateLimit); err != nil {
+			return nil, fmt.Errorf("invalid RPC rate limits: %w", err)
+		}
+	}
+	var jwtKeys *jwtKeyStore
+	if conf.JWTKeysDir != "" {
+		var err error
+		if jwtKeys, err = newJWTKeyStore(conf.JWTKeysDir); err != nil {
+			return nil, fmt.Errorf("failed to load JWT keys: %w", err)
+		}
+	}
 	server := rpc.NewServer()
 	server.SetBatchLimits(conf.BatchRequestLimit, conf.BatchResponseMaxSize)
 	node := &Node{
 		config:        conf,
 		inprocHandler: server,
+		rateLimiter:   limiter,
+		jwtKeys:       jwtKeys,
 		eventmux:      new(event.TypeMux),
 		log:           conf.Logger,
 		stop:          make(chan struct{}),
@@ -113,6 +145,10 @@ func New(conf *Config) (*Node, error) {
 		databases:     make(map[*closeTrackingDB]struct{}),
 	}
 
+	if conf.RPCCacheSize > 0 {
+		node.responseCache = rpc.NewResponseCache(conf.RPCCacheSize * 1024 * 1024)
+	}
+
 	// Register built-in APIs.
 	node.rpcAPIs = append(node.rpcAPIs, node.apis()...)
 
@@ -139,6 +175,15 @@ func New(conf *Config) (*Node, error) {
 		node.server.Config.NodeDatabase = node.config.NodeDB()
 	}
 
+	// Check HTTP/WS method policies are valid.
+	for name, policy := range map[string]*rpc.MethodPolicy{"HTTP": conf.HTTPMethods, "WebSocket": conf.WSMethods} {
+		if policy == nil {
+			continue
+		}
+		if err := policy.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid %s method policy: %w", name, err)
+		}
+	}
 	// Check HTTP/WS prefixes are valid.
 	if err := validatePrefix("HTTP", conf.HTTPPathPrefix); err != nil {
 		return nil, err
@@ -153,6 +198,7 @@ func New(conf *Config) (*Node, error) {
 	node.ws = newHTTPServer(node.log, rpc.DefaultHTTPTimeouts)
 	node.wsAuth = newHTTPServer(node.log, rpc.DefaultHTTPTimeouts)
 	node.ipc = newIPCServer(node.log, conf.IPCEndpoint())
+	node.binary = newBinaryServer(node.log)
 
 	return node, nil
 }
@@ -407,6 +453,9 @@ func (n *Node) startRPC() error {
 	rpcConfig := rpcEndpointConfig{
 		batchItemLimit:         n.config.BatchRequestLimit,
 		batchResponseSizeLimit: n.config.BatchResponseMaxSize,
+		rateLimiter:            n.rateLimiter,
+		responseCache:          n.responseCache,
+		jwtKeys:                n.jwtKeys,
 	}
 
 	initHttp := func(server *httpServer, port int) error {
@@ -417,6 +466,7 @@ func (n *Node) startRPC() error {
 			CorsAllowedOrigins: n.config.HTTPCors,
 			Vhosts:             n.config.HTTPVirtualHosts,
 			Modules:            n.config.HTTPModules,
+			Methods:            n.config.HTTPMethods,
 			prefix:             n.config.HTTPPathPrefix,
 			rpcEndpointConfig:  rpcConfig,
 		}); err != nil {
@@ -433,6 +483,7 @@ func (n *Node) startRPC() error {
 		}
 		if err := server.enableWS(openAPIs, wsConfig{
 			Modules:           n.config.WSModules,
+			Methods:           n.config.WSMethods,
 			Origins:           n.config.WSOrigins,
 			prefix:            n.config.WSPathPrefix,
 			rpcEndpointConfig: rpcConfig,
@@ -514,6 +565,13 @@ func (n *Node) startRPC() error {
 			return err
 		}
 	}
+	// Configure the binary RPC transports.
+	if n.config.BinaryHost != "" {
+		n.binary.setListenAddr(n.config.BinaryHost, n.config.BinaryPort)
+		if err := n.binary.start(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -534,6 +592,7 @@ func (n *Node) stopRPC() {
 	n.httpAuth.stop()
 	n.wsAuth.stop()
 	n.ipc.stop()
+	n.binary.stop()
 	n.stopInProc()
 }
 
@@ -605,26 +664,66 @@ func (n *Node) getAPIs() (unauthenticated, all []rpc.API) {
 }
 
 // RegisterHandler mounts a handler on the given path on the canonical HTTP server.
+// If JWT client keys are configured, the handler requires client tokens like the
+// RPC endpoints.
 //
 // The name of the handler is shown in a log message when the HTTP server starts
 // and should be a descriptive term for the service provided by the handler.
 func (n *Node) RegisterHandler(name, path string, handler http.Handler) {
+	n.registerHandler(name, path, handler, true)
+}
+
+// RegisterPublicHandler is like RegisterHandler, but the handler never requires
+// client tokens. It is meant for the endpoints polled by the infrastructure, such
+// as health checks, which can't authenticate.
+func (n *Node) RegisterPublicHandler(name, path string, handler http.Handler) {
+	n.registerHandler(name, path, handler, false)
+}
+
+func (n *Node) registerHandler(name, path string, handler http.Handler, scoped bool) {
 	n.lock.Lock()
 	defer n.lock.Unlock()
 
 	if n.state != initializingState {
 		panic("can't register HTTP handler on running/stopped node")
 	}
-
+	// The handlers share the port of the HTTP RPC endpoint, so they require the
+	// same client tokens. These must grant the namespace named after the first
+	// element of the path, e.g. "graphql" for "/graphql/ui".
+	if scoped && n.jwtKeys != nil {
+		namespace, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+		handler = newJWTHandlerScope(n.jwtKeys, namespace, handler)
+	}
 	n.http.mux.Handle(path, handler)
 	n.http.handlerNames[path] = name
 }
 
+// RegisterBinaryHandler mounts a handler on the given path on the binary RPC
+// server. The handlers are served over HTTP/2, if the binary RPC server is
+// enabled.
+func (n *Node) RegisterBinaryHandler(name, path string, handler http.Handler) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.state != initializingState {
+		panic("can't register binary RPC handler on running/stopped node")
+	}
+	n.binary.mux.Handle(path, handler)
+	n.binary.handlers[path] = name
+}
+
 // Attach creates an RPC client attached to an in-process API handler.
 func (n *Node) Attach() *rpc.Client {
 	return rpc.DialInProc(n.inprocHandler)
 }
 
+// ResponseCache returns the cache of the immutable results served by the HTTP
+// and WebSocket RPC endpoints, or nil if the cache is disabled. Services must
+// invalidate the cached results which are affected by chain reorgs.
+func (n *Node) ResponseCache() *rpc.ResponseCache {
+	return n.responseCache
+}
+
 // RPCHandler returns the in-process RPC request handler.
 func (n *Node) RPCHandler() (*rpc.Server, error) {
 	n.lock.Lock()
@@ -683,6 +782,11 @@ func (n *Node) HTTPEndpoint() string {
 	return "http://" + n.http.listenAddr()
 }
 
+// BinaryEndpoint returns the URL of the binary RPC server.
+func (n *Node) BinaryEndpoint() string {
+	return "http://" + n.binary.listenAddr()
+}
+
 // WSEndpoint returns the current JSON-RPC over WebSocket endpoint.
 func (n *Node) WSEndpoint() string {
 	if n.http.wsAllowed() {
@@ -18,7 +18,10 @@ package node
 
 import (
 	"context"
+	"crypto/ed25519"
 	crand "crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"net/http"
 	"os"
@@ -27,6 +30,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/mclock"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/golang-jwt/jwt/v4"
 )
@@ -235,3 +239,170 @@ func offsetTimeAuth(secret [32]byte, offset time.Duration) rpc.HTTPAuth {
 		return nil
 	}
 }
+
+func scopedAuth(kid string, method jwt.SigningMethod, key interface{}, claims jwt.MapClaims) rpc.HTTPAuth {
+	return func(header http.Header) error {
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["kid"] = kid
+		s, err := token.SignedString(key)
+		if err != nil {
+			return fmt.Errorf("failed to create JWT token: %w", err)
+		}
+		header.Set("Authorization", "Bearer "+s)
+		return nil
+	}
+}
+
+func TestScopedAuthEndpoints(t *testing.T) {
+	var (
+		dir    = t.TempDir()
+		secret = make([]byte, 32)
+	)
+	crand.Read(secret)
+	if err := os.WriteFile(path.Join(dir, "alice.hex"), []byte(hexutil.Encode(secret)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	pub, priv, _ := ed25519.GenerateKey(crand.Reader)
+	der, _ := x509.MarshalPKIXPublicKey(pub)
+	bobPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	if err := os.WriteFile(path.Join(dir, "bob.pem"), bobPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	conf := &Config{
+		HTTPHost:    "127.0.0.1",
+		WSHost:      "127.0.0.1",
+		JWTKeysDir:  dir,
+		WSModules:   []string{"eth", "engine"},
+		HTTPModules: []string{"eth", "engine"},
+	}
+	node, err := New(conf)
+	if err != nil {
+		t.Fatalf("could not create a new node: %v", err)
+	}
+	clock := new(mclock.Simulated)
+	node.jwtKeys.clock, node.jwtKeys.scanned = clock, clock.Now()
+	node.RegisterAPIs([]rpc.API{
+		{Namespace: "engine", Service: helloRPC("hello engine")},
+		{Namespace: "eth", Service: helloRPC("hello eth")},
+	})
+	if err := node.Start(); err != nil {
+		t.Fatalf("failed to start test node: %v", err)
+	}
+	defer node.Close()
+
+	var (
+		expiry = time.Now().Add(time.Hour).Unix()
+		alice  = scopedAuth("alice", jwt.SigningMethodHS256, secret, jwt.MapClaims{"sub": "alice", "exp": expiry, "namespaces": []string{"eth"}})
+		bob    = scopedAuth("bob", jwt.SigningMethodEdDSA, priv, jwt.MapClaims{"sub": "bob", "exp": expiry, "namespaces": []string{"eth", "engine"}})
+	)
+	tests := []struct {
+		name   string
+		auth   rpc.HTTPAuth
+		eth    bool // whether eth_helloWorld may be called
+		engine bool // whether engine_helloWorld may be called
+	}{
+		{name: "hmac", auth: alice, eth: true},
+		{name: "ed25519", auth: bob, eth: true, engine: true},
+		{name: "missing", auth: func(http.Header) error { return nil }},
+		{name: "unknown key", auth: scopedAuth("carol", jwt.SigningMethodHS256, secret, jwt.MapClaims{"sub": "carol", "exp": expiry, "namespaces": []string{"eth"}})},
+		{name: "wrong method", auth: scopedAuth("bob", jwt.SigningMethodHS256, bobPEM, jwt.MapClaims{"sub": "bob", "exp": expiry, "namespaces": []string{"eth"}})},
+		{name: "no subject", auth: scopedAuth("alice", jwt.SigningMethodHS256, secret, jwt.MapClaims{"exp": expiry, "namespaces": []string{"eth"}})},
+		{name: "no expiry", auth: scopedAuth("alice", jwt.SigningMethodHS256, secret, jwt.MapClaims{"sub": "alice", "namespaces": []string{"eth"}})},
+		{name: "expired", auth: scopedAuth("alice", jwt.SigningMethodHS256, secret, jwt.MapClaims{"sub": "alice", "exp": time.Now().Add(-time.Minute).Unix(), "namespaces": []string{"eth"}})},
+		{name: "no scope", auth: scopedAuth("alice", jwt.SigningMethodHS256, secret, jwt.MapClaims{"sub": "alice", "exp": expiry})},
+	}
+	call := func(endpoint string, auth rpc.HTTPAuth, method string) error {
+		cl, err := rpc.DialOptions(context.Background(), endpoint, rpc.WithHTTPAuth(auth))
+		if err != nil {
+			return err
+		}
+		defer cl.Close()
+		var x string
+		return cl.Call(&x, method)
+	}
+	for _, test := range tests {
+		for _, endpoint := range []string{node.HTTPEndpoint(), node.WSEndpoint()} {
+			if err := call(endpoint, test.auth, "eth_helloWorld"); (err == nil) != test.eth {
+				t.Errorf("%s: %s: eth_helloWorld returned %v, want success %t", test.name, endpoint, err, test.eth)
+			}
+			if err := call(endpoint, test.auth, "engine_helloWorld"); (err == nil) != test.engine {
+				t.Errorf("%s: %s: engine_helloWorld returned %v, want success %t", test.name, endpoint, err, test.engine)
+			}
+		}
+	}
+	// Removed keys are rejected after the next reload, added keys accepted
+	os.Rename(path.Join(dir, "alice.hex"), path.Join(dir, "carol.hex"))
+	if err := call(node.HTTPEndpoint(), alice, "eth_helloWorld"); err != nil {
+		t.Fatalf("key reloaded too early: %v", err)
+	}
+	clock.Run(jwtKeysReloadInterval)
+	if err := call(node.HTTPEndpoint(), alice, "eth_helloWorld"); err == nil {
+		t.Fatal("removed key accepted")
+	}
+	carol := scopedAuth("carol", jwt.SigningMethodHS256, secret, jwt.MapClaims{"sub": "carol", "exp": expiry, "namespaces": []string{"eth"}})
+	if err := call(node.HTTPEndpoint(), carol, "eth_helloWorld"); err != nil {
+		t.Fatalf("added key rejected: %v", err)
+	}
+}
+
+// Tests that the handlers registered on the HTTP server require client tokens
+// granting their namespace, when JWT client keys are configured.
+func TestScopedAuthHandlers(t *testing.T) {
+	var (
+		dir    = t.TempDir()
+		secret = make([]byte, 32)
+	)
+	crand.Read(secret)
+	if err := os.WriteFile(path.Join(dir, "alice.hex"), []byte(hexutil.Encode(secret)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	node, err := New(&Config{HTTPHost: "127.0.0.1", JWTKeysDir: dir})
+	if err != nil {
+		t.Fatalf("could not create a new node: %v", err)
+	}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	node.RegisterHandler("Test", "/test/", ok)
+	node.RegisterPublicHandler("Public", "/public", ok)
+	if err := node.Start(); err != nil {
+		t.Fatalf("failed to start test node: %v", err)
+	}
+	defer node.Close()
+
+	expiry := time.Now().Add(time.Hour).Unix()
+	tests := []struct {
+		name string
+		auth rpc.HTTPAuth
+		want int
+	}{
+		{name: "missing", auth: func(http.Header) error { return nil }, want: http.StatusUnauthorized},
+		{name: "expired", auth: scopedAuth("alice", jwt.SigningMethodHS256, secret, jwt.MapClaims{"sub": "alice", "exp": time.Now().Add(-time.Minute).Unix(), "namespaces": []string{"test"}}), want: http.StatusUnauthorized},
+		{name: "other scope", auth: scopedAuth("alice", jwt.SigningMethodHS256, secret, jwt.MapClaims{"sub": "alice", "exp": expiry, "namespaces": []string{"eth"}}), want: http.StatusForbidden},
+		{name: "namespace", auth: scopedAuth("alice", jwt.SigningMethodHS256, secret, jwt.MapClaims{"sub": "alice", "exp": expiry, "namespaces": []string{"test"}}), want: http.StatusOK},
+		{name: "prefix", auth: scopedAuth("alice", jwt.SigningMethodHS256, secret, jwt.MapClaims{"sub": "alice", "exp": expiry, "namespaces": []string{"te*"}}), want: http.StatusOK},
+	}
+	for _, test := range tests {
+		req, _ := http.NewRequest(http.MethodGet, node.HTTPEndpoint()+"/test/ui", nil)
+		if err := test.auth(req.Header); err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s: request failed: %v", test.name, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != test.want {
+			t.Errorf("%s: wrong status: have %d, want %d", test.name, resp.StatusCode, test.want)
+		}
+	}
+	// Public handlers don't require tokens
+	resp, err := http.Get(node.HTTPEndpoint() + "/public")
+	if err != nil {
+		t.Fatalf("public request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("public handler: wrong status: have %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
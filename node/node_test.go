@@ -105,6 +105,26 @@ func TestNodeUsedDataDir(t *testing.T) {
 	}
 }
 
+// Tests that the binary RPC transport is refused if access controls of the
+// HTTP and WebSocket endpoints are configured, which it would bypass.
+func TestNodeBinaryRPCAccessControl(t *testing.T) {
+	configs := map[string]func(*Config){
+		"rate limits":     func(c *Config) { c.RPCRateLimit = &rpc.RateLimitConfig{} },
+		"HTTP methods":    func(c *Config) { c.HTTPMethods = &rpc.MethodPolicy{Deny: []string{"debug"}} },
+		"WS methods":      func(c *Config) { c.WSMethods = &rpc.MethodPolicy{Deny: []string{"debug"}} },
+		"JWT client keys": func(c *Config) { c.JWTKeysDir = t.TempDir() },
+	}
+	for name, configure := range configs {
+		conf := testNodeConfig()
+		conf.BinaryHost = "127.0.0.1"
+		configure(conf)
+		if stack, err := New(conf); err == nil {
+			stack.Close()
+			t.Errorf("%s: binary RPC enabled", name)
+		}
+	}
+}
+
 // Tests whether a Lifecycle can be registered.
 func TestLifecycleRegistry_Successful(t *testing.T) {
 	stack, err := New(testNodeConfig())
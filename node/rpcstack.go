@@ -33,11 +33,14 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/rs/cors"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 // httpConfig is the JSON-RPC/HTTP configuration.
 type httpConfig struct {
 	Modules            []string
+	Methods            *rpc.MethodPolicy // optional method allow/deny rules
 	CorsAllowedOrigins []string
 	Vhosts             []string
 	prefix             string // path prefix on which to mount http handler
@@ -48,7 +51,8 @@ type httpConfig struct {
 type wsConfig struct {
 	Origins []string
 	Modules []string
-	prefix  string // path prefix on which to mount ws handler
+	Methods *rpc.MethodPolicy // optional method allow/deny rules
+	prefix  string            // path prefix on which to mount ws handler
 	rpcEndpointConfig
 }
 
@@ -57,6 +61,9 @@ type rpcEndpointConfig struct {
 	batchItemLimit         int
 	batchResponseSizeLimit int
 	httpBodyLimit          int
+	rateLimiter            *rpc.RateLimiter   // optional per-client rate limits
+	responseCache          *rpc.ResponseCache // optional cache of immutable results
+	jwtKeys                *jwtKeyStore       // optional keys of the scoped client tokens
 }
 
 type rpcHandler struct {
@@ -305,6 +312,15 @@ func (h *httpServer) enableRPC(apis []rpc.API, config httpConfig) error {
 	// Create RPC server and handler.
 	srv := rpc.NewServer()
 	srv.SetBatchLimits(config.batchItemLimit, config.batchResponseSizeLimit)
+	if config.Methods != nil {
+		srv.SetMethodPolicy(config.Methods)
+	}
+	if config.rateLimiter != nil {
+		srv.SetRateLimiter(config.rateLimiter)
+	}
+	if config.responseCache != nil {
+		srv.SetResponseCache(config.responseCache)
+	}
 	if config.httpBodyLimit > 0 {
 		srv.SetHTTPBodyLimit(config.httpBodyLimit)
 	}
@@ -312,8 +328,12 @@ func (h *httpServer) enableRPC(apis []rpc.API, config httpConfig) error {
 		return err
 	}
 	h.httpConfig = config
+	var handler http.Handler = srv
+	if config.jwtKeys != nil {
+		handler = newJWTScopeHandler(config.jwtKeys, handler)
+	}
 	h.httpHandler.Store(&rpcHandler{
-		Handler: NewHTTPHandlerStack(srv, config.CorsAllowedOrigins, config.Vhosts, config.jwtSecret),
+		Handler: NewHTTPHandlerStack(handler, config.CorsAllowedOrigins, config.Vhosts, config.jwtSecret),
 		server:  srv,
 	})
 	return nil
@@ -340,6 +360,15 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig) error {
 	// Create RPC server and handler.
 	srv := rpc.NewServer()
 	srv.SetBatchLimits(config.batchItemLimit, config.batchResponseSizeLimit)
+	if config.Methods != nil {
+		srv.SetMethodPolicy(config.Methods)
+	}
+	if config.rateLimiter != nil {
+		srv.SetRateLimiter(config.rateLimiter)
+	}
+	if config.responseCache != nil {
+		srv.SetResponseCache(config.responseCache)
+	}
 	if config.httpBodyLimit > 0 {
 		srv.SetHTTPBodyLimit(config.httpBodyLimit)
 	}
@@ -347,8 +376,12 @@ func (h *httpServer) enableWS(apis []rpc.API, config wsConfig) error {
 		return err
 	}
 	h.wsConfig = config
+	handler := srv.WebsocketHandler(config.Origins)
+	if config.jwtKeys != nil {
+		handler = newJWTScopeHandler(config.jwtKeys, handler)
+	}
 	h.wsHandler.Store(&rpcHandler{
-		Handler: NewWSHandlerStack(srv.WebsocketHandler(config.Origins), config.jwtSecret),
+		Handler: NewWSHandlerStack(handler, config.jwtSecret),
 		server:  srv,
 	})
 	return nil
@@ -649,3 +682,98 @@ func RegisterApis(apis []rpc.API, modules []string, srv *rpc.Server) error {
 	}
 	return nil
 }
+
+// binaryServer serves the handlers of the binary RPC transports over HTTP/2.
+// Cleartext HTTP/2 (h2c) is accepted, so that clients can multiplex calls and
+// streaming subscriptions over a single connection without TLS.
+type binaryServer struct {
+	log      log.Logger
+	mux      http.ServeMux
+	handlers map[string]string // registered handler names by path
+	endpoint string
+
+	mu       sync.Mutex
+	server   *http.Server
+	listener net.Listener
+	cancel   context.CancelFunc // cancels the context of the in-flight requests
+}
+
+func newBinaryServer(log log.Logger) *binaryServer {
+	return &binaryServer{log: log, handlers: make(map[string]string)}
+}
+
+// setListenAddr configures the listening address of the server.
+func (bs *binaryServer) setListenAddr(host string, port int) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	bs.endpoint = net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// listenAddr returns the listening address of the server.
+func (bs *binaryServer) listenAddr() string {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	if bs.listener != nil {
+		return bs.listener.Addr().String()
+	}
+	return bs.endpoint
+}
+
+// start starts the server if it is configured and has any handlers registered.
+func (bs *binaryServer) start() error {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	if bs.endpoint == "" || bs.listener != nil || len(bs.handlers) == 0 {
+		return nil // already running, not configured or nothing to serve
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	bs.server = &http.Server{
+		Handler:           h2c.NewHandler(&bs.mux, &http2.Server{}),
+		ReadHeaderTimeout: rpc.DefaultHTTPTimeouts.ReadHeaderTimeout,
+		IdleTimeout:       rpc.DefaultHTTPTimeouts.IdleTimeout,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	listener, err := net.Listen("tcp", bs.endpoint)
+	if err != nil {
+		cancel()
+		return err
+	}
+	bs.listener, bs.cancel = listener, cancel
+	go bs.server.Serve(listener)
+
+	paths := make([]string, 0, len(bs.handlers))
+	for path := range bs.handlers {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		bs.log.Info(bs.handlers[path]+" enabled", "url", "http://"+listener.Addr().String()+path)
+	}
+	return nil
+}
+
+// stop terminates the in-flight requests, including the streaming ones, and
+// shuts down the server.
+func (bs *binaryServer) stop() {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	if bs.listener == nil {
+		return // not running
+	}
+	bs.cancel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := bs.server.Shutdown(ctx); err != nil && err == ctx.Err() {
+		bs.log.Warn("Binary RPC server graceful shutdown timed out")
+		bs.server.Close()
+	}
+	bs.listener.Close()
+	bs.log.Info("Binary RPC server stopped", "endpoint", bs.listener.Addr())
+
+	bs.server, bs.listener, bs.cancel = nil, nil, nil
+}
@@ -359,6 +359,15 @@ type ChainConfig struct {
 	PragueTime   *uint64 `json:"pragueTime,omitempty"`   // Prague switch time (nil = no fork, 0 = already on prague)
 	VerkleTime   *uint64 `json:"verkleTime,omitempty"`   // Verkle switch time (nil = no fork, 0 = already on verkle)
 
+	P256VerifyTime     *uint64 `json:"p256VerifyTime,omitempty"`     // JuChain P256VERIFY precompile (RIP-7212) switch time (nil = no fork, 0 = already activated)
+	HistoryStorageTime *uint64 `json:"historyStorageTime,omitempty"` // JuChain block hash history storage (EIP-2935) switch time (nil = no fork, 0 = already activated)
+	PaymasterTime      *uint64 `json:"paymasterTime,omitempty"`      // JuChain paymaster sponsored transactions switch time (nil = no fork, 0 = already activated)
+	BlobDisableTime    *uint64 `json:"blobDisableTime,omitempty"`    // JuChain blob transactions (EIP-4844) removal time (nil = no fork, 0 = already activated)
+
+	// GasSchedules are the JuChain gas cost overrides of private deployments,
+	// sorted by activation timestamp.
+	GasSchedules []*GasSchedule `json:"gasSchedules,omitempty"`
+
 	// TerminalTotalDifficulty is the amount of total difficulty reached by
 	// the network that triggers the consensus upgrade.
 	TerminalTotalDifficulty *big.Int `json:"terminalTotalDifficulty,omitempty"`
@@ -504,6 +513,21 @@ func (c *ChainConfig) Description() string {
 	if c.VerkleTime != nil {
 		banner += fmt.Sprintf(" - Verkle:                      @%-10v\n", *c.VerkleTime)
 	}
+	if c.P256VerifyTime != nil {
+		banner += fmt.Sprintf(" - P256 Verify (RIP-7212):      @%-10v\n", *c.P256VerifyTime)
+	}
+	if c.HistoryStorageTime != nil {
+		banner += fmt.Sprintf(" - History Storage (EIP-2935):  @%-10v\n", *c.HistoryStorageTime)
+	}
+	if c.PaymasterTime != nil {
+		banner += fmt.Sprintf(" - Paymaster Sponsorship:       @%-10v\n", *c.PaymasterTime)
+	}
+	if c.BlobDisableTime != nil {
+		banner += fmt.Sprintf(" - Blob Transactions Disabled:  @%-10v\n", *c.BlobDisableTime)
+	}
+	for _, schedule := range c.GasSchedules {
+		banner += fmt.Sprintf(" - Gas Schedule Override:       @%-10v\n", schedule.Time)
+	}
 	return banner
 }
 
@@ -612,6 +636,34 @@ func (c *ChainConfig) IsVerkle(num *big.Int, time uint64) bool {
 	return c.IsLondon(num) && isTimestampForked(c.VerkleTime, time)
 }
 
+// IsP256Verify returns whether time is either equal to the P256VERIFY
+// precompile fork time or greater.
+func (c *ChainConfig) IsP256Verify(num *big.Int, time uint64) bool {
+	return c.IsLondon(num) && isTimestampForked(c.P256VerifyTime, time)
+}
+
+// IsBlobDisabled returns whether time is either equal to the blob transactions
+// removal fork time or greater. From then on blob transactions are rejected in
+// blocks, in the transaction pool and over RPC, as Congress validators don't
+// carry blob sidecars and the data of included blobs would not be available.
+func (c *ChainConfig) IsBlobDisabled(num *big.Int, time uint64) bool {
+	return c.IsLondon(num) && isTimestampForked(c.BlobDisableTime, time)
+}
+
+// IsHistoryStorage returns whether time is either equal to the block hash
+// history storage fork time or greater. The fork requires Shanghai, as the
+// history storage contract uses PUSH0.
+func (c *ChainConfig) IsHistoryStorage(num *big.Int, time uint64) bool {
+	return c.IsShanghai(num, time) && isTimestampForked(c.HistoryStorageTime, time)
+}
+
+// IsPaymaster returns whether time is either equal to the paymaster sponsored
+// transactions fork time or greater. Sponsored transactions have dynamic fees,
+// so the fork requires London.
+func (c *ChainConfig) IsPaymaster(num *big.Int, time uint64) bool {
+	return c.IsLondon(num) && isTimestampForked(c.PaymasterTime, time)
+}
+
 // CheckCompatible checks whether scheduled fork transitions have been imported
 // with a mismatching chain configuration.
 func (c *ChainConfig) CheckCompatible(newcfg *ChainConfig, height uint64, time uint64) *ConfigCompatError {
@@ -638,7 +690,8 @@ func (c *ChainConfig) CheckCompatible(newcfg *ChainConfig, height uint64, time u
 }
 
 // CheckConfigForkOrder checks that we don't "skip" any forks, geth isn't pluggable enough
-// to guarantee that forks can be implemented in a different order than on official networks
+// to guarantee that forks can be implemented in a different order than on official networks.
+// It also checks the ordering and the intrinsic gas constants of the gas schedule overrides.
 func (c *ChainConfig) CheckConfigForkOrder() error {
 	type fork struct {
 		name      string
@@ -703,7 +756,7 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 			lastFork = cur
 		}
 	}
-	return nil
+	return c.checkGasSchedules()
 }
 
 func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, headNumber *big.Int, headTimestamp uint64) *ConfigCompatError {
@@ -777,6 +830,21 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, headNumber *big.Int,
 	if isForkTimestampIncompatible(c.VerkleTime, newcfg.VerkleTime, headTimestamp) {
 		return newTimestampCompatError("Verkle fork timestamp", c.VerkleTime, newcfg.VerkleTime)
 	}
+	if isForkTimestampIncompatible(c.P256VerifyTime, newcfg.P256VerifyTime, headTimestamp) {
+		return newTimestampCompatError("P256 verify fork timestamp", c.P256VerifyTime, newcfg.P256VerifyTime)
+	}
+	if isForkTimestampIncompatible(c.HistoryStorageTime, newcfg.HistoryStorageTime, headTimestamp) {
+		return newTimestampCompatError("History storage fork timestamp", c.HistoryStorageTime, newcfg.HistoryStorageTime)
+	}
+	if isForkTimestampIncompatible(c.PaymasterTime, newcfg.PaymasterTime, headTimestamp) {
+		return newTimestampCompatError("Paymaster fork timestamp", c.PaymasterTime, newcfg.PaymasterTime)
+	}
+	if isForkTimestampIncompatible(c.BlobDisableTime, newcfg.BlobDisableTime, headTimestamp) {
+		return newTimestampCompatError("Blob disable fork timestamp", c.BlobDisableTime, newcfg.BlobDisableTime)
+	}
+	if err := isGasScheduleIncompatible(c.GasSchedules, newcfg.GasSchedules, headTimestamp); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -940,6 +1008,10 @@ type Rules struct {
 	IsBerlin, IsLondon, IsBlackList                         bool
 	IsMerge, IsShanghai, IsCancun, IsPrague                 bool
 	IsVerkle                                                bool
+	IsP256Verify, IsPaymaster                               bool
+
+	// GasSchedule is the gas schedule override in effect, nil if none.
+	GasSchedule *GasSchedule
 }
 
 // Rules ensures c's ChainID is not nil.
@@ -973,5 +1045,9 @@ func (c *ChainConfig) Rules(num *big.Int, isMerge bool, timestamp uint64) Rules
 		IsCancun:   c.IsCancun(num, timestamp),
 		IsPrague:   c.IsPrague(num, timestamp),
 		IsVerkle:   c.IsVerkle(num, timestamp),
+
+		IsP256Verify: c.IsP256Verify(num, timestamp),
+		IsPaymaster:  c.IsPaymaster(num, timestamp),
+		GasSchedule:  c.GasScheduleAt(timestamp),
 	}
 }
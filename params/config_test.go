@@ -109,6 +109,46 @@ func TestCheckCompatible(t *testing.T) {
 				RewindToTime: 9,
 			},
 		},
+		{
+			stored:        &ChainConfig{BlobDisableTime: newUint64(10)},
+			new:           &ChainConfig{},
+			headTimestamp: 9,
+			wantErr:       nil,
+		},
+		{
+			stored:        &ChainConfig{BlobDisableTime: newUint64(10)},
+			new:           &ChainConfig{},
+			headTimestamp: 25,
+			wantErr: &ConfigCompatError{
+				What:         "Blob disable fork timestamp",
+				StoredTime:   newUint64(10),
+				NewTime:      nil,
+				RewindToTime: 9,
+			},
+		},
+		{
+			stored:        &ChainConfig{GasSchedules: []*GasSchedule{{Time: 10, Storage: map[string]uint64{StorageSstoreSetGas: 10000}}}},
+			new:           &ChainConfig{GasSchedules: []*GasSchedule{{Time: 10, Storage: map[string]uint64{StorageSstoreSetGas: 15000}}}},
+			headTimestamp: 9,
+			wantErr:       nil,
+		},
+		{
+			stored:        &ChainConfig{GasSchedules: []*GasSchedule{{Time: 10, Storage: map[string]uint64{StorageSstoreSetGas: 10000}}}},
+			new:           &ChainConfig{GasSchedules: []*GasSchedule{{Time: 10, Storage: map[string]uint64{StorageSstoreSetGas: 15000}}}},
+			headTimestamp: 25,
+			wantErr: &ConfigCompatError{
+				What:         "Gas schedule",
+				StoredTime:   newUint64(10),
+				NewTime:      newUint64(10),
+				RewindToTime: 9,
+			},
+		},
+		{
+			stored:        &ChainConfig{GasSchedules: []*GasSchedule{{Time: 10}}},
+			new:           &ChainConfig{GasSchedules: []*GasSchedule{{Time: 10}, {Time: 30}}},
+			headTimestamp: 25,
+			wantErr:       nil,
+		},
 	}
 
 	for _, test := range tests {
@@ -137,3 +177,48 @@ func TestConfigRules(t *testing.T) {
 		t.Errorf("expected %v to be shanghai", stamp)
 	}
 }
+
+func TestGasSchedules(t *testing.T) {
+	c := &ChainConfig{
+		GasSchedules: []*GasSchedule{
+			{Time: 10, Intrinsic: map[string]uint64{IntrinsicTxGas: 1000}, Storage: map[string]uint64{StorageSstoreSetGas: 5000}},
+			{Time: 20},
+		},
+	}
+	if err := c.checkGasSchedules(); err != nil {
+		t.Fatalf("valid schedules rejected: %v", err)
+	}
+	if s := c.GasScheduleAt(9); s != nil {
+		t.Errorf("schedule active before activation: %v", s)
+	}
+	if gas := c.GasScheduleAt(15).IntrinsicGas(IntrinsicTxGas, TxGas); gas != 1000 {
+		t.Errorf("intrinsic gas mismatch: have %d, want %d", gas, 1000)
+	}
+	if gas := c.GasScheduleAt(15).StorageGas(StorageSstoreSetGas, SstoreSetGasEIP2200); gas != 5000 {
+		t.Errorf("storage gas mismatch: have %d, want %d", gas, 5000)
+	}
+	// Later schedules replace the earlier ones
+	if gas := c.GasScheduleAt(20).IntrinsicGas(IntrinsicTxGas, TxGas); gas != TxGas {
+		t.Errorf("intrinsic gas mismatch: have %d, want %d", gas, TxGas)
+	}
+	if gas := c.GasScheduleAt(20).StorageGas(StorageSstoreSetGas, SstoreSetGasEIP2200); gas != SstoreSetGasEIP2200 {
+		t.Errorf("storage gas mismatch: have %d, want %d", gas, SstoreSetGasEIP2200)
+	}
+	if r := c.Rules(new(big.Int), false, 15); r.GasSchedule != c.GasSchedules[0] {
+		t.Errorf("rules schedule mismatch: have %v, want %v", r.GasSchedule, c.GasSchedules[0])
+	}
+
+	for i, invalid := range [][]*GasSchedule{
+		{{Time: 20}, {Time: 10}},
+		{{Time: 10}, {Time: 10}},
+		{{Time: 10}, nil},
+		{{Intrinsic: map[string]uint64{"SloadGas": 1}}},
+		{{Intrinsic: map[string]uint64{IntrinsicTxDataZeroGas: 0}}},
+		{{Storage: map[string]uint64{IntrinsicTxGas: 1}}},
+	} {
+		c := &ChainConfig{GasSchedules: invalid}
+		if err := c.checkGasSchedules(); err == nil {
+			t.Errorf("test %d: invalid schedules accepted", i)
+		}
+	}
+}
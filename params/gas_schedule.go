@@ -0,0 +1,175 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Names of the intrinsic gas constants a gas schedule may override.
+const (
+	IntrinsicTxGas                     = "TxGas"
+	IntrinsicTxGasContractCreation     = "TxGasContractCreation"
+	IntrinsicTxDataZeroGas             = "TxDataZeroGas"
+	IntrinsicTxDataNonZeroGas          = "TxDataNonZeroGas"
+	IntrinsicInitCodeWordGas           = "InitCodeWordGas"
+	IntrinsicTxAccessListAddressGas    = "TxAccessListAddressGas"
+	IntrinsicTxAccessListStorageKeyGas = "TxAccessListStorageKeyGas"
+)
+
+// intrinsicGasNames is the set of overridable intrinsic gas constants, mapped
+// to whether the constant is used as a divisor and thus can't be zero.
+var intrinsicGasNames = map[string]bool{
+	IntrinsicTxGas:                     false,
+	IntrinsicTxGasContractCreation:     false,
+	IntrinsicTxDataZeroGas:             true,
+	IntrinsicTxDataNonZeroGas:          true,
+	IntrinsicInitCodeWordGas:           true,
+	IntrinsicTxAccessListAddressGas:    false,
+	IntrinsicTxAccessListStorageKeyGas: false,
+}
+
+// Names of the storage gas constants a gas schedule may override. They apply to
+// SLOAD and SSTORE from EIP-2200 (Istanbul) on.
+const (
+	StorageSloadGas                   = "SloadGas"                   // SLOAD_GAS of EIP-2200, before EIP-2929
+	StorageColdSloadCost              = "ColdSloadCost"              // COLD_SLOAD_COST of EIP-2929
+	StorageWarmStorageReadCost        = "WarmStorageReadCost"        // WARM_STORAGE_READ_COST of EIP-2929
+	StorageSstoreSetGas               = "SstoreSetGas"               // SSTORE_SET_GAS
+	StorageSstoreResetGas             = "SstoreResetGas"             // SSTORE_RESET_GAS
+	StorageSstoreClearsScheduleRefund = "SstoreClearsScheduleRefund" // SSTORE_CLEARS_SCHEDULE
+)
+
+// storageGasNames is the set of overridable storage gas constants.
+var storageGasNames = map[string]struct{}{
+	StorageSloadGas:                   {},
+	StorageColdSloadCost:              {},
+	StorageWarmStorageReadCost:        {},
+	StorageSstoreSetGas:               {},
+	StorageSstoreResetGas:             {},
+	StorageSstoreClearsScheduleRefund: {},
+}
+
+// GasSchedule is a set of gas cost overrides for private JuChain deployments.
+// A schedule becomes active at its timestamp and stays active until a later
+// schedule of the chain config replaces it. Schedules are not merged, every
+// schedule lists all the overrides in effect from its activation on.
+type GasSchedule struct {
+	Time        uint64                    `json:"time"`                  // Activation timestamp (0 = already activated)
+	Opcodes     map[string]uint64         `json:"opcodes,omitempty"`     // Gas of opcodes without dynamic gas by name (e.g. "ADD")
+	Precompiles map[common.Address]uint64 `json:"precompiles,omitempty"` // Flat gas of precompiled contracts by address
+	Intrinsic   map[string]uint64         `json:"intrinsic,omitempty"`   // Intrinsic gas constants by name (e.g. "TxGas")
+	Storage     map[string]uint64         `json:"storage,omitempty"`     // Storage gas constants by name (e.g. "SstoreSetGas")
+}
+
+// IntrinsicGas returns the overridden value of the named intrinsic gas
+// constant, or def if the schedule is nil or doesn't override it.
+func (s *GasSchedule) IntrinsicGas(name string, def uint64) uint64 {
+	if s == nil {
+		return def
+	}
+	if gas, ok := s.Intrinsic[name]; ok {
+		return gas
+	}
+	return def
+}
+
+// StorageGas returns the overridden value of the named storage gas constant, or
+// def if the schedule is nil or doesn't override it.
+func (s *GasSchedule) StorageGas(name string, def uint64) uint64 {
+	if s == nil {
+		return def
+	}
+	if gas, ok := s.Storage[name]; ok {
+		return gas
+	}
+	return def
+}
+
+// Validate checks the intrinsic and storage gas overrides of the schedule. Opcode names and
+// precompile addresses are checked by the vm package, which defines them.
+func (s *GasSchedule) Validate() error {
+	for name, gas := range s.Intrinsic {
+		divisor, ok := intrinsicGasNames[name]
+		if !ok {
+			return fmt.Errorf("gas schedule at timestamp %d: unknown intrinsic gas constant %q", s.Time, name)
+		}
+		if divisor && gas == 0 {
+			return fmt.Errorf("gas schedule at timestamp %d: intrinsic gas constant %q can't be zero", s.Time, name)
+		}
+	}
+	for name := range s.Storage {
+		if _, ok := storageGasNames[name]; !ok {
+			return fmt.Errorf("gas schedule at timestamp %d: unknown storage gas constant %q", s.Time, name)
+		}
+	}
+	return nil
+}
+
+// GasScheduleAt returns the gas schedule active at the given timestamp, or nil
+// if no schedule is activated yet.
+func (c *ChainConfig) GasScheduleAt(time uint64) *GasSchedule {
+	for i := len(c.GasSchedules) - 1; i >= 0; i-- {
+		if c.GasSchedules[i].Time <= time {
+			return c.GasSchedules[i]
+		}
+	}
+	return nil
+}
+
+// checkGasSchedules checks that the gas schedules are valid and sorted by
+// strictly increasing activation timestamps.
+func (c *ChainConfig) checkGasSchedules() error {
+	for i, schedule := range c.GasSchedules {
+		if schedule == nil {
+			return fmt.Errorf("gas schedule %d is empty", i)
+		}
+		if i > 0 && c.GasSchedules[i-1].Time >= schedule.Time {
+			return fmt.Errorf("unsupported gas schedule ordering: schedule %d activated at timestamp %d, but schedule %d at timestamp %d",
+				i-1, c.GasSchedules[i-1].Time, i, schedule.Time)
+		}
+		if err := schedule.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isGasScheduleIncompatible returns an error if the gas schedules activated at
+// the head timestamp differ between the stored and the new config. The chain
+// needs to be rewound to the first differing schedule.
+func isGasScheduleIncompatible(stored, updated []*GasSchedule, headTimestamp uint64) *ConfigCompatError {
+	for i := 0; i < len(stored) || i < len(updated); i++ {
+		var s1, s2 *uint64
+		if i < len(stored) && stored[i].Time <= headTimestamp {
+			s1 = &stored[i].Time
+		}
+		if i < len(updated) && updated[i].Time <= headTimestamp {
+			s2 = &updated[i].Time
+		}
+		if s1 == nil && s2 == nil {
+			return nil
+		}
+		if s1 == nil || s2 == nil || !reflect.DeepEqual(stored[i], updated[i]) {
+			return newTimestampCompatError("Gas schedule", s1, s2)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// authCallCounter counts the calls of authenticated clients, labelled by their
+// subject and the outcome of the call.
+var authCallCounter = metrics.NewRegisteredCounterVec("rpc/auth/calls", nil, "subject", "status")
+
+// AuthInfo describes an authenticated client and the scope of its access, as
+// granted by the credentials it presented to the HTTP or WebSocket endpoint.
+type AuthInfo struct {
+	// Subject identifies the client in logs and metrics.
+	Subject string
+
+	// Namespaces are the methods the client may call. They are matched like
+	// the rules of a MethodPolicy: by full name, by a name prefix ending with
+	// an asterisk or by namespace. The methods of the rpc namespace are always
+	// allowed.
+	Namespaces []string
+
+	// Tier selects the rate limits of the client, see RateLimitConfig.
+	Tier string
+
+	// Expiry is the time the access of the client ends. A zero expiry never
+	// ends. It is enforced on every call, because WebSocket connections may
+	// outlive it.
+	Expiry time.Time
+}
+
+type authContextKey struct{}
+
+// WithAuth returns a copy of the context carrying the given client information.
+// HTTP handlers authenticating the clients in front of a server use it to pass
+// the client information on to the server, in the context of the request.
+func WithAuth(ctx context.Context, info *AuthInfo) context.Context {
+	return context.WithValue(ctx, authContextKey{}, info)
+}
+
+// authFromContext returns the client information attached to the context by
+// WithAuth, or nil if the client isn't authenticated.
+func authFromContext(ctx context.Context) *AuthInfo {
+	info, _ := ctx.Value(authContextKey{}).(*AuthInfo)
+	return info
+}
+
+// authorize checks whether the client may call the given method.
+func (a *AuthInfo) authorize(method string) error {
+	if !a.Expiry.IsZero() && time.Now().After(a.Expiry) {
+		return &authExpiredError{}
+	}
+	if namespace, _, _ := strings.Cut(method, serviceMethodSeparator); namespace == MetadataApi {
+		return nil
+	}
+	if matchRules(a.Namespaces, method) < 0 {
+		return &methodNotAllowedError{method: method}
+	}
+	return nil
+}
+
+// authExpiredError is returned for calls of clients whose access expired.
+type authExpiredError struct{}
+
+func (e *authExpiredError) ErrorCode() int { return errcodeDefault }
+
+func (e *authExpiredError) Error() string { return "authorization expired" }
+
+// countAuthCall records a call of an authenticated client in the metrics.
+func countAuthCall(info *AuthInfo, success bool) {
+	status := "success"
+	if !success {
+		status = "failure"
+	}
+	authCallCounter.With(info.Subject, status).Inc(1)
+}
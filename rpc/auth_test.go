@@ -0,0 +1,123 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// authHandler authenticates every request as the given client.
+func authHandler(info *AuthInfo, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(WithAuth(r.Context(), info)))
+	})
+}
+
+func TestServerAuth(t *testing.T) {
+	server := newTestServer()
+	defer server.Stop()
+
+	var (
+		scoped = &AuthInfo{Subject: "alice", Namespaces: []string{"test_echo*"}}
+		full   = &AuthInfo{Subject: "bob", Namespaces: []string{"test", "nftest"}}
+		// Expired while a WebSocket connection is still open
+		expired = &AuthInfo{Subject: "carol", Namespaces: []string{"test"}, Expiry: time.Now().Add(time.Second)}
+	)
+	for _, transport := range []string{"http", "ws"} {
+		dial := func(info *AuthInfo) *Client {
+			t.Helper()
+			handler := http.Handler(server)
+			if transport == "ws" {
+				handler = server.WebsocketHandler([]string{"*"})
+			}
+			ts := httptest.NewServer(authHandler(info, handler))
+			t.Cleanup(ts.Close)
+			url := ts.URL
+			if transport == "ws" {
+				url = "ws:" + strings.TrimPrefix(url, "http:")
+			}
+			client, err := Dial(url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(client.Close)
+			return client
+		}
+		call := func(client *Client, method string, code int, args ...interface{}) {
+			t.Helper()
+			err := client.Call(nil, method, args...)
+			var rpcErr Error
+			switch {
+			case code == 0 && err != nil:
+				t.Errorf("%s: %s failed: %v", transport, method, err)
+			case code != 0 && (!errors.As(err, &rpcErr) || rpcErr.ErrorCode() != code):
+				t.Errorf("%s: %s returned %v, want error code %d", transport, method, err, code)
+			}
+		}
+		client := dial(scoped)
+		call(client, "test_echo", 0, "x", 1)
+		call(client, "test_noArgsRets", -32601)
+		if _, err := client.SupportedModules(); err != nil {
+			t.Errorf("%s: rpc namespace not allowed: %v", transport, err)
+		}
+		call(dial(full), "test_noArgsRets", 0)
+
+		client = dial(expired)
+		if transport == "ws" {
+			call(client, "test_noArgsRets", 0)
+		}
+		expired.Expiry = time.Now().Add(-time.Second)
+		call(client, "test_noArgsRets", errcodeDefault)
+		expired.Expiry = time.Now().Add(time.Second)
+	}
+}
+
+func TestRateLimiterTiers(t *testing.T) {
+	limiter, err := NewRateLimiter(RateLimitConfig{
+		Limit: RateLimit{Rate: 0.001, Burst: 1},
+		Tiers: map[string]RateTier{"gold": {Limit: RateLimit{Rate: 0.001, Burst: 3}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	peer := func(subject, tier string) context.Context {
+		info := PeerInfo{RemoteAddr: "10.0.0.1:1000", Auth: &AuthInfo{Subject: subject, Tier: tier}}
+		return context.WithValue(context.Background(), peerInfoContextKey{}, info)
+	}
+	allowed := func(ctx context.Context) (n int) {
+		for ; limiter.allow(ctx, "eth_blockNumber") == nil; n++ {
+		}
+		return n
+	}
+	// Clients are limited by tier, not by their shared IP address
+	if n := allowed(peer("alice", "gold")); n != 3 {
+		t.Errorf("gold tier allowed %d calls, want 3", n)
+	}
+	if n := allowed(peer("bob", "gold")); n != 3 {
+		t.Errorf("second gold client allowed %d calls, want 3", n)
+	}
+	// Unknown tiers fall back to the default limits
+	if n := allowed(peer("alice", "platinum")); n != 1 {
+		t.Errorf("unknown tier allowed %d calls, want 1", n)
+	}
+}
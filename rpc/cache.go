@@ -0,0 +1,175 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	cacheHitMeter   = metrics.NewRegisteredMeter("rpc/cache/hit", nil)
+	cacheMissMeter  = metrics.NewRegisteredMeter("rpc/cache/miss", nil)
+	cacheSizeGauge  = metrics.NewRegisteredGauge("rpc/cache/size", nil)
+	cacheEvictMeter = metrics.NewRegisteredMeter("rpc/cache/evict", nil)
+)
+
+// ResponseCache is a memory bounded cache of the results of RPC calls which are
+// known to be immutable, such as the blocks, receipts and logs of old blocks.
+// Methods opt into caching per call by reporting the block their result depends
+// on with CacheResponse. Cached results are dropped if the block is reorged,
+// which has to be reported to the cache with Invalidate.
+//
+// A response cache can be shared between multiple servers.
+type ResponseCache struct {
+	maxSize int
+
+	lock    sync.Mutex
+	size    int
+	entries lru.BasicLRU[string, *cacheEntry]
+	methods map[string]struct{} // Methods which cached results at least once
+	epoch   uint64              // Number of invalidations, to discard results racing with them
+}
+
+// cacheEntry is a cached result along with the block it depends on.
+type cacheEntry struct {
+	result json.RawMessage
+	number uint64
+}
+
+// NewResponseCache creates a response cache holding at most maxSize bytes of
+// results.
+func NewResponseCache(maxSize int) *ResponseCache {
+	return &ResponseCache{
+		maxSize: maxSize,
+		entries: lru.NewBasicLRU[string, *cacheEntry](math.MaxInt),
+		methods: make(map[string]struct{}),
+	}
+}
+
+// cacheable reports whether the given method ever marked its result as
+// immutable. The cache isn't consulted for other methods, avoiding the cost of
+// deriving the cache key. The current epoch is returned too, it needs to be
+// passed to add.
+func (c *ResponseCache) cacheable(method string) (bool, uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	_, ok := c.methods[method]
+	return ok, c.epoch
+}
+
+// get retrieves the cached result of a call.
+func (c *ResponseCache) get(key string) (json.RawMessage, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry, ok := c.entries.Get(key)
+	if !ok {
+		return nil, false
+	}
+	cacheHitMeter.Mark(1)
+	return entry.result, true
+}
+
+// add inserts the result of a call which missed the cache, evicting the least
+// recently used entries if the cache exceeds its size limit. The result is
+// discarded if the cache was invalidated since the given epoch, as it might
+// have been produced from reorged blocks.
+func (c *ResponseCache) add(method string, key string, result json.RawMessage, number uint64, epoch uint64) {
+	cacheMissMeter.Mark(1)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.methods[method] = struct{}{}
+	size := len(key) + len(result)
+	if epoch != c.epoch || size > c.maxSize {
+		return
+	}
+	if old, ok := c.entries.Peek(key); ok {
+		c.size -= len(key) + len(old.result)
+	}
+	c.entries.Add(key, &cacheEntry{result: result, number: number})
+	c.size += size
+
+	for c.size > c.maxSize {
+		key, entry, _ := c.entries.RemoveOldest()
+		c.size -= len(key) + len(entry.result)
+		cacheEvictMeter.Mark(1)
+	}
+	cacheSizeGauge.Update(int64(c.size))
+}
+
+// Invalidate drops all cached results which depend on the given block or any
+// block after it. It needs to be called whenever blocks are reorged.
+func (c *ResponseCache) Invalidate(number uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.epoch++
+	for _, key := range c.entries.Keys() {
+		if entry, _ := c.entries.Peek(key); entry.number >= number {
+			c.entries.Remove(key)
+			c.size -= len(key) + len(entry.result)
+		}
+	}
+	cacheSizeGauge.Update(int64(c.size))
+}
+
+// cacheKey returns the key of a call in the cache. The key is derived from the
+// decoded arguments, so that equivalent encodings of the parameters share the
+// same cache entry.
+func cacheKey(method string, args []reflect.Value) (string, bool) {
+	var key strings.Builder
+	key.WriteString(method)
+	for _, arg := range args {
+		enc, err := json.Marshal(arg.Interface())
+		if err != nil {
+			return "", false
+		}
+		key.WriteByte(0)
+		key.Write(enc)
+	}
+	return key.String(), true
+}
+
+// cacheHintKey is the context key of the cacheHint of a call.
+type cacheHintKey struct{}
+
+// cacheHint is set by the called method if its result may be cached.
+type cacheHint struct {
+	cacheable bool
+	number    uint64
+}
+
+// CacheResponse marks the result of the RPC call associated with the given
+// context as immutable as long as the given block and its ancestors are not
+// reorged, allowing the server to cache it. It's a noop if response caching
+// is disabled.
+func CacheResponse(ctx context.Context, number uint64) {
+	if hint, ok := ctx.Value(cacheHintKey{}).(*cacheHint); ok {
+		hint.cacheable, hint.number = true, number
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// cacheTestService counts the calls served without the response cache.
+type cacheTestService struct {
+	calls int
+}
+
+// Block is cacheable for all blocks up to number 100.
+func (s *cacheTestService) Block(ctx context.Context, number hexutil.Uint64, full *bool) string {
+	s.calls++
+	if number <= 100 {
+		CacheResponse(ctx, uint64(number))
+	}
+	return fmt.Sprintf("block %d", number)
+}
+
+// Large returns a result which doesn't fit into the cache.
+func (s *cacheTestService) Large(ctx context.Context) string {
+	s.calls++
+	CacheResponse(ctx, 0)
+	return strings.Repeat("x", 1024)
+}
+
+func TestResponseCache(t *testing.T) {
+	var (
+		server  = NewServer()
+		service = new(cacheTestService)
+		cache   = NewResponseCache(256)
+	)
+	if err := server.RegisterName("cache", service); err != nil {
+		t.Fatal(err)
+	}
+	server.SetResponseCache(cache)
+	client := DialInProc(server)
+	defer client.Close()
+
+	call := func(wantCalls int, method string, args ...interface{}) {
+		t.Helper()
+		var result string
+		if err := client.Call(&result, method, args...); err != nil {
+			t.Fatalf("%s%v failed: %v", method, args, err)
+		}
+		if service.calls != wantCalls {
+			t.Fatalf("%s%v: served %d calls, want %d", method, args, service.calls, wantCalls)
+		}
+	}
+	// Immutable results are cached, regardless of the parameter encoding
+	call(1, "cache_block", "0x10")
+	call(1, "cache_block", "0x10")
+	call(1, "cache_block", "0x10", nil)
+	call(2, "cache_block", "0x20")
+
+	// Results not marked by the method are never cached
+	call(3, "cache_block", "0x1000")
+	call(4, "cache_block", "0x1000")
+
+	// Results exceeding the cache size are not cached
+	call(5, "cache_large")
+	call(6, "cache_large")
+
+	// Invalidation drops the results of the reorged blocks only
+	cache.Invalidate(0x20)
+	call(6, "cache_block", "0x10")
+	call(7, "cache_block", "0x20")
+
+	// Results added concurrently with an invalidation are discarded
+	ok, epoch := cache.cacheable("cache_block")
+	if !ok {
+		t.Fatal("method not cacheable")
+	}
+	cache.Invalidate(0x30)
+	cache.add("cache_block", "stale", []byte(`"stale"`), 0x30, epoch)
+	if _, ok := cache.get("stale"); ok {
+		t.Fatal("stale result cached")
+	}
+	// The least recently used results are evicted when the cache is full
+	for i := 0; i < 100; i++ {
+		call(8+i, "cache_block", hexutil.EncodeUint64(uint64(i)))
+	}
+	if cache.size > cache.maxSize {
+		t.Fatalf("cache size %d exceeds limit %d", cache.size, cache.maxSize)
+	}
+	call(107, "cache_block", "0x63")
+	call(108, "cache_block", "0x0")
+}
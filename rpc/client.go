@@ -88,6 +88,9 @@ type Client struct {
 	// config fields
 	batchItemLimit       int
 	batchResponseMaxSize int
+	methodPolicy         *MethodPolicy
+	rateLimiter          *RateLimiter
+	responseCache        *ResponseCache
 
 	// writeConn is used for writing to the connection on the caller's goroutine. It should
 	// only be accessed outside of dispatch, with the write lock held. The write lock is
@@ -120,6 +123,9 @@ func (c *Client) newClientConn(conn ServerCodec) *clientConn {
 	ctx = context.WithValue(ctx, clientContextKey{}, c)
 	ctx = context.WithValue(ctx, peerInfoContextKey{}, conn.peerInfo())
 	handler := newHandler(ctx, conn, c.idgen, c.services, c.batchItemLimit, c.batchResponseMaxSize)
+	handler.methodPolicy = c.methodPolicy
+	handler.rateLimiter = c.rateLimiter
+	handler.responseCache = c.responseCache
 	return &clientConn{conn, handler}
 }
 
@@ -247,6 +253,9 @@ func initClient(conn ServerCodec, services *serviceRegistry, cfg *clientConfig)
 		idgen:                cfg.idgen,
 		batchItemLimit:       cfg.batchItemLimit,
 		batchResponseMaxSize: cfg.batchResponseLimit,
+		methodPolicy:         cfg.methodPolicy,
+		rateLimiter:          cfg.rateLimiter,
+		responseCache:        cfg.responseCache,
 		writeConn:            conn,
 		close:                make(chan struct{}),
 		closing:              make(chan struct{}),
@@ -538,6 +547,37 @@ func (c *Client) Subscribe(ctx context.Context, namespace string, channel interf
 	return op.sub, nil
 }
 
+// SubscribeResumable is like Subscribe, but the subscription survives the loss of
+// the connection. The client reconnects and re-establishes the subscription with
+// the arguments returned by resume, which receives the original arguments and the
+// last notification delivered on the subscription. This allows the server to
+// replay the notifications missed while the connection was down.
+//
+// The subscription Err channel only receives an error if the subscription can't
+// be re-established, e.g. because the server rejected it or the client is closed.
+func (c *Client) SubscribeResumable(ctx context.Context, namespace string, channel interface{}, resume ResumeFunc, args ...interface{}) (*ClientSubscription, error) {
+	// Check type of channel first.
+	chanVal := reflect.ValueOf(channel)
+	if chanVal.Kind() != reflect.Chan || chanVal.Type().ChanDir()&reflect.SendDir == 0 {
+		panic(fmt.Sprintf("channel argument of SubscribeResumable has type %T, need writable channel", channel))
+	}
+	if chanVal.IsNil() {
+		panic("channel given to SubscribeResumable must not be nil")
+	}
+	r := newResumer(c, namespace, resume, args)
+	inner, err := c.Subscribe(ctx, namespace, r.in, args...)
+	if err != nil {
+		return nil, err
+	}
+	sub := newClientSubscription(c, namespace, chanVal)
+	sub.subid, sub.resumer = inner.subid, r
+	r.sub = sub
+
+	go sub.run()
+	go r.loop(inner)
+	return sub, nil
+}
+
 // SupportsSubscriptions reports whether subscriptions are supported by the client
 // transport. When this returns false, Subscribe and related methods will return
 // ErrNotificationsUnsupported.
@@ -41,6 +41,9 @@ type clientConfig struct {
 	idgen              func() ID
 	batchItemLimit     int
 	batchResponseLimit int
+	methodPolicy       *MethodPolicy
+	rateLimiter        *RateLimiter
+	responseCache      *ResponseCache
 }
 
 func (cfg *clientConfig) initHeaders() {
@@ -806,6 +806,142 @@ func TestClientReconnect(t *testing.T) {
 	}
 }
 
+func TestClientSubscribeResumable(t *testing.T) {
+	startServer := func(addr string) (*Server, net.Listener) {
+		srv := newTestServer()
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			t.Fatal("can't listen:", err)
+		}
+		go http.Serve(l, srv.WebsocketHandler([]string{"*"}))
+		return srv, l
+	}
+	s1, l1 := startServer("127.0.0.1:0")
+	client, err := Dial("ws://" + l1.Addr().String())
+	if err != nil {
+		t.Fatal("can't dial", err)
+	}
+	defer client.Close()
+
+	// Resume the subscription a bit before the last received value, skipping the
+	// values delivered already
+	var resumes []json.RawMessage
+	resume := func(args []interface{}, last json.RawMessage) ([]interface{}, SkipFunc, error) {
+		resumes = append(resumes, last)
+		var val int
+		if err := json.Unmarshal(last, &val); err != nil {
+			return nil, nil, err
+		}
+		skip := func(raw json.RawMessage) bool {
+			var v int
+			return json.Unmarshal(raw, &v) == nil && v <= val
+		}
+		return []interface{}{args[0], 7, val - 1}, skip, nil
+	}
+	var (
+		nc      = make(chan int)
+		sub     *ClientSubscription
+		timeout = time.After(10 * time.Second)
+		receive = func(from, to int) {
+			for want := from; want <= to; want++ {
+				select {
+				case have := <-nc:
+					if have != want {
+						t.Fatalf("wrong notification: have %d, want %d", have, want)
+					}
+				case err := <-sub.Err():
+					t.Fatalf("subscription failed: %v", err)
+				case <-timeout:
+					t.Fatalf("notification %d not received", want)
+				}
+			}
+		}
+	)
+	sub, err = client.SubscribeResumable(context.Background(), "nftest", nc, resume, "someSubscription", 5, 0)
+	if err != nil {
+		t.Fatalf("can't subscribe: %v", err)
+	}
+	receive(0, 4)
+
+	// Restart the server, the subscription should be resumed
+	l1.Close()
+	s1.Stop()
+	s2, l2 := startServer(l1.Addr().String())
+	defer l2.Close()
+	defer s2.Stop()
+	receive(5, 9)
+
+	if len(resumes) != 1 || string(resumes[0]) != "4" {
+		t.Errorf("wrong resume calls: %s", resumes)
+	}
+	sub.Unsubscribe()
+	if err, ok := <-sub.Err(); ok {
+		t.Errorf("unexpected subscription error: %v", err)
+	}
+}
+
+// This test checks that a subscription closed by the server with an error fails on
+// the client, and that resumable subscriptions are re-established.
+func TestClientSubscribeServerClose(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer()
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	nc := make(chan int)
+	sub, err := client.Subscribe(context.Background(), "nftest", nc, "failingSubscription", 0, 0)
+	if err != nil {
+		t.Fatalf("can't subscribe: %v", err)
+	}
+	select {
+	case err := <-sub.Err():
+		if err == nil || err.Error() != "subscription failed" {
+			t.Fatalf("wrong subscription error: %v", err)
+		}
+	case v := <-nc:
+		t.Fatalf("unexpected notification %d", v)
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not closed by the server")
+	}
+
+	// Resume the failed subscription with one that keeps going
+	var resumes int
+	resume := func(args []interface{}, last json.RawMessage) ([]interface{}, SkipFunc, error) {
+		resumes++
+		val := -1
+		if last != nil {
+			if err := json.Unmarshal(last, &val); err != nil {
+				return nil, nil, err
+			}
+		}
+		return []interface{}{"someSubscription", 5, val + 1}, nil, nil
+	}
+	sub, err = client.SubscribeResumable(context.Background(), "nftest", nc, resume, "failingSubscription", 3, 0)
+	if err != nil {
+		t.Fatalf("can't subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	timeout := time.After(10 * time.Second)
+	for want := 0; want < 5; want++ {
+		select {
+		case have := <-nc:
+			if have != want {
+				t.Fatalf("wrong notification: have %d, want %d", have, want)
+			}
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-timeout:
+			t.Fatalf("notification %d not received", want)
+		}
+	}
+	if resumes != 1 {
+		t.Errorf("wrong number of resumes: have %d, want 1", resumes)
+	}
+}
+
 func httpTestClient(srv *Server, transport string, fl *flakeyListener) (*Client, *httptest.Server) {
 	// Create the HTTP server.
 	var hs *httptest.Server
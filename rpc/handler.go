@@ -25,9 +25,15 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/internal/tracing"
 	"github.com/ethereum/go-ethereum/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// spanTracer creates the spans of the served RPC calls.
+var spanTracer = tracing.Tracer("github.com/ethereum/go-ethereum/rpc")
+
 // handler handles JSON-RPC messages. There is one handler per connection. Note that
 // handler is not safe for concurrent use. Message handling never blocks indefinitely
 // because RPCs are processed on background goroutines launched by handler.
@@ -62,6 +68,9 @@ type handler struct {
 	allowSubscribe       bool
 	batchRequestLimit    int
 	batchResponseMaxSize int
+	methodPolicy         *MethodPolicy  // restricts the callable methods, nil if unrestricted
+	rateLimiter          *RateLimiter   // limits the calls of the remote end, nil if not limited
+	responseCache        *ResponseCache // caches immutable results, nil if disabled
 
 	subLock    sync.Mutex
 	serverSubs map[ID]*Subscription
@@ -91,6 +100,9 @@ func newHandler(connCtx context.Context, conn jsonWriter, idgen func() ID, reg *
 	if conn.remoteAddr() != "" {
 		h.log = h.log.New("conn", conn.remoteAddr())
 	}
+	if auth := PeerInfoFromContext(connCtx).Auth; auth != nil {
+		h.log = h.log.New("sub", auth.Subject)
+	}
 	h.unsubscribeCb = newCallback(reflect.Value{}, reflect.ValueOf(h.unsubscribe))
 	return h
 }
@@ -365,6 +377,18 @@ func (h *handler) addSubscriptions(nn []*Notifier) {
 	}
 }
 
+// removeServerSubscription removes a subscription ended by the server and closes
+// its error channel.
+func (h *handler) removeServerSubscription(id ID) {
+	h.subLock.Lock()
+	defer h.subLock.Unlock()
+
+	if s := h.serverSubs[id]; s != nil {
+		close(s.err)
+		delete(h.serverSubs, id)
+	}
+}
+
 // cancelServerSubscriptions removes all subscriptions and closes their error channels.
 func (h *handler) cancelServerSubscriptions(err error) {
 	h.subLock.Lock()
@@ -452,8 +476,14 @@ func (h *handler) handleSubscriptionResult(msg *jsonrpcMessage) {
 		h.log.Debug("Dropping invalid subscription message")
 		return
 	}
-	if h.clientSubs[result.ID] != nil {
-		h.clientSubs[result.ID].deliver(result.Result)
+	if sub := h.clientSubs[result.ID]; sub != nil {
+		// An error notification means the server has ended the subscription.
+		if result.Error != nil {
+			delete(h.clientSubs, result.ID)
+			sub.close(result.Error)
+			return
+		}
+		sub.deliver(result.Result)
 	}
 }
 
@@ -489,8 +519,45 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage) *jsonrpcMess
 	}
 }
 
-// handleCall processes method calls.
+// handleCall processes method calls, tracing them in a span.
 func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
+	ctx, span := spanTracer.Start(cp.ctx, msg.Method,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "jsonrpc"),
+			attribute.String("rpc.method", msg.Method),
+		),
+	)
+	call := &callProc{ctx: ctx}
+	answer := h.serveCall(call, msg)
+	cp.notifiers = append(cp.notifiers, call.notifiers...)
+
+	var err error
+	if answer.Error != nil {
+		span.SetAttributes(attribute.Int("rpc.jsonrpc.error_code", answer.Error.Code))
+		err = answer.Error
+	}
+	tracing.End(span, err)
+	return answer
+}
+
+// serveCall processes method calls.
+func (h *handler) serveCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage {
+	if !msg.isUnsubscribe() && !h.methodPolicy.Allowed(msg.Method) {
+		return msg.errorResponse(&methodNotAllowedError{method: msg.Method})
+	}
+	auth := PeerInfoFromContext(cp.ctx).Auth
+	if auth != nil && !msg.isUnsubscribe() {
+		if err := auth.authorize(msg.Method); err != nil {
+			countAuthCall(auth, false)
+			return msg.errorResponse(err)
+		}
+	}
+	if h.rateLimiter != nil && !msg.isUnsubscribe() {
+		if err := h.rateLimiter.allow(cp.ctx, msg.Method); err != nil {
+			return msg.errorResponse(err)
+		}
+	}
 	if msg.isSubscribe() {
 		return h.handleSubscribe(cp, msg)
 	}
@@ -509,7 +576,12 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 		return msg.errorResponse(&invalidParamsError{err.Error()})
 	}
 	start := time.Now()
-	answer := h.runMethod(cp.ctx, msg, callb, args)
+	var answer *jsonrpcMessage
+	if h.responseCache != nil && callb != h.unsubscribeCb {
+		answer = h.runCachedMethod(cp.ctx, msg, callb, args)
+	} else {
+		answer = h.runMethod(cp.ctx, msg, callb, args)
+	}
 
 	// Collect the statistics for RPC calls if metrics is enabled.
 	// We only care about pure rpc call. Filter out subscription.
@@ -522,6 +594,9 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 		}
 		rpcServingTimer.UpdateSince(start)
 		updateServeTimeHistogram(msg.Method, answer.Error == nil, time.Since(start))
+		if auth != nil {
+			countAuthCall(auth, answer.Error == nil)
+		}
 	}
 
 	return answer
@@ -569,6 +644,35 @@ func (h *handler) runMethod(ctx context.Context, msg *jsonrpcMessage, callb *cal
 	return msg.response(result)
 }
 
+// runCachedMethod runs the given method, serving the result from the response
+// cache if it's known already. Results marked as immutable by the method are
+// added to the cache.
+func (h *handler) runCachedMethod(ctx context.Context, msg *jsonrpcMessage, callb *callback, args []reflect.Value) *jsonrpcMessage {
+	var key string
+	cacheable, epoch := h.responseCache.cacheable(msg.Method)
+	if cacheable {
+		var ok bool
+		if key, ok = cacheKey(msg.Method, args); ok {
+			if result, ok := h.responseCache.get(key); ok {
+				return &jsonrpcMessage{Version: vsn, ID: msg.ID, Result: result}
+			}
+		}
+	}
+	hint := new(cacheHint)
+	answer := h.runMethod(context.WithValue(ctx, cacheHintKey{}, hint), msg, callb, args)
+	if answer.Error != nil || !hint.cacheable {
+		return answer
+	}
+	if key == "" {
+		var ok bool
+		if key, ok = cacheKey(msg.Method, args); !ok {
+			return answer
+		}
+	}
+	h.responseCache.add(msg.Method, key, answer.Result, hint.number, epoch)
+	return answer
+}
+
 // unsubscribe is the callback function for all *_unsubscribe calls.
 func (h *handler) unsubscribe(ctx context.Context, id ID) (bool, error) {
 	h.subLock.Lock()
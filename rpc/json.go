@@ -44,11 +44,13 @@ var null = json.RawMessage("null")
 type subscriptionResult struct {
 	ID     string          `json:"subscription"`
 	Result json.RawMessage `json:"result,omitempty"`
+	Error  *jsonError      `json:"error,omitempty"`
 }
 
 type subscriptionResultEnc struct {
-	ID     string `json:"subscription"`
-	Result any    `json:"result"`
+	ID     string     `json:"subscription"`
+	Result any        `json:"result"`
+	Error  *jsonError `json:"error,omitempty"`
 }
 
 type jsonrpcSubscriptionNotification struct {
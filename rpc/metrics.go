@@ -32,6 +32,10 @@ var (
 	serveTimeHistName = "rpc/duration"
 
 	rpcServingTimer = metrics.NewRegisteredTimer("rpc/duration/all", nil)
+
+	// rpcLatencyTimer tracks the serving time of the calls labelled by method
+	// and outcome, for exposition as a single Prometheus metric family.
+	rpcLatencyTimer = metrics.NewRegisteredTimerVec("rpc/latency", nil, "method", "status")
 )
 
 // updateServeTimeHistogram tracks the serving time of a remote RPC call.
@@ -47,4 +51,5 @@ func updateServeTimeHistogram(method string, success bool, elapsed time.Duration
 		)
 	}
 	metrics.GetOrRegisterHistogramLazy(h, nil, sampler).Update(elapsed.Nanoseconds())
+	rpcLatencyTimer.With(method, note).Update(elapsed)
 }
@@ -0,0 +1,97 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// MethodPolicy restricts the methods callable on a server on top of the
+// registered namespaces.
+//
+// Rules match methods by their full name (e.g. "debug_traceTransaction"), by a
+// name prefix ending with an asterisk (e.g. "debug_trace*", or "*" matching all
+// methods) or by their namespace (e.g. "debug"). If a method matches both allow
+// and deny rules, the most specific rule wins: exact names over prefixes, longer
+// prefixes over shorter ones, and prefixes over namespaces. Deny rules win ties.
+// Methods not matching any rule are allowed, unless allow rules are configured.
+//
+// The methods of the rpc namespace are always allowed.
+type MethodPolicy struct {
+	Allow []string `toml:",omitempty"`
+	Deny  []string `toml:",omitempty"`
+}
+
+// Validate checks the rules of the policy for syntax errors.
+func (p *MethodPolicy) Validate() error {
+	for _, rules := range [][]string{p.Allow, p.Deny} {
+		for _, rule := range rules {
+			if rule == "" || strings.Contains(strings.TrimSuffix(rule, "*"), "*") {
+				return fmt.Errorf("invalid method rule %q", rule)
+			}
+		}
+	}
+	return nil
+}
+
+// Allowed reports whether the policy allows calling the given method. A nil
+// policy allows all methods.
+func (p *MethodPolicy) Allowed(method string) bool {
+	if p == nil {
+		return true
+	}
+	if namespace, _, _ := strings.Cut(method, serviceMethodSeparator); namespace == MetadataApi {
+		return true
+	}
+	allow, deny := matchRules(p.Allow, method), matchRules(p.Deny, method)
+	if allow < 0 && deny < 0 {
+		return len(p.Allow) == 0
+	}
+	return allow > deny
+}
+
+// matchRules returns the specificity of the most specific rule matching the
+// method, or -1 if none of them matches.
+func matchRules(rules []string, method string) int {
+	best := -1
+	for _, rule := range rules {
+		if rank := matchRule(rule, method); rank > best {
+			best = rank
+		}
+	}
+	return best
+}
+
+// matchRule returns the specificity of a rule matching the given method, or -1
+// if the rule doesn't match it.
+func matchRule(rule string, method string) int {
+	if rule == method {
+		return math.MaxInt
+	}
+	if prefix, ok := strings.CutSuffix(rule, "*"); ok {
+		if strings.HasPrefix(method, prefix) {
+			return 1 + len(prefix)
+		}
+		return -1
+	}
+	if namespace, _, ok := strings.Cut(method, serviceMethodSeparator); ok && namespace == rule {
+		return 0
+	}
+	return -1
+}
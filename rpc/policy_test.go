@@ -0,0 +1,110 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMethodPolicy(t *testing.T) {
+	tests := []struct {
+		policy *MethodPolicy
+		method string
+		want   bool
+	}{
+		{nil, "debug_setHead", true},
+		{&MethodPolicy{}, "debug_setHead", true},
+
+		// Deny rules only
+		{&MethodPolicy{Deny: []string{"debug_setHead"}}, "debug_setHead", false},
+		{&MethodPolicy{Deny: []string{"debug_setHead"}}, "debug_traceTransaction", true},
+		{&MethodPolicy{Deny: []string{"debug"}}, "debug_traceTransaction", false},
+		{&MethodPolicy{Deny: []string{"debug_set*"}}, "debug_setHead", false},
+		{&MethodPolicy{Deny: []string{"debug_set*"}}, "eth_setHead", true},
+
+		// Allow rules only
+		{&MethodPolicy{Allow: []string{"eth", "debug_trace*"}}, "debug_traceTransaction", true},
+		{&MethodPolicy{Allow: []string{"eth", "debug_trace*"}}, "debug_setHead", false},
+		{&MethodPolicy{Allow: []string{"eth", "debug_trace*"}}, "eth_call", true},
+		{&MethodPolicy{Allow: []string{"eth"}}, "ethx_call", false},
+
+		// The most specific rule wins
+		{&MethodPolicy{Allow: []string{"debug_traceTransaction"}, Deny: []string{"debug"}}, "debug_traceTransaction", true},
+		{&MethodPolicy{Allow: []string{"debug_traceTransaction"}, Deny: []string{"debug"}}, "debug_traceCall", false},
+		{&MethodPolicy{Allow: []string{"debug"}, Deny: []string{"debug_setHead"}}, "debug_setHead", false},
+		{&MethodPolicy{Allow: []string{"debug_trace*"}, Deny: []string{"*"}}, "debug_traceCall", true},
+		{&MethodPolicy{Allow: []string{"debug_trace*"}, Deny: []string{"debug_traceBad*"}}, "debug_traceBadBlock", false},
+		{&MethodPolicy{Allow: []string{"debug_setHead"}, Deny: []string{"debug_setHead"}}, "debug_setHead", false},
+
+		// The metadata namespace is always allowed
+		{&MethodPolicy{Deny: []string{"*"}}, "rpc_modules", true},
+	}
+	for i, test := range tests {
+		if have := test.policy.Allowed(test.method); have != test.want {
+			t.Errorf("test %d: %s allowed %v, want %v", i, test.method, have, test.want)
+		}
+	}
+	if err := (&MethodPolicy{Deny: []string{"debug_*trace"}}).Validate(); err == nil {
+		t.Error("invalid rule accepted")
+	}
+}
+
+func TestServerMethodPolicy(t *testing.T) {
+	server := newTestServer()
+	server.SetMethodPolicy(&MethodPolicy{Deny: []string{"test_echo*", "nftest"}})
+	defer server.Stop()
+
+	client := DialInProc(server)
+	defer client.Close()
+
+	var rpcErr Error
+	err := client.Call(nil, "test_echo", "x", 1)
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != -32601 {
+		t.Fatalf("expected method not allowed error, got %v", err)
+	}
+	if err := client.Call(nil, "test_noArgsRets"); err != nil {
+		t.Fatalf("allowed method failed: %v", err)
+	}
+	// Disallowed methods and modules are hidden from the module list
+	modules, err := client.SupportedModules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := modules["nftest"]; ok {
+		t.Error("denied module listed")
+	}
+	if _, ok := modules["test"]; !ok {
+		t.Error("allowed module not listed")
+	}
+	var infos map[string]ModuleInfo
+	if err := client.Call(&infos, "rpc_modules", true); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"rpc_modules"}; !reflect.DeepEqual(infos["rpc"].Methods, want) {
+		t.Errorf("rpc methods mismatch: have %v, want %v", infos["rpc"].Methods, want)
+	}
+	for _, method := range infos["test"].Methods {
+		if method == "test_echo" || method == "test_echoWithCtx" {
+			t.Errorf("denied method %s listed", method)
+		}
+	}
+	if len(infos["test"].Methods) == 0 {
+		t.Error("allowed methods not listed")
+	}
+}
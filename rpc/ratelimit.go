@@ -0,0 +1,332 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const (
+	// rateLimitPruneInterval is the interval at which the buckets of idle
+	// clients are dropped.
+	rateLimitPruneInterval = time.Minute
+
+	// anonymousClient is the name of the clients without API key in metrics.
+	anonymousClient = "anonymous"
+)
+
+// RateLimit is the configuration of a token bucket. The bucket holds at most
+// Burst tokens and is refilled with Rate tokens per second. A zero rate means
+// unlimited.
+type RateLimit struct {
+	Rate  float64 `toml:",omitempty"`
+	Burst float64 `toml:",omitempty"` // Defaults to Rate if unset
+}
+
+// burst returns the capacity of the bucket.
+func (l RateLimit) burst() float64 {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Rate
+}
+
+// APIKey is the configuration of the clients identifying themselves with an
+// API key.
+type APIKey struct {
+	Name    string               // Name of the key in metrics, the key itself is never exported
+	Limit   RateLimit            // Limit replacing the default client limit
+	Methods map[string]RateLimit `toml:",omitempty"` // Limits replacing the default method limits
+}
+
+// RateTier is the configuration of the clients granted a rate limit tier by
+// their authentication, see AuthInfo.
+type RateTier struct {
+	Limit   RateLimit            // Limit replacing the default client limit
+	Methods map[string]RateLimit `toml:",omitempty"` // Limits replacing the default method limits
+}
+
+// RateLimitConfig configures the per-client rate limits of an RPC server. Every
+// call consumes tokens from the bucket of the calling client, and from the
+// bucket of the method if the method is limited separately. Calls exceeding
+// the limits are rejected.
+//
+// Methods are matched by their full name (e.g. "eth_getLogs"), by a name
+// prefix ending with an asterisk (e.g. "debug_trace*") or by their namespace
+// (e.g. "debug"), in this order of precedence.
+//
+// Authenticated clients are identified by their subject and limited according
+// to their tier, falling back to the default limits for unknown tiers. Other
+// clients are identified by the API key sent in the KeyHeader of their HTTP or
+// WebSocket requests. Clients without a configured key are identified by their
+// IP address.
+type RateLimitConfig struct {
+	Limit     RateLimit            // Limit of every client
+	Methods   map[string]RateLimit `toml:",omitempty"` // Additional limits of methods or namespaces
+	Weights   map[string]float64   `toml:",omitempty"` // Tokens consumed by a call, defaults to 1
+	KeyHeader string               `toml:",omitempty"` // HTTP header carrying the API key
+	Keys      map[string]APIKey    `toml:",omitempty"` // API keys with their own limits
+	Tiers     map[string]RateTier  `toml:",omitempty"` // Limits of authenticated clients by tier
+}
+
+// RateLimiter enforces a RateLimitConfig. A rate limiter can be shared between
+// multiple servers, limiting the clients across all of them.
+type RateLimiter struct {
+	config RateLimitConfig
+	clock  mclock.Clock
+
+	lock    sync.Mutex
+	clients map[string]*rateLimitClient
+	pruned  mclock.AbsTime
+}
+
+// rateLimitClient is the rate limiting state of a single client.
+type rateLimitClient struct {
+	name    string               // Name of the client in metrics
+	limit   RateLimit            // Limit of all calls
+	methods map[string]RateLimit // Limits of methods
+
+	bucket  tokenBucket
+	buckets map[string]*tokenBucket // Buckets of the limited methods
+}
+
+// tokenBucket is a token bucket which is refilled lazily.
+type tokenBucket struct {
+	tokens  float64
+	updated mclock.AbsTime
+}
+
+// refill adds the tokens accumulated since the last update to the bucket.
+func (b *tokenBucket) refill(limit RateLimit, now mclock.AbsTime) {
+	b.tokens += limit.Rate * time.Duration(now-b.updated).Seconds()
+	if burst := limit.burst(); b.tokens > burst {
+		b.tokens = burst
+	}
+	b.updated = now
+}
+
+// full reports whether the bucket is refilled completely at the given time.
+func (b *tokenBucket) full(limit RateLimit, now mclock.AbsTime) bool {
+	return b.tokens+limit.Rate*time.Duration(now-b.updated).Seconds() >= limit.burst()
+}
+
+// NewRateLimiter creates a rate limiter enforcing the given configuration.
+func NewRateLimiter(config RateLimitConfig) (*RateLimiter, error) {
+	for name, weight := range config.Weights {
+		if weight < 0 {
+			return nil, fmt.Errorf("invalid weight %v of method %q", weight, name)
+		}
+	}
+	for name, key := range config.Keys {
+		if key.Name == "" {
+			return nil, fmt.Errorf("missing name of API key %q", name)
+		}
+	}
+	if len(config.Keys) > 0 && config.KeyHeader == "" {
+		return nil, fmt.Errorf("missing API key header")
+	}
+	return &RateLimiter{
+		config:  config,
+		clock:   mclock.System{},
+		clients: make(map[string]*rateLimitClient),
+	}, nil
+}
+
+// rateLimitError is returned for calls exceeding the rate limits.
+type rateLimitError struct{ method string }
+
+func (e *rateLimitError) ErrorCode() int { return errcodeLimitExceeded }
+
+func (e *rateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded for %s", e.method)
+}
+
+// allow consumes the tokens of a call to the given method, returning an error if
+// the client exceeded its limits.
+func (l *RateLimiter) allow(ctx context.Context, method string) error {
+	var (
+		info   = PeerInfoFromContext(ctx)
+		weight = 1.0
+	)
+	if w, ok := matchMethod(l.config.Weights, method); ok {
+		weight = w
+	}
+	l.lock.Lock()
+	now := l.clock.Now()
+	if time.Duration(now-l.pruned) > rateLimitPruneInterval {
+		l.prune(now)
+	}
+	client := l.client(info, now)
+	allowed := client.take(method, weight, now)
+	name := client.name
+	l.lock.Unlock()
+
+	if !allowed {
+		metrics.GetOrRegisterMeter(fmt.Sprintf("rpc/ratelimit/%s/rejected", name), nil).Mark(1)
+		return &rateLimitError{method}
+	}
+	metrics.GetOrRegisterMeter(fmt.Sprintf("rpc/ratelimit/%s/accepted", name), nil).Mark(1)
+	return nil
+}
+
+// client returns the rate limiting state of the client with the given peer
+// info, creating it if not existent yet. The caller must hold the lock.
+func (l *RateLimiter) client(info PeerInfo, now mclock.AbsTime) *rateLimitClient {
+	var (
+		id  string
+		key APIKey
+		ok  bool
+	)
+	if info.Auth != nil {
+		// The tier is part of the identity, so that a new token granting
+		// another tier takes effect immediately.
+		id = "sub:" + info.Auth.Subject + "/" + info.Auth.Tier
+		key = APIKey{Name: info.Auth.Subject, Limit: l.config.Limit}
+		if tier, known := l.config.Tiers[info.Auth.Tier]; known {
+			key.Limit, key.Methods = tier.Limit, tier.Methods
+		}
+		ok = true
+	} else if l.config.KeyHeader != "" && info.HTTP.Header != nil {
+		value := info.HTTP.Header.Get(l.config.KeyHeader)
+		if key, ok = l.config.Keys[value]; ok {
+			id = "key:" + value
+		}
+	}
+	if !ok {
+		id = "ip:" + info.RemoteAddr
+		if host, _, err := net.SplitHostPort(info.RemoteAddr); err == nil {
+			id = "ip:" + host
+		}
+	}
+	if client := l.clients[id]; client != nil {
+		return client
+	}
+	client := &rateLimitClient{
+		name:    anonymousClient,
+		limit:   l.config.Limit,
+		methods: l.config.Methods,
+		buckets: make(map[string]*tokenBucket),
+	}
+	if ok {
+		client.name, client.limit = key.Name, key.Limit
+		if key.Methods != nil {
+			client.methods = key.Methods
+		}
+	}
+	client.bucket = tokenBucket{tokens: client.limit.burst(), updated: now}
+	l.clients[id] = client
+	return client
+}
+
+// prune drops the clients whose buckets are all refilled, they are recreated
+// identically on their next call. The caller must hold the lock.
+func (l *RateLimiter) prune(now mclock.AbsTime) {
+	for id, client := range l.clients {
+		if client.idle(now) {
+			delete(l.clients, id)
+		}
+	}
+	l.pruned = now
+}
+
+// take consumes the given number of tokens for a call to the method, reporting
+// whether the call is allowed. No tokens are consumed if it's rejected.
+func (c *rateLimitClient) take(method string, weight float64, now mclock.AbsTime) bool {
+	var (
+		limited = c.limit.Rate > 0
+		rule    string
+		limit   RateLimit
+		bucket  *tokenBucket
+	)
+	if limited {
+		c.bucket.refill(c.limit, now)
+		if c.bucket.tokens < weight {
+			return false
+		}
+	}
+	if name, ok := matchMethodName(c.methods, method); ok && c.methods[name].Rate > 0 {
+		rule, limit = name, c.methods[name]
+		if bucket = c.buckets[rule]; bucket == nil {
+			bucket = &tokenBucket{tokens: limit.burst(), updated: now}
+			c.buckets[rule] = bucket
+		}
+		bucket.refill(limit, now)
+		if bucket.tokens < weight {
+			return false
+		}
+		bucket.tokens -= weight
+	}
+	if limited {
+		c.bucket.tokens -= weight
+	}
+	return true
+}
+
+// idle reports whether all buckets of the client are refilled.
+func (c *rateLimitClient) idle(now mclock.AbsTime) bool {
+	if c.limit.Rate > 0 && !c.bucket.full(c.limit, now) {
+		return false
+	}
+	for name, bucket := range c.buckets {
+		if !bucket.full(c.methods[name], now) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchMethod returns the value configured for the given method.
+func matchMethod[T any](values map[string]T, method string) (T, bool) {
+	name, ok := matchMethodName(values, method)
+	return values[name], ok
+}
+
+// matchMethodName returns the configuration key matching the given method. An
+// exact match is preferred over the longest matching prefix, which is preferred
+// over the namespace of the method.
+func matchMethodName[T any](values map[string]T, method string) (string, bool) {
+	if len(values) == 0 {
+		return "", false
+	}
+	if _, ok := values[method]; ok {
+		return method, true
+	}
+	var match string
+	for name := range values {
+		prefix, ok := strings.CutSuffix(name, "*")
+		if ok && strings.HasPrefix(method, prefix) && len(name) > len(match) {
+			match = name
+		}
+	}
+	if match != "" {
+		return match, true
+	}
+	if namespace, _, ok := strings.Cut(method, serviceMethodSeparator); ok {
+		if _, ok := values[namespace]; ok {
+			return namespace, true
+		}
+	}
+	return "", false
+}
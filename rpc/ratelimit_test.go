@@ -0,0 +1,126 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+)
+
+func TestRateLimiter(t *testing.T) {
+	limiter, err := NewRateLimiter(RateLimitConfig{
+		Limit:     RateLimit{Rate: 1, Burst: 10},
+		Methods:   map[string]RateLimit{"debug_trace*": {Rate: 1, Burst: 2}},
+		Weights:   map[string]float64{"eth_getLogs": 5, "debug": 2},
+		KeyHeader: "X-API-Key",
+		Keys:      map[string]APIKey{"secret": {Name: "partner", Limit: RateLimit{Rate: 100, Burst: 100}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := new(mclock.Simulated)
+	limiter.clock = clock
+
+	peer := func(addr string, key string) context.Context {
+		var info PeerInfo
+		info.RemoteAddr = addr
+		info.HTTP.Header = http.Header{}
+		if key != "" {
+			info.HTTP.Header.Set("X-API-Key", key)
+		}
+		return context.WithValue(context.Background(), peerInfoContextKey{}, info)
+	}
+	allow := func(ctx context.Context, method string, want bool) {
+		t.Helper()
+		if err := limiter.allow(ctx, method); (err == nil) != want {
+			t.Fatalf("%s: allowed %v, want %v (err %v)", method, err == nil, want, err)
+		}
+	}
+	var (
+		alice = peer("10.0.0.1:1000", "")
+		bob   = peer("10.0.0.2:1000", "")
+	)
+	// Weighted calls drain the client bucket faster
+	allow(alice, "eth_getLogs", true)
+	allow(alice, "eth_getLogs", true)
+	allow(alice, "eth_getLogs", false)
+	allow(alice, "eth_blockNumber", false)
+
+	// Clients are distinguished by IP address, not by port
+	allow(peer("10.0.0.1:2000", ""), "eth_blockNumber", false)
+	allow(bob, "eth_blockNumber", true)
+
+	// Unknown API keys are treated as anonymous clients
+	allow(peer("10.0.0.1:1000", "wrong"), "eth_blockNumber", false)
+
+	// API keys are limited separately
+	for i := 0; i < 20; i++ {
+		allow(peer("10.0.0.1:1000", "secret"), "eth_getLogs", true)
+	}
+	// Tokens are refilled over time
+	clock.Run(time.Second)
+	allow(alice, "eth_blockNumber", true)
+	allow(alice, "eth_blockNumber", false)
+
+	// Method limits apply on top of the client limit, with the namespace weight
+	clock.Run(10 * time.Second)
+	allow(alice, "debug_traceTransaction", true)
+	allow(alice, "debug_traceTransaction", false)
+	allow(alice, "debug_getBadBlocks", true)
+
+	// Idle clients are pruned
+	clock.Run(time.Hour)
+	allow(bob, "eth_blockNumber", true)
+	if len(limiter.clients) != 1 {
+		t.Fatalf("idle clients not pruned: %d clients", len(limiter.clients))
+	}
+}
+
+func TestRateLimiterHTTP(t *testing.T) {
+	limiter, err := NewRateLimiter(RateLimitConfig{Limit: RateLimit{Rate: 0.001, Burst: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newTestServer()
+	s.SetRateLimiter(limiter)
+	defer s.Stop()
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	c, err := Dial(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for i := 0; i < 2; i++ {
+		if err := c.Call(nil, "test_noArgsRets"); err != nil {
+			t.Fatalf("call %d failed: %v", i, err)
+		}
+	}
+	err = c.Call(nil, "test_noArgsRets")
+	var rpcErr Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != errcodeLimitExceeded {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+}
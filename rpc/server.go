@@ -19,6 +19,8 @@ package rpc
 import (
 	"context"
 	"io"
+	"net/http"
+	"sort"
 	"sync"
 	"sync/atomic"
 
@@ -52,6 +54,9 @@ type Server struct {
 	batchItemLimit     int
 	batchResponseLimit int
 	httpBodyLimit      int
+	methodPolicy       *MethodPolicy
+	rateLimiter        *RateLimiter
+	responseCache      *ResponseCache
 }
 
 // NewServer creates a new server instance with no registered handlers.
@@ -87,6 +92,30 @@ func (s *Server) SetHTTPBodyLimit(limit int) {
 	s.httpBodyLimit = limit
 }
 
+// SetMethodPolicy restricts the methods which clients are allowed to call.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetMethodPolicy(policy *MethodPolicy) {
+	s.methodPolicy = policy
+}
+
+// SetRateLimiter sets the rate limiter applied to the calls of all clients.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetRateLimiter(limiter *RateLimiter) {
+	s.rateLimiter = limiter
+}
+
+// SetResponseCache sets the cache serving the results of the immutable calls.
+//
+// This method should be called before processing any requests via ServeCodec, ServeHTTP,
+// ServeListener etc.
+func (s *Server) SetResponseCache(cache *ResponseCache) {
+	s.responseCache = cache
+}
+
 // RegisterName creates a service for the given receiver type under the given name. When no
 // methods on the given receiver match the criteria to be either a RPC method or a
 // subscription an error is returned. Otherwise a new service is created and added to the
@@ -112,6 +141,9 @@ func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 		idgen:              s.idgen,
 		batchItemLimit:     s.batchItemLimit,
 		batchResponseLimit: s.batchResponseLimit,
+		methodPolicy:       s.methodPolicy,
+		rateLimiter:        s.rateLimiter,
+		responseCache:      s.responseCache,
 	}
 	c := initClient(codec, &s.services, cfg)
 	<-codec.closed()
@@ -147,6 +179,9 @@ func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec) {
 
 	h := newHandler(ctx, codec, s.idgen, &s.services, s.batchItemLimit, s.batchResponseLimit)
 	h.allowSubscribe = false
+	h.methodPolicy = s.methodPolicy
+	h.rateLimiter = s.rateLimiter
+	h.responseCache = s.responseCache
 	defer h.close(io.EOF, nil)
 
 	reqs, batch, err := codec.readBatch()
@@ -185,14 +220,43 @@ type RPCService struct {
 	server *Server
 }
 
-// Modules returns the list of RPC services with their version number
-func (s *RPCService) Modules() map[string]string {
+// ModuleInfo describes an RPC service in the verbose output of rpc_modules.
+type ModuleInfo struct {
+	Version string   `json:"version"`
+	Methods []string `json:"methods"` // Methods allowed by the method policy
+}
+
+// Modules returns the list of RPC services with their version number. Services
+// whose methods are all disallowed by the method policy are omitted. If verbose
+// is set, the methods allowed by the policy are listed for every service too.
+func (s *RPCService) Modules(verbose *bool) interface{} {
 	s.server.services.mu.Lock()
 	defer s.server.services.mu.Unlock()
 
-	modules := make(map[string]string)
-	for name := range s.server.services.services {
+	var (
+		modules = make(map[string]string)
+		infos   = make(map[string]ModuleInfo)
+		policy  = s.server.methodPolicy
+	)
+	for name, svc := range s.server.services.services {
+		var methods []string
+		for callback := range svc.callbacks {
+			if method := name + serviceMethodSeparator + callback; policy.Allowed(method) {
+				methods = append(methods, method)
+			}
+		}
+		if method := name + subscribeMethodSuffix; len(svc.subscriptions) > 0 && policy.Allowed(method) {
+			methods = append(methods, method, name+unsubscribeMethodSuffix)
+		}
+		if len(methods) == 0 {
+			continue
+		}
+		sort.Strings(methods)
 		modules[name] = "1.0"
+		infos[name] = ModuleInfo{Version: "1.0", Methods: methods}
+	}
+	if verbose != nil && *verbose {
+		return infos
 	}
 	return modules
 }
@@ -218,7 +282,13 @@ type PeerInfo struct {
 		UserAgent string
 		Origin    string
 		Host      string
+		// All header values of the request.
+		Header http.Header `json:"-"`
 	}
+
+	// Auth describes the authenticated client, nil if the client isn't
+	// authenticated.
+	Auth *AuthInfo `json:"-"`
 }
 
 type peerInfoContextKey struct{}
@@ -29,6 +29,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/ethereum/go-ethereum/log"
 )
 
 var (
@@ -108,6 +110,13 @@ type Notifier struct {
 	buffer       []any
 	callReturned bool
 	activated    bool
+	closed       bool
+}
+
+// subscriptionError is buffered in place of a notification when the subscription
+// is closed by the server before it was activated.
+type subscriptionError struct {
+	err error
 }
 
 // CreateSubscription returns a new subscription that is coupled to the
@@ -138,6 +147,9 @@ func (n *Notifier) Notify(id ID, data any) error {
 	} else if n.sub.ID != id {
 		panic("Notify with wrong ID")
 	}
+	if n.closed {
+		return ErrSubscriptionNotFound
+	}
 	if n.activated {
 		return n.send(n.sub, data)
 	}
@@ -145,6 +157,38 @@ func (n *Notifier) Notify(id ID, data any) error {
 	return nil
 }
 
+// Close ends the subscription from the server side. The error is delivered to
+// the client as the final notification, failing its subscription so that it
+// can resubscribe if needed. Any further Notify calls are rejected.
+func (n *Notifier) Close(id ID, err error) error {
+	n.mu.Lock()
+	if n.sub == nil {
+		n.mu.Unlock()
+		panic("can't Close before subscription is created")
+	} else if n.sub.ID != id {
+		n.mu.Unlock()
+		panic("Close with wrong ID")
+	}
+	if n.closed {
+		n.mu.Unlock()
+		return nil
+	}
+	n.closed = true
+
+	var sendErr error
+	if n.activated {
+		sendErr = n.send(n.sub, subscriptionError{err})
+	} else {
+		n.buffer = append(n.buffer, subscriptionError{err})
+	}
+	n.mu.Unlock()
+
+	// The handler lock must not be taken while holding n.mu, as the handler
+	// acquires them in the opposite order when registering subscriptions.
+	n.h.removeServerSubscription(id)
+	return sendErr
+}
+
 // Closed returns a channel that is closed when the RPC connection is closed.
 // Deprecated: use subscription error channel
 func (n *Notifier) Closed() <-chan interface{} {
@@ -157,6 +201,9 @@ func (n *Notifier) takeSubscription() *Subscription {
 	n.mu.Lock()
 	defer n.mu.Unlock()
 	n.callReturned = true
+	if n.closed {
+		return nil
+	}
 	return n.sub
 }
 
@@ -181,10 +228,14 @@ func (n *Notifier) send(sub *Subscription, data any) error {
 		Version: vsn,
 		Method:  n.namespace + notificationMethodSuffix,
 		Params: subscriptionResultEnc{
-			ID:     string(sub.ID),
-			Result: data,
+			ID: string(sub.ID),
 		},
 	}
+	if serr, ok := data.(subscriptionError); ok {
+		msg.Params.Error = errorMessage(serr.err).Error
+	} else {
+		msg.Params.Result = data
+	}
 	return n.h.conn.writeJSON(context.Background(), &msg, false)
 }
 
@@ -229,6 +280,10 @@ type ClientSubscription struct {
 	quit        chan error
 	forwardDone chan struct{}
 	unsubDone   chan struct{}
+
+	// resumer re-establishes the subscription on reconnects, it's nil for
+	// subscriptions which end with the connection.
+	resumer *resumer
 }
 
 // This is the sentinel value sent on sub.quit when Unsubscribe is called.
@@ -376,6 +431,148 @@ func (sub *ClientSubscription) unmarshal(result json.RawMessage) (interface{}, e
 }
 
 func (sub *ClientSubscription) requestUnsubscribe() error {
+	if sub.resumer != nil {
+		sub.resumer.stop()
+		return nil
+	}
 	var result interface{}
 	return sub.client.Call(&result, sub.namespace+unsubscribeMethodSuffix, sub.subid)
 }
+
+// ResumeFunc returns the arguments for re-establishing a subscription after the
+// connection was lost, given the original arguments and the last notification
+// received. The last notification is nil if none was received.
+//
+// The optional skip function is applied to the notifications of the resumed
+// subscription, dropping the ones which were already delivered before the
+// connection was lost.
+type ResumeFunc func(args []interface{}, last json.RawMessage) (resumed []interface{}, skip SkipFunc, err error)
+
+// SkipFunc reports whether a notification of a resumed subscription duplicates
+// one delivered before the connection was lost.
+type SkipFunc func(json.RawMessage) bool
+
+const (
+	resubscribeMinBackoff = 500 * time.Millisecond
+	resubscribeMaxBackoff = 30 * time.Second
+)
+
+// resumer maintains the server side subscriptions backing a resumable client
+// subscription. Notifications are forwarded in their raw form, so that the last
+// one can be passed to the resume function.
+type resumer struct {
+	client    *Client
+	namespace string
+	args      []interface{}
+	resume    ResumeFunc
+
+	sub  *ClientSubscription  // resumable subscription delivered to the user
+	in   chan json.RawMessage // notifications of the current server subscription
+	last json.RawMessage      // last notification delivered
+
+	ctx      context.Context // cancelled on stop to abort resubscriptions
+	cancel   context.CancelFunc
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+func newResumer(c *Client, namespace string, resume ResumeFunc, args []interface{}) *resumer {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &resumer{
+		client:    c,
+		namespace: namespace,
+		args:      args,
+		resume:    resume,
+		in:        make(chan json.RawMessage),
+		ctx:       ctx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+}
+
+// stop terminates the resumer and unsubscribes on the server.
+func (r *resumer) stop() {
+	r.stopOnce.Do(r.cancel)
+	<-r.done
+}
+
+// loop forwards the notifications of the server subscriptions, re-establishing
+// them when the connection is lost.
+func (r *resumer) loop(inner *ClientSubscription) {
+	defer close(r.done)
+
+	var skip SkipFunc
+	for {
+		err := r.forward(inner, skip)
+		if r.ctx.Err() != nil {
+			return // stopped by Unsubscribe
+		}
+		if err == nil {
+			r.sub.close(ErrClientQuit) // client closed
+			return
+		}
+		log.Debug("RPC subscription interrupted", "namespace", r.namespace, "id", inner.subid, "err", err)
+		if inner, skip, err = r.resubscribe(err); err != nil {
+			if r.ctx.Err() == nil {
+				r.sub.close(err)
+			}
+			return
+		}
+		log.Debug("RPC subscription resumed", "namespace", r.namespace, "id", inner.subid)
+	}
+}
+
+// forward delivers the notifications of a server subscription until it ends,
+// dropping the ones reported by skip. A nil error is returned if the client was
+// closed or the resumer stopped.
+func (r *resumer) forward(inner *ClientSubscription, skip SkipFunc) error {
+	defer inner.Unsubscribe()
+
+	for {
+		select {
+		case raw := <-r.in:
+			if skip != nil && skip(raw) {
+				continue
+			}
+			if !r.sub.deliver(raw) {
+				return nil
+			}
+			r.last = raw
+		case err := <-inner.Err():
+			return err
+		case <-r.ctx.Done():
+			return nil
+		}
+	}
+}
+
+// resubscribe re-establishes the server subscription, retrying with exponential
+// backoff until the connection is restored. The cause of the interruption is
+// returned if reconnecting isn't possible.
+func (r *resumer) resubscribe(cause error) (*ClientSubscription, SkipFunc, error) {
+	for backoff := resubscribeMinBackoff; ; backoff = min(2*backoff, resubscribeMaxBackoff) {
+		args, skip, err := r.resume(r.args, r.last)
+		if err != nil {
+			return nil, nil, err
+		}
+		ctx, cancel := context.WithTimeout(r.ctx, subscribeTimeout)
+		inner, err := r.client.Subscribe(ctx, r.namespace, r.in, args...)
+		cancel()
+		if err == nil {
+			return inner, skip, nil
+		}
+		var rpcErr Error
+		switch {
+		case errors.Is(err, errDead):
+			return nil, nil, cause // not reconnectable
+		case errors.Is(err, ErrClientQuit), errors.As(err, &rpcErr):
+			return nil, nil, err
+		}
+		log.Trace("RPC resubscription failed", "namespace", r.namespace, "err", err, "retry", backoff)
+		select {
+		case <-time.After(backoff):
+		case <-r.ctx.Done():
+			return nil, nil, r.ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2024 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestServerTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	}()
+
+	server := newTestServer()
+	defer server.Stop()
+	httpsrv := httptest.NewServer(server)
+	defer httpsrv.Close()
+
+	const (
+		traceID    = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentSpan = "00f067aa0ba902b7"
+	)
+	body := `[{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["x",1]},{"jsonrpc":"2.0","id":2,"method":"test_returnError"}]`
+	req, _ := http.NewRequest(http.MethodPost, httpsrv.URL, strings.NewReader(body))
+	req.Header.Set("content-type", contentType)
+	req.Header.Set("traceparent", "00-"+traceID+"-"+parentSpan+"-01")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("wrong number of spans: have %d, want 2", len(spans))
+	}
+	for i, want := range []string{"test_echo", "test_returnError"} {
+		span := spans[i]
+		if span.Name() != want {
+			t.Errorf("span %d: wrong name %q, want %q", i, span.Name(), want)
+		}
+		if span.SpanContext().TraceID().String() != traceID || span.Parent().SpanID().String() != parentSpan {
+			t.Errorf("span %d: not a child of the caller's span: trace %v, parent %v", i, span.SpanContext().TraceID(), span.Parent().SpanID())
+		}
+	}
+	if spans[0].Status().Code == codes.Error {
+		t.Errorf("successful call has error status: %v", spans[0].Status())
+	}
+	if spans[1].Status().Code != codes.Error {
+		t.Errorf("failed call has status %v, want error", spans[1].Status())
+	}
+}